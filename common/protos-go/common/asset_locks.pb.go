@@ -63,6 +63,60 @@ func (LockMechanism) EnumDescriptor() ([]byte, []int) {
 	return file_common_asset_locks_proto_rawDescGZIP(), []int{0}
 }
 
+// Hash functions that can be used to compute the hash of an HTLC lock
+// SHA256 is the default, so locks created without a hash mechanism use SHA-256
+type HashMechanism int32
+
+const (
+	HashMechanism_SHA256    HashMechanism = 0
+	HashMechanism_SHA512    HashMechanism = 1
+	HashMechanism_SHA3_256  HashMechanism = 2
+	HashMechanism_KECCAK256 HashMechanism = 3
+)
+
+// Enum value maps for HashMechanism.
+var (
+	HashMechanism_name = map[int32]string{
+		0: "SHA256",
+		1: "SHA512",
+		2: "SHA3_256",
+		3: "KECCAK256",
+	}
+	HashMechanism_value = map[string]int32{
+		"SHA256":    0,
+		"SHA512":    1,
+		"SHA3_256":  2,
+		"KECCAK256": 3,
+	}
+)
+
+func (x HashMechanism) Enum() *HashMechanism {
+	p := new(HashMechanism)
+	*p = x
+	return p
+}
+
+func (x HashMechanism) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HashMechanism) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_asset_locks_proto_enumTypes[1].Descriptor()
+}
+
+func (HashMechanism) Type() protoreflect.EnumType {
+	return &file_common_asset_locks_proto_enumTypes[1]
+}
+
+func (x HashMechanism) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HashMechanism.Descriptor instead.
+func (HashMechanism) EnumDescriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{1}
+}
+
 type AssetLockHTLC_TimeSpec int32
 
 const (
@@ -93,11 +147,11 @@ func (x AssetLockHTLC_TimeSpec) String() string {
 }
 
 func (AssetLockHTLC_TimeSpec) Descriptor() protoreflect.EnumDescriptor {
-	return file_common_asset_locks_proto_enumTypes[1].Descriptor()
+	return file_common_asset_locks_proto_enumTypes[2].Descriptor()
 }
 
 func (AssetLockHTLC_TimeSpec) Type() protoreflect.EnumType {
-	return &file_common_asset_locks_proto_enumTypes[1]
+	return &file_common_asset_locks_proto_enumTypes[2]
 }
 
 func (x AssetLockHTLC_TimeSpec) Number() protoreflect.EnumNumber {
@@ -227,6 +281,7 @@ type AssetLockHTLC struct {
 	HashBase64     []byte                 `protobuf:"bytes,1,opt,name=hashBase64,proto3" json:"hashBase64,omitempty"`
 	ExpiryTimeSecs uint64                 `protobuf:"varint,2,opt,name=expiryTimeSecs,proto3" json:"expiryTimeSecs,omitempty"`
 	TimeSpec       AssetLockHTLC_TimeSpec `protobuf:"varint,3,opt,name=timeSpec,proto3,enum=common.asset_locks.AssetLockHTLC_TimeSpec" json:"timeSpec,omitempty"`
+	HashMechanism  HashMechanism          `protobuf:"varint,4,opt,name=hashMechanism,proto3,enum=common.asset_locks.HashMechanism" json:"hashMechanism,omitempty"`
 }
 
 func (x *AssetLockHTLC) Reset() {
@@ -282,6 +337,13 @@ func (x *AssetLockHTLC) GetTimeSpec() AssetLockHTLC_TimeSpec {
 	return AssetLockHTLC_EPOCH
 }
 
+func (x *AssetLockHTLC) GetHashMechanism() HashMechanism {
+	if x != nil {
+		return x.HashMechanism
+	}
+	return HashMechanism_SHA256
+}
+
 type AssetClaimHTLC struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -633,7 +695,7 @@ var file_common_asset_locks_proto_rawDesc = []byte{
 	0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x52, 0x0d, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x65,
 	0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6c, 0x61, 0x69, 0x6d,
 	0x49, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x6c, 0x61, 0x69,
-	0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0xc4, 0x01, 0x0a, 0x0d, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c,
+	0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x8d, 0x02, 0x0a, 0x0d, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c,
 	0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x42,
 	0x61, 0x73, 0x65, 0x36, 0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x68, 0x61, 0x73,
 	0x68, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72,
@@ -643,70 +705,79 @@ var file_common_asset_locks_proto_rawDesc = []byte{
 	0x0e, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
 	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b,
 	0x48, 0x54, 0x4c, 0x43, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x08, 0x74,
-	0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63, 0x22, 0x23, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x53,
-	0x70, 0x65, 0x63, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x50, 0x4f, 0x43, 0x48, 0x10, 0x00, 0x12, 0x0c,
-	0x0a, 0x08, 0x44, 0x55, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x22, 0x40, 0x0a, 0x0e,
-	0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x2e,
-	0x0a, 0x12, 0x68, 0x61, 0x73, 0x68, 0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61,
-	0x73, 0x65, 0x36, 0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x12, 0x68, 0x61, 0x73, 0x68,
-	0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x22, 0x72,
-	0x0a, 0x16, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41,
-	0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06,
-	0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f,
-	0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e,
-	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65,
-	0x6e, 0x74, 0x22, 0x86, 0x01, 0x0a, 0x1e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41,
-	0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65,
-	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d,
-	0x55, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d,
-	0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18,
+	0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x47, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x4d,
+	0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21,
+	0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73,
+	0x6d, 0x52, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d,
+	0x22, 0x23, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x09, 0x0a, 0x05,
+	0x45, 0x50, 0x4f, 0x43, 0x48, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x55, 0x52, 0x41, 0x54,
+	0x49, 0x4f, 0x4e, 0x10, 0x01, 0x22, 0x40, 0x0a, 0x0e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c,
+	0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x2e, 0x0a, 0x12, 0x68, 0x61, 0x73, 0x68, 0x50,
+	0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x12, 0x68, 0x61, 0x73, 0x68, 0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67,
+	0x65, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x22, 0x72, 0x0a, 0x16, 0x41, 0x73, 0x73, 0x65, 0x74,
+	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18,
 	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a,
 	0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0xee, 0x01, 0x0a, 0x11,
-	0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x54, 0x4c,
-	0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49,
-	0x64, 0x12, 0x48, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
-	0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45,
-	0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74,
-	0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x04, 0x6c,
-	0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
-	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41,
-	0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04, 0x6c, 0x6f,
-	0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
-	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69,
-	0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x22, 0xfe, 0x01, 0x0a,
-	0x19, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f,
+	0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x86, 0x01, 0x0a, 0x1e,
+	0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69,
+	0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70,
+	0x69, 0x65, 0x6e, 0x74, 0x22, 0xee, 0x01, 0x0a, 0x11, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f,
 	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f,
 	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x50, 0x0a, 0x09, 0x61, 0x67,
-	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e,
+	0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x48, 0x0a, 0x09, 0x61, 0x67,
+	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e,
 	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63,
-	0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74,
-	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x04,
-	0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63,
+	0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d,
 	0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e,
-	0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04, 0x6c,
-	0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65,
-	0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61,
-	0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x2a, 0x19, 0x0a,
-	0x0d, 0x4c, 0x6f, 0x63, 0x6b, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x08,
-	0x0a, 0x04, 0x48, 0x54, 0x4c, 0x43, 0x10, 0x00, 0x42, 0x77, 0x0a, 0x24, 0x63, 0x6f, 0x6d, 0x2e,
-	0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e, 0x63, 0x6f,
+	0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05,
+	0x63, 0x6c, 0x61, 0x69, 0x6d, 0x22, 0xfe, 0x01, 0x0a, 0x19, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62,
+	0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48,
+	0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x49, 0x64, 0x12, 0x50, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e, 0x67,
+	0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73,
+	0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f,
+	0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05,
+	0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f,
 	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x5a, 0x4f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70,
-	0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2d, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x77, 0x65,
-	0x61, 0x76, 0x65, 0x72, 0x2d, 0x64, 0x6c, 0x74, 0x2d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6f, 0x70,
-	0x65, 0x72, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
-	0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52,
+	0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x2a, 0x19, 0x0a, 0x0d, 0x4c, 0x6f, 0x63, 0x6b, 0x4d, 0x65,
+	0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x4c, 0x43, 0x10,
+	0x00, 0x2a, 0x44, 0x0a, 0x0d, 0x48, 0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69,
+	0x73, 0x6d, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x48,
+	0x41, 0x33, 0x5f, 0x32, 0x35, 0x36, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x4b, 0x45, 0x43, 0x43,
+	0x41, 0x4b, 0x32, 0x35, 0x36, 0x10, 0x03, 0x42, 0x77, 0x0a, 0x24, 0x63, 0x6f, 0x6d, 0x2e, 0x77,
+	0x65, 0x61, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5a,
+	0x4f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65,
+	0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2d, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x77, 0x65, 0x61,
+	0x76, 0x65, 0x72, 0x2d, 0x64, 0x6c, 0x74, 0x2d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6f, 0x70, 0x65,
+	0x72, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -721,35 +792,37 @@ func file_common_asset_locks_proto_rawDescGZIP() []byte {
 	return file_common_asset_locks_proto_rawDescData
 }
 
-var file_common_asset_locks_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_common_asset_locks_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
 var file_common_asset_locks_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_common_asset_locks_proto_goTypes = []interface{}{
 	(LockMechanism)(0),                     // 0: common.asset_locks.LockMechanism
-	(AssetLockHTLC_TimeSpec)(0),            // 1: common.asset_locks.AssetLockHTLC.TimeSpec
-	(*AssetLock)(nil),                      // 2: common.asset_locks.AssetLock
-	(*AssetClaim)(nil),                     // 3: common.asset_locks.AssetClaim
-	(*AssetLockHTLC)(nil),                  // 4: common.asset_locks.AssetLockHTLC
-	(*AssetClaimHTLC)(nil),                 // 5: common.asset_locks.AssetClaimHTLC
-	(*AssetExchangeAgreement)(nil),         // 6: common.asset_locks.AssetExchangeAgreement
-	(*FungibleAssetExchangeAgreement)(nil), // 7: common.asset_locks.FungibleAssetExchangeAgreement
-	(*AssetContractHTLC)(nil),              // 8: common.asset_locks.AssetContractHTLC
-	(*FungibleAssetContractHTLC)(nil),      // 9: common.asset_locks.FungibleAssetContractHTLC
+	(HashMechanism)(0),                     // 1: common.asset_locks.HashMechanism
+	(AssetLockHTLC_TimeSpec)(0),            // 2: common.asset_locks.AssetLockHTLC.TimeSpec
+	(*AssetLock)(nil),                      // 3: common.asset_locks.AssetLock
+	(*AssetClaim)(nil),                     // 4: common.asset_locks.AssetClaim
+	(*AssetLockHTLC)(nil),                  // 5: common.asset_locks.AssetLockHTLC
+	(*AssetClaimHTLC)(nil),                 // 6: common.asset_locks.AssetClaimHTLC
+	(*AssetExchangeAgreement)(nil),         // 7: common.asset_locks.AssetExchangeAgreement
+	(*FungibleAssetExchangeAgreement)(nil), // 8: common.asset_locks.FungibleAssetExchangeAgreement
+	(*AssetContractHTLC)(nil),              // 9: common.asset_locks.AssetContractHTLC
+	(*FungibleAssetContractHTLC)(nil),      // 10: common.asset_locks.FungibleAssetContractHTLC
 }
 var file_common_asset_locks_proto_depIdxs = []int32{
-	0, // 0: common.asset_locks.AssetLock.lockMechanism:type_name -> common.asset_locks.LockMechanism
-	0, // 1: common.asset_locks.AssetClaim.lockMechanism:type_name -> common.asset_locks.LockMechanism
-	1, // 2: common.asset_locks.AssetLockHTLC.timeSpec:type_name -> common.asset_locks.AssetLockHTLC.TimeSpec
-	6, // 3: common.asset_locks.AssetContractHTLC.agreement:type_name -> common.asset_locks.AssetExchangeAgreement
-	4, // 4: common.asset_locks.AssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
-	5, // 5: common.asset_locks.AssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
-	7, // 6: common.asset_locks.FungibleAssetContractHTLC.agreement:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
-	4, // 7: common.asset_locks.FungibleAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
-	5, // 8: common.asset_locks.FungibleAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	0,  // 0: common.asset_locks.AssetLock.lockMechanism:type_name -> common.asset_locks.LockMechanism
+	0,  // 1: common.asset_locks.AssetClaim.lockMechanism:type_name -> common.asset_locks.LockMechanism
+	2,  // 2: common.asset_locks.AssetLockHTLC.timeSpec:type_name -> common.asset_locks.AssetLockHTLC.TimeSpec
+	1,  // 3: common.asset_locks.AssetLockHTLC.hashMechanism:type_name -> common.asset_locks.HashMechanism
+	7,  // 4: common.asset_locks.AssetContractHTLC.agreement:type_name -> common.asset_locks.AssetExchangeAgreement
+	5,  // 5: common.asset_locks.AssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 6: common.asset_locks.AssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	8,  // 7: common.asset_locks.FungibleAssetContractHTLC.agreement:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
+	5,  // 8: common.asset_locks.FungibleAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 9: common.asset_locks.FungibleAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_common_asset_locks_proto_init() }
@@ -860,7 +933,7 @@ func file_common_asset_locks_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_common_asset_locks_proto_rawDesc,
-			NumEnums:      2,
+			NumEnums:      3,
 			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
@@ -98,7 +98,8 @@ async function invoke(
             query.getAddress() + query.getNonce(),
         );
         // 3. Set the endorser list for the transaction, this enforces that the list provided will endorse the proposed transaction
-        const transaction = contract.createTransaction('HandleExternalRequest');
+        // Invocations change the ledger state of the application chaincode, and are handled by a separate function
+        const transaction = contract.createTransaction(query.getInvoke() ? 'HandleExternalInvocation' : 'HandleExternalRequest');
         if (identities.length > 0) {
             const endorserList = endorsers.filter((endorser: Endorser) => {
                 //@ts-ignore
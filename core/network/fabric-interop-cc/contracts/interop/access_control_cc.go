@@ -9,17 +9,39 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	log "github.com/sirupsen/logrus"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern"
 )
 
 const accessControlObjectType = "accessControl"
 
+// Permissions checked against the read and write flags of access control rules
+const (
+	accessPermissionRead  = "read"
+	accessPermissionWrite = "write"
+)
+
+// memberRole matches requesters with any role in "role" rules
+const memberRole = "member"
+
+// validRoles are the principals of "role" rules, i.e., the Fabric node OUs and memberRole
+var validRoles = map[string]struct{}{
+	"client":   {},
+	"admin":    {},
+	"peer":     {},
+	"orderer":  {},
+	memberRole: {},
+}
+
 // CreateAccessControlPolicy cc is used to store a AccessControlPolicy in the ledger
 func (s *SmartContract) CreateAccessControlPolicy(ctx contractapi.TransactionContextInterface, accessControlPolicyJSON string) error {
 	accessControlPolicy, err := decodeAccessControlPolicy([]byte(accessControlPolicyJSON))
@@ -28,6 +50,11 @@ func (s *SmartContract) CreateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
+	err = validateAccessControlPolicyRules(accessControlPolicy)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
 	accessControlKey, err := ctx.GetStub().CreateCompositeKey(accessControlObjectType, []string{accessControlPolicy.SecurityDomain})
 	acp, err := ctx.GetStub().GetState(accessControlKey)
 	if err != nil {
@@ -46,7 +73,11 @@ func (s *SmartContract) CreateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
-	return ctx.GetStub().PutState(accessControlKey, accessControlBytes)
+	err = ctx.GetStub().PutState(accessControlKey, accessControlBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "CreateAccessControlPolicy", accessControlPolicy.SecurityDomain)
 }
 
 // UpdateAccessControlPolicy cc is used to update an existing AccessControlPolicy in the ledger
@@ -57,6 +88,11 @@ func (s *SmartContract) UpdateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
+	err = validateAccessControlPolicyRules(accessControlPolicy)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
 	accessControlKey, err := ctx.GetStub().CreateCompositeKey(accessControlObjectType, []string{accessControlPolicy.SecurityDomain})
 	_, err = s.GetAccessControlPolicyBySecurityDomain(ctx, accessControlPolicy.SecurityDomain)
 	if err != nil {
@@ -70,7 +106,11 @@ func (s *SmartContract) UpdateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
-	return ctx.GetStub().PutState(accessControlKey, accessControlBytes)
+	err = ctx.GetStub().PutState(accessControlKey, accessControlBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateAccessControlPolicy", accessControlPolicy.SecurityDomain)
 }
 
 // GetAccessControlPolicyBySecurityDomain cc gets the AccessControlPolicy for the provided securityDomain
@@ -113,52 +153,207 @@ func (s *SmartContract) DeleteAccessControlPolicy(ctx contractapi.TransactionCon
 		return errors.New(errorMessage)
 	}
 
-	return nil
+	return recordConfigChange(ctx, "DeleteAccessControlPolicy", securityDomain)
 }
 
 // verifyAccessToCC looks up the Access Control State for the external network
 // and verifies that the requester has the required permission to call the specified CC function.
-func verifyAccessToCC(s *SmartContract, ctx contractapi.TransactionContextInterface, viewAddress *FabricViewAddress, viewAddressString string, query *common.Query) error {
+//
+// The request is permitted if a rule for the view address and permission matches the requester, unless a deny rule
+// for the view address and permission also matches the requester. Rules outside of their validity window are ignored.
+// The most specific rule that permits the request is returned, as it specifies the redaction of the response.
+// The requester's certificate must already have been verified against the member of the requesting network's
+// Membership for the RequestingOrg, as verifyExternalRequest does, so that rules on the org can be trusted.
+func verifyAccessToCC(s *SmartContract, ctx contractapi.TransactionContextInterface, viewAddress *FabricViewAddress, viewAddressString string, query *common.Query, cert *x509.Certificate, permission string) (*common.Rule, error) {
 	acpString, err := s.GetAccessControlPolicyBySecurityDomain(ctx, query.RequestingNetwork)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Access control policy does not exist for network: %s", query.RequestingNetwork)
 		log.Error(errorMessage)
-		return errors.New(errorMessage)
+		return nil, errors.New(errorMessage)
 	}
 	acp, err := decodeAccessControlPolicy([]byte(acpString))
 	if err != nil {
 		errorMessage := fmt.Sprintf("Failed to unmarshal access control policy: %s", err.Error())
 		log.Error(errorMessage)
-		return errors.New(errorMessage)
+		return nil, errors.New(errorMessage)
 	}
 
+	// Rules are evaluated from the most to the least specific resource pattern, so that the rule permitting the
+	// request does not depend on the order of the rules in the policy
+	matchingRules := []*common.Rule{}
 	for _, rule := range acp.Rules {
-		if rule.Resource == viewAddressString || (validPatternString(rule.Resource) && isPatternAndAddressMatch(rule.Resource, viewAddressString)) {
-			// TODO: Check if these will be the same format (Or convert to matching formats at some point)
-			// TODO: Need to use principalType and perform different validation for type "certificate" and "ca".
-			// Code below assumes that requestor's membership has already been authenticated earlier if the type is "ca"
-			if (rule.PrincipalType == "certificate" && query.Certificate == rule.Principal) {
-				// Break loop as cert is valid.
-				log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate)
-				return nil
-			}
-			if (rule.PrincipalType == "ca" && query.RequestingOrg == rule.Principal) {
-				// Break loop as cert is valid.
-				log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.RequestingOrg)
-				return nil
-			}
+		if rule.Resource == viewAddressString || isPatternAndAddressMatch(rule.Resource, viewAddressString) {
+			matchingRules = append(matchingRules, rule)
 		}
+	}
+	sort.SliceStable(matchingRules, func(i, j int) bool {
+		return addresspattern.Compare(matchingRules[i].Resource, matchingRules[j].Resource) > 0
+	})
 
+	requester := &accessControlRequester{ctx: ctx, query: query, cert: cert}
+	var permittingRule *common.Rule
+	for _, rule := range matchingRules {
+		if !ruleAppliesToPermission(rule, permission) {
+			continue
+		}
+		if permittingRule != nil && !rule.Deny {
+			continue
+		}
+		active, err := requester.isRuleActive(rule)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		if !active {
+			continue
+		}
+		matches, err := requester.matchesRule(rule)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+		if rule.Deny {
+			errorMessage := fmt.Sprintf("Access Control Policy DENIES the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, requester.String())
+			log.Error(errorMessage)
+			return nil, errors.New(errorMessage)
+		}
+		permittingRule = rule
+	}
+	if permittingRule != nil {
+		log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, requester.String())
+		return permittingRule, nil
 	}
 	var errorMessage string
-	if (query.Certificate != "") {
-        errorMessage = fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate)
-	} else if (query.RequestingOrg != "") {
-        errorMessage = fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.RequestingOrg)
+	if requester.String() != "" {
+		errorMessage = fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, requester.String())
 	} else {
 		errorMessage = fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from a foreign entity", viewAddressString)
 	}
 	log.Error(errorMessage)
-	return errors.New(errorMessage)
+	return nil, errors.New(errorMessage)
+}
 
+// ruleAppliesToPermission checks if a rule grants (or denies) a permission, according to its read and write flags
+func ruleAppliesToPermission(rule *common.Rule, permission string) bool {
+	if permission == accessPermissionWrite {
+		return rule.Write
+	}
+	return rule.Read
+}
+
+// accessControlRequester holds the identity of the requester that access control rules are evaluated against
+type accessControlRequester struct {
+	ctx        contractapi.TransactionContextInterface
+	query      *common.Query
+	cert       *x509.Certificate
+	txTimeSecs int64
+}
+
+// String returns the identity of the requester used in log and error messages
+func (r *accessControlRequester) String() string {
+	if r.query.Certificate != "" {
+		return r.query.Certificate
+	}
+	return r.query.RequestingOrg
+}
+
+// isRuleActive checks if the transaction time lies within the validity window of a rule
+func (r *accessControlRequester) isRuleActive(rule *common.Rule) (bool, error) {
+	if rule.ValidFrom == 0 && rule.ValidUntil == 0 {
+		return true, nil
+	}
+	if r.txTimeSecs == 0 {
+		txTimeSecs, err := getTxTimeSecs(r.ctx)
+		if err != nil {
+			return false, err
+		}
+		r.txTimeSecs = txTimeSecs
+	}
+	if rule.ValidFrom != 0 && uint64(r.txTimeSecs) < rule.ValidFrom {
+		return false, nil
+	}
+	if rule.ValidUntil != 0 && uint64(r.txTimeSecs) > rule.ValidUntil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// matchesRule checks if the requester is the principal of a rule
+func (r *accessControlRequester) matchesRule(rule *common.Rule) (bool, error) {
+	switch rule.PrincipalType {
+	case "certificate":
+		return r.query.Certificate == rule.Principal, nil
+	case "ca":
+		return r.query.RequestingOrg == rule.Principal, nil
+	case "role", "ou", "attribute":
+		if rule.Org != "" && rule.Org != r.query.RequestingOrg {
+			return false, nil
+		}
+		if r.cert == nil {
+			return false, nil
+		}
+		var matches bool
+		switch rule.PrincipalType {
+		case "role":
+			matches = rule.Principal == memberRole || hasCertificateOU(r.cert, rule.Principal)
+		case "ou":
+			matches = hasCertificateOU(r.cert, rule.Principal)
+		default:
+			name, value, err := parseAttributePrincipal(rule.Principal)
+			if err != nil {
+				return false, err
+			}
+			attributes, err := getCertificateAttributes(r.cert)
+			if err != nil {
+				return false, err
+			}
+			attributeValue, ok := attributes[name]
+			matches = ok && attributeValue == value
+		}
+		return matches, nil
+	}
+	return false, nil
+}
+
+// validateAccessControlPolicyRules checks that the rules set a permission, and that their principals and validity
+// windows are well formed
+func validateAccessControlPolicyRules(accessControlPolicy *common.AccessControlPolicy) error {
+	for _, rule := range accessControlPolicy.Rules {
+		if !rule.Read && !rule.Write {
+			return fmt.Errorf("Rule for resource %s must set read, write or both", rule.Resource)
+		}
+		switch rule.PrincipalType {
+		case "role":
+			if _, ok := validRoles[rule.Principal]; !ok {
+				return fmt.Errorf("Invalid role %s in rule for resource %s", rule.Principal, rule.Resource)
+			}
+		case "attribute":
+			_, _, err := parseAttributePrincipal(rule.Principal)
+			if err != nil {
+				return fmt.Errorf("%s in rule for resource %s", err.Error(), rule.Resource)
+			}
+		}
+		for _, path := range append(append([]string{}, rule.IncludePaths...), rule.ExcludePaths...) {
+			err := validateJSONPath(path)
+			if err != nil {
+				return fmt.Errorf("%s in rule for resource %s", err.Error(), rule.Resource)
+			}
+		}
+		if rule.ValidUntil != 0 && rule.ValidFrom > rule.ValidUntil {
+			return fmt.Errorf("Invalid validity window in rule for resource %s: validFrom is later than validUntil", rule.Resource)
+		}
+	}
+	return nil
+}
+
+// parseAttributePrincipal splits the principal of an attribute rule into the attribute name and value
+func parseAttributePrincipal(principal string) (string, string, error) {
+	parts := strings.SplitN(principal, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("Invalid attribute %s, expecting name=value", principal)
+	}
+	return parts[0], parts[1], nil
 }
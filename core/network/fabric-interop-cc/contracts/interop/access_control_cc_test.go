@@ -7,10 +7,17 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/require"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
@@ -141,7 +148,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 
 	// Test: Happy case
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.NoError(t, err)
 	newRule := common.Rule{
 		Principal:     "cert",
@@ -153,7 +160,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.NoError(t, err)
 
 	newRule = common.Rule{
@@ -166,7 +173,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.NoError(t, err)
 
 	// Test: Invalid Cert
@@ -180,7 +187,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
 	// Test: Invalid CA
@@ -194,7 +201,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
 	// Test: No rule for requested resource
@@ -208,7 +215,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
 	differentResourceRule = common.Rule{
@@ -221,11 +228,124 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturns(accessControlBytes, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
 	// Test: No Rule for ID
 	chaincodeStub.GetStateReturns(nil, nil)
-	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
+	_, err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query, nil, accessPermissionRead)
 	require.EqualError(t, err, fmt.Sprintf("Access control policy does not exist for network: %s", query.RequestingNetwork))
 }
+
+// createAttributeCertificate issues a certificate from the test CA with the given OUs and Fabric CA attributes
+func createAttributeCertificate(t *testing.T, ca *revocationTestCA, ous []string, attrs map[string]string) (*x509.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	attrsBytes, err := json.Marshal(map[string]map[string]string{"attrs": attrs})
+	require.NoError(t, err)
+	template := x509.Certificate{
+		Subject:         pkix.Name{CommonName: "user1", OrganizationalUnit: ous},
+		NotBefore:       testCertNotBefore,
+		NotAfter:        testCertNotAfter,
+		SerialNumber:    big.NewInt(0x1339),
+		ExtraExtensions: []pkix.Extension{{Id: fabricAttributesOID, Value: attrsBytes}},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, ca.caCert, &key.PublicKey, ca.caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	return cert, encodePEM("CERTIFICATE", certDER)
+}
+
+func TestVerifyAccessToCCRules(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	ca := createRevocationTestCA(t)
+	membership := common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: ca.caPEM, Type: "ca"}},
+	}
+	membershipBytes, err := json.Marshal(&membership)
+	require.NoError(t, err)
+	worldState["membership:network1"] = membershipBytes
+	cert, certPEM := createAttributeCertificate(t, ca, []string{"client", "department1"}, map[string]string{"role": "auditor"})
+
+	viewAddressString := "mychannel:interop:Read:a"
+	viewAddress := FabricViewAddress{Channel: "mychannel", Contract: "interop", CCFunc: "Read", Args: []string{"a"}}
+	query := common.Query{
+		Address:           "localhost:9080/network1/mychannel:interop:Read:a",
+		RequestingNetwork: "network1",
+		RequestingOrg:     "Org1MSP",
+		Certificate:       certPEM,
+	}
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: 1000}, nil)
+	verifyRules := func(requester *x509.Certificate, rules ...*common.Rule) error {
+		acpBytes, err := json.Marshal(&common.AccessControlPolicy{SecurityDomain: "network1", Rules: rules})
+		require.NoError(t, err)
+		worldState["accessControl:network1"] = acpBytes
+		_, err = verifyAccessToCC(&interopcc, ctx, &viewAddress, viewAddressString, &query, requester, accessPermissionRead)
+		return err
+	}
+	notPermitted := fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, certPEM)
+	denied := fmt.Sprintf("Access Control Policy DENIES the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, certPEM)
+
+	// Test: Org, role, OU and attribute rules
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "Org1MSP", PrincipalType: "ca", Resource: "mychannel:interop:Read:*", Read: true}))
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "client", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true, Org: "Org1MSP"}))
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "member", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true}))
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "department1", PrincipalType: "ou", Resource: "mychannel:interop:Read:*", Read: true}))
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "role=auditor", PrincipalType: "attribute", Resource: "mychannel:interop:Read:*", Read: true}))
+	require.EqualError(t, verifyRules(cert, &common.Rule{Principal: "admin", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true}), notPermitted)
+	require.EqualError(t, verifyRules(cert, &common.Rule{Principal: "client", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true, Org: "Org2MSP"}), notPermitted)
+	require.EqualError(t, verifyRules(cert, &common.Rule{Principal: "role=admin", PrincipalType: "attribute", Resource: "mychannel:interop:Read:*", Read: true}), notPermitted)
+
+	// Test: Deny rules take precedence, whatever their position
+	allowRule := &common.Rule{Principal: "member", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true}
+	denyRule := &common.Rule{Principal: "role=auditor", PrincipalType: "attribute", Resource: "mychannel:interop:Read:a", Read: true, Deny: true}
+	require.EqualError(t, verifyRules(cert, allowRule, denyRule), denied)
+	require.EqualError(t, verifyRules(cert, denyRule, allowRule), denied)
+
+	// Test: Rules outside their validity window are ignored
+	require.NoError(t, verifyRules(cert, allowRule, &common.Rule{Principal: "role=auditor", PrincipalType: "attribute", Resource: "mychannel:interop:Read:a", Read: true, Deny: true, ValidUntil: 999}))
+	require.EqualError(t, verifyRules(cert, &common.Rule{Principal: "client", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true, ValidFrom: 1001}), notPermitted)
+	require.NoError(t, verifyRules(cert, &common.Rule{Principal: "client", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true, ValidFrom: 1000, ValidUntil: 1000}))
+
+	// Test: The most specific permitting rule is returned, whatever its position
+	broadRule := &common.Rule{Principal: "member", PrincipalType: "role", Resource: "mychannel:*", Read: true, ExcludePaths: []string{"owner"}}
+	specificRule := &common.Rule{Principal: "member", PrincipalType: "role", Resource: "mychannel:interop:Read:*", Read: true, ExcludePaths: []string{"value"}}
+	for _, rules := range [][]*common.Rule{{broadRule, specificRule}, {specificRule, broadRule}} {
+		acpBytes, err := json.Marshal(&common.AccessControlPolicy{SecurityDomain: "network1", Rules: rules})
+		require.NoError(t, err)
+		worldState["accessControl:network1"] = acpBytes
+		rule, err := verifyAccessToCC(&interopcc, ctx, &viewAddress, viewAddressString, &query, cert, accessPermissionRead)
+		require.NoError(t, err)
+		require.Equal(t, []string{"value"}, rule.ExcludePaths)
+	}
+}
+
+func TestValidateAccessControlPolicyRules(t *testing.T) {
+	validate := func(rule *common.Rule) error {
+		return validateAccessControlPolicyRules(&common.AccessControlPolicy{SecurityDomain: "network1", Rules: []*common.Rule{rule}})
+	}
+	require.NoError(t, validate(&common.Rule{Principal: "admin", PrincipalType: "role", Resource: "a", Read: true}))
+	require.NoError(t, validate(&common.Rule{Principal: "role=", PrincipalType: "attribute", Resource: "a", Read: true}))
+	require.EqualError(t, validate(&common.Rule{Principal: "superuser", PrincipalType: "role", Resource: "a", Read: true}), "Invalid role superuser in rule for resource a")
+	require.EqualError(t, validate(&common.Rule{Principal: "role", PrincipalType: "attribute", Resource: "a", Read: true}), "Invalid attribute role, expecting name=value in rule for resource a")
+	require.EqualError(t, validate(&common.Rule{Principal: "cert", PrincipalType: "certificate", Resource: "a", Read: true, ValidFrom: 2, ValidUntil: 1}), "Invalid validity window in rule for resource a: validFrom is later than validUntil")
+	require.EqualError(t, validate(&common.Rule{Principal: "cert", PrincipalType: "certificate", Resource: "a", Read: true, ExcludePaths: []string{"owner."}}), "Invalid JSON path owner. in rule for resource a")
+	require.EqualError(t, validate(&common.Rule{Principal: "cert", PrincipalType: "certificate", Resource: "a"}), "Rule for resource a must set read, write or both")
+	require.EqualError(t, validate(&common.Rule{Principal: "cert", PrincipalType: "certificate", Resource: "a", Deny: true}), "Rule for resource a must set read, write or both")
+	require.NoError(t, validate(&common.Rule{Principal: "cert", PrincipalType: "certificate", Resource: "a", Write: true}))
+}
+
+func TestRuleAppliesToPermission(t *testing.T) {
+	require.True(t, ruleAppliesToPermission(&common.Rule{Read: true}, accessPermissionRead))
+	require.False(t, ruleAppliesToPermission(&common.Rule{Read: true}, accessPermissionWrite))
+	require.True(t, ruleAppliesToPermission(&common.Rule{Write: true}, accessPermissionWrite))
+	require.False(t, ruleAppliesToPermission(&common.Rule{Write: true}, accessPermissionRead))
+	require.True(t, ruleAppliesToPermission(&common.Rule{Read: true, Write: true}, accessPermissionRead))
+	require.True(t, ruleAppliesToPermission(&common.Rule{Read: true, Write: true}, accessPermissionWrite))
+	// Deny rules apply to the permissions they set
+	require.False(t, ruleAppliesToPermission(&common.Rule{Deny: true, Read: true}, accessPermissionWrite))
+	require.True(t, ruleAppliesToPermission(&common.Rule{Deny: true, Write: true}, accessPermissionWrite))
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// application_registry contains the registry of application chaincodes that remote networks may reach through
+// the interop chaincode, and the chaincode functions used to manage it
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	applicationCCKey               = "applicationccid"
+	applicationChaincodeObjectType = "applicationChaincode"
+)
+
+// ApplicationChaincode is an entry in the registry of application chaincodes that remote networks may reach.
+//
+// Requests from remote networks to a chaincode and channel that are not registered, or whose entry is not
+// enabled, are rejected before the access control policy of the requesting network is checked. OwnerOrg is
+// the MSP ID of the local org responsible for the chaincode.
+type ApplicationChaincode struct {
+	ChaincodeID string `json:"chaincodeId"`
+	Channel     string `json:"channel"`
+	OwnerOrg    string `json:"ownerOrg"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// CreateApplicationChaincode cc is used to register an application chaincode that remote networks may reach
+func (s *SmartContract) CreateApplicationChaincode(ctx contractapi.TransactionContextInterface, applicationChaincodeJSON string) error {
+	applicationChaincode, err := decodeApplicationChaincode([]byte(applicationChaincodeJSON))
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unmarshal error: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	err = validateApplicationChaincode(applicationChaincode)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	existing, err := getApplicationChaincode(ctx, applicationChaincode.Channel, applicationChaincode.ChaincodeID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		errorMessage := fmt.Sprintf("Application chaincode %s on channel %s already exists", applicationChaincode.ChaincodeID, applicationChaincode.Channel)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	err = putApplicationChaincode(ctx, applicationChaincode)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "CreateApplicationChaincode", localSecurityDomain)
+}
+
+// UpdateApplicationChaincode cc is used to update the entry of a registered application chaincode, e.g., to
+// enable or disable it
+func (s *SmartContract) UpdateApplicationChaincode(ctx contractapi.TransactionContextInterface, applicationChaincodeJSON string) error {
+	applicationChaincode, err := decodeApplicationChaincode([]byte(applicationChaincodeJSON))
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unmarshal error: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	err = validateApplicationChaincode(applicationChaincode)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	existing, err := getApplicationChaincode(ctx, applicationChaincode.Channel, applicationChaincode.ChaincodeID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		errorMessage := fmt.Sprintf("Application chaincode %s on channel %s does not exist", applicationChaincode.ChaincodeID, applicationChaincode.Channel)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	err = putApplicationChaincode(ctx, applicationChaincode)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateApplicationChaincode", localSecurityDomain)
+}
+
+// DeleteApplicationChaincode cc is used to remove an application chaincode from the registry, so that remote
+// networks can no longer reach it
+func (s *SmartContract) DeleteApplicationChaincode(ctx contractapi.TransactionContextInterface, channel string, chaincodeID string) error {
+	existing, err := getApplicationChaincode(ctx, channel, chaincodeID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		errorMessage := fmt.Sprintf("Application chaincode %s on channel %s does not exist", chaincodeID, channel)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	applicationChaincodeKey, err := ctx.GetStub().CreateCompositeKey(applicationChaincodeObjectType, []string{channel, chaincodeID})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(applicationChaincodeKey)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed to delete application chaincode %s on channel %s: %s", chaincodeID, channel, err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	return recordConfigChange(ctx, "DeleteApplicationChaincode", localSecurityDomain)
+}
+
+// GetApplicationChaincode cc returns the registry entry of an application chaincode
+func (s *SmartContract) GetApplicationChaincode(ctx contractapi.TransactionContextInterface, channel string, chaincodeID string) (string, error) {
+	applicationChaincode, err := getApplicationChaincode(ctx, channel, chaincodeID)
+	if err != nil {
+		return "", err
+	}
+	if applicationChaincode == nil {
+		return "", fmt.Errorf("Application chaincode %s on channel %s does not exist", chaincodeID, channel)
+	}
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(applicationChaincodeBytes), nil
+}
+
+// ListApplicationChaincodes cc returns a page of the registered application chaincodes, optionally restricted
+// to those owned by the given org. An empty bookmark fetches the first page.
+func (s *SmartContract) ListApplicationChaincodes(ctx contractapi.TransactionContextInterface, ownerOrg string, pageSize int32, bookmark string) (string, error) {
+	return getConfigPage(ctx, applicationChaincodeObjectType, pageSize, bookmark, func(bytes []byte) (bool, error) {
+		if ownerOrg == "" {
+			return true, nil
+		}
+		applicationChaincode, err := decodeApplicationChaincode(bytes)
+		if err != nil {
+			return false, fmt.Errorf("Failed to unmarshal application chaincode: %s", err.Error())
+		}
+		return applicationChaincode.OwnerOrg == ownerOrg, nil
+	})
+}
+
+// GetApplicationID retrieves the ID of the application chaincode recorded when the interop chaincode was
+// initialised
+func (s *SmartContract) GetApplicationID(ctx contractapi.TransactionContextInterface) (string, error) {
+	bytes, err := ctx.GetStub().GetState(applicationCCKey)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// verifyApplicationChaincode checks that a remote request addresses a registered and enabled application
+// chaincode. While the registry is empty, requests may address any chaincode, as they did before the registry
+// was introduced, so the registry only takes effect once an application chaincode is registered. Requests to the
+// interop chaincode itself are instead limited to the functions it exposes, and must address its own channel.
+func verifyApplicationChaincode(ctx contractapi.TransactionContextInterface, viewAddress *FabricViewAddress) error {
+	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	if viewAddress.Contract == localCCId {
+		channel := ctx.GetStub().GetChannelID()
+		if viewAddress.Channel != channel {
+			return fmt.Errorf("Interop chaincode %s can only be reached on channel %s, found %s", localCCId, channel, viewAddress.Channel)
+		}
+		return nil
+	}
+	applicationChaincode, err := getApplicationChaincode(ctx, viewAddress.Channel, viewAddress.Contract)
+	if err != nil {
+		return err
+	}
+	if applicationChaincode == nil {
+		registryEmpty, err := isApplicationRegistryEmpty(ctx)
+		if err != nil {
+			return err
+		}
+		if registryEmpty {
+			return nil
+		}
+		return fmt.Errorf("Application chaincode %s on channel %s is not registered", viewAddress.Contract, viewAddress.Channel)
+	}
+	if !applicationChaincode.Enabled {
+		return fmt.Errorf("Application chaincode %s on channel %s is disabled", viewAddress.Contract, viewAddress.Channel)
+	}
+	return nil
+}
+
+// isApplicationRegistryEmpty returns whether no application chaincode has been registered
+func isApplicationRegistryEmpty(ctx contractapi.TransactionContextInterface) (bool, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(applicationChaincodeObjectType, []string{})
+	if err != nil {
+		return false, err
+	}
+	defer iterator.Close()
+	return !iterator.HasNext(), nil
+}
+
+func validateApplicationChaincode(applicationChaincode *ApplicationChaincode) error {
+	if applicationChaincode.ChaincodeID == "" || applicationChaincode.Channel == "" {
+		return fmt.Errorf("Application chaincode must specify a chaincode ID and a channel")
+	}
+	return nil
+}
+
+// getApplicationChaincode returns the registry entry of an application chaincode, or nil if it is not registered
+func getApplicationChaincode(ctx contractapi.TransactionContextInterface, channel string, chaincodeID string) (*ApplicationChaincode, error) {
+	applicationChaincodeKey, err := ctx.GetStub().CreateCompositeKey(applicationChaincodeObjectType, []string{channel, chaincodeID})
+	if err != nil {
+		return nil, err
+	}
+	applicationChaincodeBytes, err := ctx.GetStub().GetState(applicationChaincodeKey)
+	if err != nil {
+		return nil, err
+	}
+	if applicationChaincodeBytes == nil {
+		return nil, nil
+	}
+	applicationChaincode, err := decodeApplicationChaincode(applicationChaincodeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal application chaincode: %s", err.Error())
+	}
+	return applicationChaincode, nil
+}
+
+func putApplicationChaincode(ctx contractapi.TransactionContextInterface, applicationChaincode *ApplicationChaincode) error {
+	applicationChaincodeKey, err := ctx.GetStub().CreateCompositeKey(applicationChaincodeObjectType, []string{applicationChaincode.Channel, applicationChaincode.ChaincodeID})
+	if err != nil {
+		return err
+	}
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Marshal error: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	return ctx.GetStub().PutState(applicationChaincodeKey, applicationChaincodeBytes)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationChaincodeRegistry(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+	chaincodeStub.GetChannelIDReturns("mychannel")
+
+	chaincodeStub.GetStateByPartialCompositeKeyCalls(func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		iterator := &mocks.StateQueryIterator{}
+		prefix := strings.Join(append([]string{objectType}, attributes...), ":") + ":"
+		i := 0
+		for key, value := range worldState {
+			if strings.HasPrefix(key, prefix) {
+				iterator.HasNextReturnsOnCall(i, true)
+				iterator.NextReturnsOnCall(i, &queryresult.KV{Key: key, Value: value}, nil)
+				i++
+			}
+		}
+		return iterator, nil
+	})
+
+	// Test: The application chaincode passed to InitLedger is recorded, but not registered
+	chaincodeStub.GetFunctionAndParametersReturns("InitLedger", []string{"simpleasset"})
+	err := interopcc.InitLedger(ctx)
+	require.NoError(t, err)
+	applicationID, err := interopcc.GetApplicationID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "simpleasset", applicationID)
+	_, err = interopcc.GetApplicationChaincode(ctx, "mychannel", "simpleasset")
+	require.EqualError(t, err, "Application chaincode simpleasset on channel mychannel does not exist")
+
+	// Test: Any application chaincode can be reached while the registry is empty
+	assetAddress := &FabricViewAddress{Channel: "otherchannel", Contract: "assetcc", CCFunc: "Read"}
+	require.NoError(t, verifyApplicationChaincode(ctx, &FabricViewAddress{Channel: "mychannel", Contract: "simpleasset"}))
+	require.NoError(t, verifyApplicationChaincode(ctx, assetAddress))
+
+	// Test: Once an application chaincode is registered, others must be registered before they can be reached
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"simpleasset","channel":"mychannel","enabled":true}`)
+	require.NoError(t, err)
+	require.NoError(t, verifyApplicationChaincode(ctx, &FabricViewAddress{Channel: "mychannel", Contract: "simpleasset"}))
+	err = verifyApplicationChaincode(ctx, assetAddress)
+	require.EqualError(t, err, "Application chaincode assetcc on channel otherchannel is not registered")
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"otherchannel","ownerOrg":"Org1MSP","enabled":true}`)
+	require.NoError(t, err)
+	require.NoError(t, verifyApplicationChaincode(ctx, assetAddress))
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"otherchannel","ownerOrg":"Org1MSP","enabled":true}`)
+	require.EqualError(t, err, "Application chaincode assetcc on channel otherchannel already exists")
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","enabled":true}`)
+	require.EqualError(t, err, "Application chaincode must specify a chaincode ID and a channel")
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"otherchannel","owner":"Org1MSP"}`)
+	require.EqualError(t, err, "Unmarshal error: json: unknown field \"owner\"")
+
+	// Test: Disabled application chaincodes cannot be reached
+	err = interopcc.UpdateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"otherchannel","ownerOrg":"Org1MSP","enabled":false}`)
+	require.NoError(t, err)
+	err = verifyApplicationChaincode(ctx, assetAddress)
+	require.EqualError(t, err, "Application chaincode assetcc on channel otherchannel is disabled")
+	err = interopcc.UpdateApplicationChaincode(ctx, `{"chaincodeId":"unknowncc","channel":"otherchannel","enabled":true}`)
+	require.EqualError(t, err, "Application chaincode unknowncc on channel otherchannel does not exist")
+
+	// Test: The interop chaincode itself does not need to be registered
+	require.NoError(t, verifyApplicationChaincode(ctx, &FabricViewAddress{Channel: "mychannel", Contract: "interopcc", CCFunc: "GetLocalMembership"}))
+	err = verifyApplicationChaincode(ctx, &FabricViewAddress{Channel: "otherchannel", Contract: "interopcc", CCFunc: "GetLocalMembership"})
+	require.EqualError(t, err, "Interop chaincode interopcc can only be reached on channel mychannel, found otherchannel")
+
+	// Test: Delete
+	chaincodeStub.DelStateCalls(func(key string) error {
+		delete(worldState, key)
+		return nil
+	})
+	err = interopcc.DeleteApplicationChaincode(ctx, "otherchannel", "assetcc")
+	require.NoError(t, err)
+	_, err = interopcc.GetApplicationChaincode(ctx, "otherchannel", "assetcc")
+	require.EqualError(t, err, "Application chaincode assetcc on channel otherchannel does not exist")
+	err = interopcc.DeleteApplicationChaincode(ctx, "otherchannel", "assetcc")
+	require.EqualError(t, err, "Application chaincode assetcc on channel otherchannel does not exist")
+}
+
+func TestListApplicationChaincodes(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	applicationChaincodes := []interface{}{
+		&ApplicationChaincode{ChaincodeID: "simpleasset", Channel: "mychannel", OwnerOrg: "Org1MSP", Enabled: true},
+		&ApplicationChaincode{ChaincodeID: "assetcc", Channel: "mychannel", OwnerOrg: "Org2MSP", Enabled: false},
+	}
+	getChaincodeIDs := func(pageJSON string) []string {
+		var page ConfigPage
+		require.NoError(t, json.Unmarshal([]byte(pageJSON), &page))
+		chaincodeIDs := []string{}
+		for _, record := range page.Records {
+			applicationChaincode, err := decodeApplicationChaincode(record)
+			require.NoError(t, err)
+			chaincodeIDs = append(chaincodeIDs, applicationChaincode.ChaincodeID)
+		}
+		return chaincodeIDs
+	}
+
+	prepConfigPage(t, chaincodeStub, applicationChaincodes...)
+	pageJSON, err := interopcc.ListApplicationChaincodes(ctx, "", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"simpleasset", "assetcc"}, getChaincodeIDs(pageJSON))
+	objectType, _, _, _ := chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	require.Equal(t, applicationChaincodeObjectType, objectType)
+
+	// Test: Filter by owner org
+	prepConfigPage(t, chaincodeStub, applicationChaincodes...)
+	pageJSON, err = interopcc.ListApplicationChaincodes(ctx, "Org2MSP", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"assetcc"}, getChaincodeIDs(pageJSON))
+}
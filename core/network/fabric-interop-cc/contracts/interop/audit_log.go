@@ -0,0 +1,175 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// auditlog contains the functions used to record interop operations in a queryable audit log on the ledger
+// and to emit the corresponding chaincode events
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	auditLogObjectType = "auditLog"
+	// localSecurityDomain is the security domain recorded for operations that do not involve a known remote network,
+	// e.g., asset exchange operations and changes to the local configuration
+	localSecurityDomain = "local"
+
+	handleExternalRequestEventName = "HandleExternalRequest"
+	writeExternalStateEventName    = "WriteExternalState"
+)
+
+// AuditLogEntry records an interop operation.
+//
+// Entries are keyed by the security domain of the remote network and the request ID, which is the ID of the query
+// for requests from remote networks, the contractId for asset exchange operations and the transaction ID otherwise.
+type AuditLogEntry struct {
+	Operation      string            `json:"operation"`
+	SecurityDomain string            `json:"securityDomain"`
+	RequestID      string            `json:"requestId"`
+	TxID           string            `json:"txId"`
+	Timestamp      int64             `json:"timestamp"`
+	Client         string            `json:"client"`
+	Details        map[string]string `json:"details,omitempty"`
+}
+
+// AuditLogPage is a page of audit log entries, along with the bookmark to fetch the next page
+type AuditLogPage struct {
+	Entries  []*AuditLogEntry `json:"entries"`
+	Bookmark string           `json:"bookmark"`
+}
+
+// newAuditLogEntry creates an entry for an operation in the current transaction. The request ID defaults to the
+// transaction ID if empty.
+func newAuditLogEntry(ctx contractapi.TransactionContextInterface, operation string, securityDomain string, requestID string, details map[string]string) (*AuditLogEntry, error) {
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get client MSP ID: %s", err)
+	}
+	txID := ctx.GetStub().GetTxID()
+	if requestID == "" {
+		requestID = txID
+	}
+	return &AuditLogEntry{
+		Operation:      operation,
+		SecurityDomain: securityDomain,
+		RequestID:      requestID,
+		TxID:           txID,
+		Timestamp:      txTimeSecs,
+		Client:         client,
+		Details:        details,
+	}, nil
+}
+
+// putAuditLogEntries records entries in the audit log
+func putAuditLogEntries(ctx contractapi.TransactionContextInterface, entries ...*AuditLogEntry) error {
+	for _, entry := range entries {
+		entryKey, err := ctx.GetStub().CreateCompositeKey(auditLogObjectType, []string{entry.SecurityDomain, entry.RequestID, entry.TxID, entry.Operation})
+		if err != nil {
+			return err
+		}
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("Marshal error: %s", err)
+		}
+		err = ctx.GetStub().PutState(entryKey, entryBytes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordInteropEvent records entries in the audit log and emits them as the payload (a JSON array) of a chaincode
+// event named after the operation. Fabric only delivers the last event set in a transaction, so this must be called
+// at most once per transaction.
+func recordInteropEvent(ctx contractapi.TransactionContextInterface, eventName string, entries ...*AuditLogEntry) error {
+	err := putAuditLogEntries(ctx, entries...)
+	if err != nil {
+		return fmt.Errorf("Unable to record audit log: %s", err)
+	}
+	eventBytes, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().SetEvent(eventName, eventBytes)
+	if err != nil {
+		return fmt.Errorf("Unable to set event %s: %s", eventName, err)
+	}
+	return nil
+}
+
+// recordConfigChange records a change to the interop configuration for a security domain and emits the corresponding event
+func recordConfigChange(ctx contractapi.TransactionContextInterface, operation string, securityDomain string) error {
+	entry, err := newAuditLogEntry(ctx, operation, securityDomain, "", nil)
+	if err != nil {
+		return err
+	}
+	return recordInteropEvent(ctx, operation, entry)
+}
+
+// recordAssetExchangeOperation records an asset exchange operation in the audit log. The event for the operation
+// is emitted by the asset exchange library.
+func recordAssetExchangeOperation(ctx contractapi.TransactionContextInterface, operation string, contractId string, callerChaincodeID string) error {
+	entry, err := newAuditLogEntry(ctx, operation, localSecurityDomain, contractId, map[string]string{"callerChaincodeId": callerChaincodeID})
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
+	err = putAuditLogEntries(ctx, entry)
+	if err != nil {
+		return logThenErrorf("failed to record audit log: %+v", err)
+	}
+	return nil
+}
+
+// GetAuditLog cc returns a page of the audit log entries for a security domain, optionally restricted to a request ID.
+// An empty bookmark fetches the first page.
+func (s *SmartContract) GetAuditLog(ctx contractapi.TransactionContextInterface, securityDomain string, requestID string, pageSize int32, bookmark string) (string, error) {
+	if securityDomain == "" {
+		return "", fmt.Errorf("Security domain must be specified")
+	}
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return "", err
+	}
+	keys := []string{securityDomain}
+	if requestID != "" {
+		keys = append(keys, requestID)
+	}
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(auditLogObjectType, keys, pageSize, bookmark)
+	if err != nil {
+		return "", err
+	}
+	defer iterator.Close()
+	page := AuditLogPage{Entries: []*AuditLogEntry{}}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return "", err
+		}
+		var entry AuditLogEntry
+		err = json.Unmarshal(result.Value, &entry)
+		if err != nil {
+			return "", fmt.Errorf("Unmarshal error: %s", err)
+		}
+		page.Entries = append(page.Entries, &entry)
+	}
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+	}
+	pageBytes, err := json.Marshal(&page)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(pageBytes), nil
+}
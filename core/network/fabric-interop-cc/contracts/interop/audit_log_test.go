@@ -0,0 +1,94 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordConfigChange(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	chaincodeStub.GetTxIDReturns("tx1")
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: 1628467200}, nil)
+	chaincodeStub.CreateCompositeKeyReturns("auditLogKey", nil)
+
+	err := recordConfigChange(ctx, "CreateMembership", "network1")
+	require.NoError(t, err)
+	_, attributes := chaincodeStub.CreateCompositeKeyArgsForCall(0)
+	require.Equal(t, []string{"network1", "tx1", "tx1", "CreateMembership"}, attributes)
+	key, entryBytes := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "auditLogKey", key)
+	var entry AuditLogEntry
+	require.NoError(t, json.Unmarshal(entryBytes, &entry))
+	expectedEntry := AuditLogEntry{
+		Operation:      "CreateMembership",
+		SecurityDomain: "network1",
+		RequestID:      "tx1",
+		TxID:           "tx1",
+		Timestamp:      1628467200,
+		Client:         "Org1Testmsp",
+	}
+	require.Equal(t, expectedEntry, entry)
+
+	eventName, eventBytes := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "CreateMembership", eventName)
+	var eventEntries []AuditLogEntry
+	require.NoError(t, json.Unmarshal(eventBytes, &eventEntries))
+	require.Equal(t, []AuditLogEntry{expectedEntry}, eventEntries)
+
+	// Test: Transaction timestamp unavailable
+	chaincodeStub.GetTxTimestampReturns(nil, nil)
+	err = recordConfigChange(ctx, "CreateMembership", "network1")
+	require.EqualError(t, err, "Transaction timestamp is not set")
+}
+
+func TestGetAuditLog(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	entry := AuditLogEntry{
+		Operation:      handleExternalRequestEventName,
+		SecurityDomain: "network1",
+		RequestID:      "request1",
+		TxID:           "tx1",
+		Timestamp:      1628467200,
+		Client:         "Org1MSP",
+		Details:        map[string]string{"address": "localhost:9080/network1/mychannel:interop:Read:a"},
+	}
+	entryBytes, err := json.Marshal(&entry)
+	require.NoError(t, err)
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, false)
+	iterator.NextReturns(&queryresult.KV{Key: "auditLogKey", Value: entryBytes}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyWithPaginationReturns(iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: "bookmark1"}, nil)
+
+	pageJSON, err := interopcc.GetAuditLog(ctx, "network1", "request1", 10, "")
+	require.NoError(t, err)
+	var page AuditLogPage
+	require.NoError(t, json.Unmarshal([]byte(pageJSON), &page))
+	require.Equal(t, "bookmark1", page.Bookmark)
+	require.Equal(t, []*AuditLogEntry{&entry}, page.Entries)
+	objectType, keys, pageSize, bookmark := chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	require.Equal(t, auditLogObjectType, objectType)
+	require.Equal(t, []string{"network1", "request1"}, keys)
+	require.Equal(t, int32(10), pageSize)
+	require.Equal(t, "", bookmark)
+
+	// Test: Invalid arguments
+	_, err = interopcc.GetAuditLog(ctx, "", "", 10, "")
+	require.EqualError(t, err, "Security domain must be specified")
+	_, err = interopcc.GetAuditLog(ctx, "network1", "", 0, "")
+	require.EqualError(t, err, "Page size must be between 1 and 1000, found 0")
+}
@@ -8,20 +8,26 @@
 package main
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"hash"
 	"math/big"
+	"strings"
 	"time"
 
-	"golang.org/x/crypto/ed25519"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 )
 
 const (
@@ -72,12 +78,12 @@ func getCertChainOptions(rootCerts []interface{}, intermediateCerts []interface{
 	return *certOptions, nil
 }
 
-func verifyCaCertificate(cert *x509.Certificate, memberCertificate string) error {
+func verifyCaCertificate(cert *x509.Certificate, memberCertificate string, txTime time.Time) error {
 	memberX509Cert, err := parseCert(memberCertificate)
 	if err != nil {
 		return err
 	}
-	err = validateCertificateUsingCA(cert, memberX509Cert, true)
+	err = validateCertificateUsingCA(cert, memberX509Cert, true, txTime)
 	if err != nil {
 		return fmt.Errorf("CA Certificate is not valid: %s", err.Error())
 	}
@@ -88,7 +94,7 @@ func verifyCaCertificate(cert *x509.Certificate, memberCertificate string) error
    The assumption is that a Corda network has a single Root CA and Doorman CA, and one or more Node CAs corresponding to nodes.
    This function will receive arguments for exactly one node with the following cert chain assumed: <root cert> -> <int cert 0> -> <int cert 1>
 */
-func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
+func verifyCertificateChain(cert *x509.Certificate, certPEMs []string, txTime time.Time) error {
 	var parentCert *x509.Certificate
 	for i, certPEM := range certPEMs {
 		decodedCert, _ := pem.Decode([]byte(certPEM))
@@ -101,13 +107,13 @@ func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
 		}
 
 		if i > 0 {
-			err := validateCertificateUsingCA(caCert, parentCert, i == 1)
+			err := validateCertificateUsingCA(caCert, parentCert, i == 1, txTime)
 			if err != nil {
 				errMsg := fmt.Sprintf("Certificate link for Subject %s with Parent Subject %s invalid", caCert.Subject.String(), parentCert.Subject.String())
 				return errors.New(errMsg)
 			}
 			if i == len(certPEMs)-1 {
-				err := validateCertificateUsingCA(cert, caCert, i == 1)
+				err := validateCertificateUsingCA(cert, caCert, i == 1, txTime)
 				if err != nil {
 					return errors.New("Certificate link invalid for endorser")
 				}
@@ -119,7 +125,7 @@ func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
 	return nil
 }
 
-func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certificate, isSignerRootCA bool) error {
+func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certificate, isSignerRootCA bool, txTime time.Time) error {
 	var err error
 	if isSignerRootCA {
 		if err = signerCACert.CheckSignature(signerCACert.SignatureAlgorithm, signerCACert.RawTBSCertificate, signerCACert.Signature); err != nil {
@@ -129,7 +135,7 @@ func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certi
 	if err = signerCACert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
 		return err
 	}
-	err = isCertificateWithinExpiry(cert)
+	err = isCertificateWithinExpiry(cert, txTime)
 	if err != nil {
 		errMsg := fmt.Sprintf("Certificate is outside of expiry date. No longer valid. Cert: %s", cert.Subject.String())
 		return errors.New(errMsg)
@@ -142,18 +148,6 @@ func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certi
 	return nil
 }
 
-// Check if 'PublicKey' field in cert is nil
-// Fabric certificates contain such keys, whereas Corda certificates contain ED25519 keys (but only in raw form)
-// So this check serves to distinguish Corda certificates from Fabric certificates
-func getECDSAPublicKeyFromCertificate(cert *x509.Certificate) *ecdsa.PublicKey {
-	if cert.PublicKey != nil {
-		if certPublicKey, isEcdsaKey := cert.PublicKey.(*ecdsa.PublicKey); isEcdsaKey {
-			return certPublicKey
-		}
-	}
-	return nil
-}
-
 // extracted almost verbatim from core/chaincode/shim/crypto/ecdsa/hash.go (HLF v0)
 func computeSHA2Hash(msg []byte, bitsize int) ([]byte, error) {
 	var hash hash.Hash
@@ -211,30 +205,79 @@ func verifyEd25519Signature(pubKey []byte, hashedMessage []byte, signature []byt
 	return nil
 }
 
+// getECDSAHashBitSize returns the size of the SHA-2 digest matching the curve of an ECDSA key,
+// i.e., SHA-256 for P-256, SHA-384 for P-384 and SHA-512 for P-521
+func getECDSAHashBitSize(pubKey *ecdsa.PublicKey) int {
+	return pubKey.Curve.Params().BitSize
+}
+
+// getRSAHash returns the hash function used with an RSA key, based on the signature algorithm of its certificate.
+// SHA-256 is used if the certificate does not specify a SHA-384 or SHA-512 based algorithm.
+func getRSAHash(signatureAlgorithm x509.SignatureAlgorithm) crypto.Hash {
+	switch signatureAlgorithm {
+	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS, x509.ECDSAWithSHA384:
+		return crypto.SHA384
+	case x509.SHA512WithRSA, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA512:
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// isRSAPSSAlgorithm returns true if the signature algorithm uses the RSA-PSS padding scheme
+func isRSAPSSAlgorithm(signatureAlgorithm x509.SignatureAlgorithm) bool {
+	switch signatureAlgorithm {
+	case x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate RSA signature. The PSS padding scheme is used if the certificate is signed with RSA-PSS, else PKCS #1 v1.5
+func rsaVerify(verKey *rsa.PublicKey, signatureAlgorithm x509.SignatureAlgorithm, message []byte, signature []byte) error {
+	hashFunc := getRSAHash(signatureAlgorithm)
+	hasher := hashFunc.New()
+	hasher.Write(message)
+	hashed := hasher.Sum(nil)
+
+	var err error
+	if isRSAPSSAlgorithm(signatureAlgorithm) {
+		err = rsa.VerifyPSS(verKey, hashFunc, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: hashFunc})
+	} else {
+		err = rsa.VerifyPKCS1v15(verKey, hashFunc, hashed, signature)
+	}
+	if err != nil {
+		return errors.New("Signature Verification failed. RSA VERIFY")
+	}
+	return nil
+}
+
 // Validate signature
 func validateSignature(message string, cert *x509.Certificate, signature string) error {
 	if len(signature) == 0 {
 		return errors.New("Empty signature")
 	}
 
-	// First check if the public key in the cert is an ECDSA public key
-	pubKey := getECDSAPublicKeyFromCertificate(cert)
-	if pubKey != nil {
-		// Construct the message that was signed
-		hashed, err := computeSHA2Hash([]byte(message), 256)
+	switch pubKey := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		// Fabric certificates contain ECDSA keys. The message is hashed using the digest matching the curve.
+		hashed, err := computeSHA2Hash([]byte(message), getECDSAHashBitSize(pubKey))
 		if err != nil {
 			return err
 		}
 		return ecdsaVerify(pubKey, hashed, []byte(signature))
-	} else if cert.RawSubjectPublicKeyInfo != nil && len(cert.RawSubjectPublicKeyInfo) == 44 {
-		// ed25519 public key
-		// We expect the key to be 44 bytes, but only the last 32 bytes (multiple of 8) comprise the public key
+	case *rsa.PublicKey:
+		return rsaVerify(pubKey, cert.SignatureAlgorithm, []byte(message), []byte(signature))
+	case ed25519.PublicKey:
+		// Corda certificates typically contain ED25519 keys
 		// Message in ed25519 is hashed by default as part of the signature algorithm. Uses SHA512
-		return verifyEd25519Signature(cert.RawSubjectPublicKeyInfo[12:], []byte(message), []byte(signature))
-	} else {
+		return verifyEd25519Signature(pubKey, []byte(message), []byte(signature))
+	default:
 		return errors.New("Missing or unsupported public key type")
 	}
 }
+
 func parseCert(certString string) (*x509.Certificate, error) {
 	certBytes, _ := pem.Decode([]byte(certString))
 
@@ -249,14 +292,147 @@ func parseCert(certString string) (*x509.Certificate, error) {
 	return cert, err
 }
 
-func isCertificateWithinExpiry(cert *x509.Certificate) error {
+// isCertificateWithinExpiry checks that a certificate is valid at the time of the transaction. The transaction
+// time is the same on all endorsing peers, unlike their clocks, so they reach the same decision.
+func isCertificateWithinExpiry(cert *x509.Certificate, txTime time.Time) error {
 	if cert == nil {
 		return errors.New("Cert is nil")
 	}
 	certLocation := cert.NotBefore.Location()
-	currentDate := time.Now().In(certLocation)
+	currentDate := txTime.In(certLocation)
 	if currentDate.After(cert.NotBefore) && currentDate.Before(cert.NotAfter) {
 		return nil
 	}
 	return errors.New("Cert is invalid")
 }
+
+// parseCRL parses a PEM encoded certificate revocation list
+func parseCRL(crlString string) (*pkix.CertificateList, error) {
+	crlBytes, _ := pem.Decode([]byte(crlString))
+	if crlBytes == nil {
+		return nil, errors.New("CRL not in a known PEM format")
+	}
+	return x509.ParseDERCRL(crlBytes.Bytes)
+}
+
+// parseCertificateSerial parses a hex encoded certificate serial number, which may have a '0x' prefix
+// and may be colon separated (as printed by openssl)
+func parseCertificateSerial(serial string) (*big.Int, error) {
+	hexSerial := strings.ReplaceAll(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(serial)), "0x"), ":", "")
+	serialNumber, ok := new(big.Int).SetString(hexSerial, 16)
+	if !ok {
+		return nil, fmt.Errorf("Invalid certificate serial number: %s", serial)
+	}
+	return serialNumber, nil
+}
+
+// getCRLIssuer returns the distinguished name of the issuer of a CRL, in the same form as x509.Certificate.Issuer.String()
+func getCRLIssuer(crl *pkix.CertificateList) string {
+	var issuer pkix.Name
+	issuer.FillFromRDNSequence(&crl.TBSCertList.Issuer)
+	return issuer.String()
+}
+
+// getMemberCACertificates returns the PEM encoded certificates that can issue certificates (and CRLs) for a member
+func getMemberCACertificates(member *common.Member) []string {
+	if member.Type == "certificate" && len(member.Chain) > 0 {
+		return member.Chain
+	}
+	return []string{member.Value}
+}
+
+// verifyCRLIssuedByMember checks that a CRL is signed by one of the member's certificate authorities
+func verifyCRLIssuedByMember(crl *pkix.CertificateList, member *common.Member) error {
+	for _, caCertPEM := range getMemberCACertificates(member) {
+		caCert, err := parseCert(caCertPEM)
+		if err != nil {
+			continue
+		}
+		if caCert.Subject.String() == getCRLIssuer(crl) && caCert.CheckCRLSignature(crl) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("CRL issued by %s is not signed by a certificate authority of the member", getCRLIssuer(crl))
+}
+
+// validateMemberRevocations checks that the CRLs of a member are well-formed and signed by the member's
+// certificate authorities, and that the revoked serial numbers are well-formed
+func validateMemberRevocations(member *common.Member) error {
+	for _, crlPEM := range member.Crls {
+		crl, err := parseCRL(crlPEM)
+		if err != nil {
+			return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+		}
+		err = verifyCRLIssuedByMember(crl, member)
+		if err != nil {
+			return err
+		}
+	}
+	for _, serial := range member.RevokedSerials {
+		_, err := parseCertificateSerial(serial)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyCertificateNotRevoked checks that a certificate is neither in the member's revoked serial list
+// nor in any of the member's CRLs issued by the certificate's issuer
+func verifyCertificateNotRevoked(cert *x509.Certificate, member *common.Member) error {
+	for _, serial := range member.RevokedSerials {
+		serialNumber, err := parseCertificateSerial(serial)
+		if err != nil {
+			return err
+		}
+		if serialNumber.Cmp(cert.SerialNumber) == 0 {
+			return fmt.Errorf("Certificate with serial number %x has been revoked", cert.SerialNumber)
+		}
+	}
+	for _, crlPEM := range member.Crls {
+		crl, err := parseCRL(crlPEM)
+		if err != nil {
+			return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+		}
+		if getCRLIssuer(crl) != cert.Issuer.String() {
+			continue
+		}
+		for _, revokedCert := range crl.TBSCertList.RevokedCertificates {
+			if revokedCert.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("Certificate with serial number %x has been revoked by %s", cert.SerialNumber, getCRLIssuer(crl))
+			}
+		}
+	}
+	return nil
+}
+
+// fabricAttributesOID is the certificate extension in which a Fabric CA encodes the attributes of an identity
+var fabricAttributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// hasCertificateOU checks if a certificate has an organizational unit, ignoring case
+func hasCertificateOU(cert *x509.Certificate, ou string) bool {
+	for _, certOU := range cert.Subject.OrganizationalUnit {
+		if strings.EqualFold(certOU, ou) {
+			return true
+		}
+	}
+	return false
+}
+
+// getCertificateAttributes returns the attributes encoded in a certificate issued by a Fabric CA
+func getCertificateAttributes(cert *x509.Certificate) (map[string]string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(fabricAttributesOID) {
+			continue
+		}
+		var attributes struct {
+			Attrs map[string]string `json:"attrs"`
+		}
+		err := json.Unmarshal(ext.Value, &attributes)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal certificate attributes: %s", err.Error())
+		}
+		return attributes.Attrs, nil
+	}
+	return map[string]string{}, nil
+}
@@ -14,6 +14,8 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -78,8 +80,12 @@ GqO4M1KlfimphQwCICiq00hDanT5W8bTLqE7GIGuplf/O8AABlpWrUg6uiUB
 	cordaCert, err := parseCert("-----BEGIN CERTIFICATE-----\nMIIBwjCCAV+gAwIBAgIIUJkQvmKm35YwFAYIKoZIzj0EAwIGCCqGSM49AwEHMC8x\nCzAJBgNVBAYTAkdCMQ8wDQYDVQQHDAZMb25kb24xDzANBgNVBAoMBlBhcnR5QTAe\nFw0yMDA3MjQwMDAwMDBaFw0yNzA1MjAwMDAwMDBaMC8xCzAJBgNVBAYTAkdCMQ8w\nDQYDVQQHDAZMb25kb24xDzANBgNVBAoMBlBhcnR5QTAqMAUGAytlcAMhAMMKaREK\nhcTgSBMMzK81oPUSPoVmG/fJMLXq/ujSmse9o4GJMIGGMB0GA1UdDgQWBBRMXtDs\nKFZzULdQ3c2DCUEx3T1CUDAPBgNVHRMBAf8EBTADAQH/MAsGA1UdDwQEAwIChDAT\nBgNVHSUEDDAKBggrBgEFBQcDAjAfBgNVHSMEGDAWgBR4hwLuLgfIZMEWzG4n3Axw\nfgPbezARBgorBgEEAYOKYgEBBAMCAQYwFAYIKoZIzj0EAwIGCCqGSM49AwEHA0cA\nMEQCIC7J46SxDDz3LjDNrEPjjwP2prgMEMh7r/gJpouQHBk+AiA+KzXD0d5miI86\nD2mYK4C3tRli3X3VgnCe8COqfYyuQg==\n-----END CERTIFICATE-----")
 	require.NoError(t, err)
 
-	err = verifyCertificateChain(cordaCert, certs)
+	err = verifyCertificateChain(cordaCert, certs, time.Date(2021, time.August, 9, 0, 0, 0, 0, time.UTC))
 	require.NoError(t, err)
+
+	// Test: Chain verified after the certificates have expired
+	err = verifyCertificateChain(cordaCert, certs, time.Date(2028, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.Error(t, err)
 }
 func TestParseCert(t *testing.T) {
 	// Test: Valid cert (happy case)
@@ -108,7 +114,7 @@ func TestIsCertificateWithinExpiry(t *testing.T) {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.NoError(t, err)
 
 	// Test: Expired cert case
@@ -117,16 +123,20 @@ func TestIsCertificateWithinExpiry(t *testing.T) {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.EqualError(t, err, fmt.Sprintf("Cert is invalid"))
 
+	// Test: Expired cert is valid at an earlier transaction time
+	err = isCertificateWithinExpiry(x509Cert, now.Add(-5*threeDays/2))
+	require.NoError(t, err)
+
 	// Test: Not valid yet case
 	x509Cert, err = createCertWithTimeRange(now.Add(3*threeDays), now.Add(4*threeDays), "ecdsa")
 	if err != nil {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.EqualError(t, err, fmt.Sprintf("Cert is invalid"))
 }
 
@@ -181,6 +191,80 @@ func TestEd25519SignatureValidation(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestValidateSignatureKeyTypes(t *testing.T) {
+	message := "localhost:9080/network1/mychannel:interop:Read:anonce"
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: "example-a.com",
+		},
+		SerialNumber: big.NewInt(1337),
+	}
+
+	// Test: ECDSA P-384 key, message hashed with SHA-384
+	testKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &testKey.PublicKey, testKey)
+	require.NoError(t, err)
+	x509Cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+	hashed := sha512.Sum384([]byte(message))
+	signature, err := ecdsa.SignASN1(rand.Reader, testKey, hashed[:])
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.NoError(t, err)
+	sha256Hashed := sha256.Sum256([]byte(message))
+	signature, err = ecdsa.SignASN1(rand.Reader, testKey, sha256Hashed[:])
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.EqualError(t, err, "Signature Verification failed. ECDSA VERIFY")
+
+	// Test: RSA key with PKCS #1 v1.5 signatures
+	rsaTemplate := template
+	rsaTemplate.SignatureAlgorithm = x509.SHA256WithRSA
+	certBytes, rsaKey, err := createRSACertAndKeyFromTemplate(rsaTemplate)
+	require.NoError(t, err)
+	x509Cert, err = x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+	signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sha256Hashed[:])
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.NoError(t, err)
+	signature, err = rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, sha256Hashed[:], nil)
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.EqualError(t, err, "Signature Verification failed. RSA VERIFY")
+
+	// Test: RSA key with PSS signatures using SHA-384
+	rsaTemplate.SignatureAlgorithm = x509.SHA384WithRSAPSS
+	certBytes, rsaKey, err = createRSACertAndKeyFromTemplate(rsaTemplate)
+	require.NoError(t, err)
+	x509Cert, err = x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+	signature, err = rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA384, hashed[:], nil)
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.NoError(t, err)
+	signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA384, hashed[:])
+	require.NoError(t, err)
+	err = validateSignature(message, x509Cert, string(signature))
+	require.EqualError(t, err, "Signature Verification failed. RSA VERIFY")
+
+	// Test: Ed25519 key
+	certBytes, edKey, err := createED25519CertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	x509Cert, err = x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+	signature = ed25519.Sign(*edKey, []byte(message))
+	err = validateSignature(message, x509Cert, string(signature))
+	require.NoError(t, err)
+	err = validateSignature("tampered message", x509Cert, string(signature))
+	require.EqualError(t, err, "Signature is not valid. ED25519 VERIFY")
+
+	// Test: Unsupported key type
+	err = validateSignature(message, &x509.Certificate{}, string(signature))
+	require.EqualError(t, err, "Missing or unsupported public key type")
+}
+
 func generateCertFromTemplate(template x509.Certificate, keyType string) ([]byte, error) {
 	random := rand.Reader
 	switch keyType {
@@ -264,3 +348,15 @@ func createECDSACertAndKeyFromTemplate(template x509.Certificate) ([]byte, *ecds
 	return certBytes, testKey, err
 
 }
+
+func createRSACertAndKeyFromTemplate(template x509.Certificate) ([]byte, *rsa.PrivateKey, error) {
+	random := rand.Reader
+
+	testKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		fmt.Printf("rsa ERROR %s \n", err.Error())
+		return nil, nil, err
+	}
+	certBytes, err := x509.CreateCertificate(random, &template, &template, &testKey.PublicKey, testKey)
+	return certBytes, testKey, err
+}
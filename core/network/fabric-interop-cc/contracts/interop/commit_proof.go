@@ -0,0 +1,268 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// commit_proof contains the verification of Fabric views carrying proofs that the transaction returning the view was
+// committed to the ledger of the remote network
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	fabriccommon "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// fabricCommitmentProofType is the proof type of views carrying a FabricCommitView
+const fabricCommitmentProofType = "Commitment"
+
+// qsccChaincodeName is the name of the system chaincode whose GetTransactionByID response attests the validation
+// code of a committed transaction
+const qsccChaincodeName = "qscc"
+
+// asn1BlockHeader is the encoding of a block header signed by the orderers
+type asn1BlockHeader struct {
+	Number       *big.Int
+	PreviousHash []byte
+	DataHash     []byte
+}
+
+// The verifyFabricCommitment function is used to verify views that come from a Fabric network
+// that were generated with Commitment proofs.
+//
+// Verification requires the following checks to be performed:
+// 1. Ensure the response is in a valid format - view data should be parsed to [FabricCommitView].
+// 2. Verify address in payload is the same as original address
+// 3. Verify the block data hashes to the data hash in the block header.
+// 4. Verify each of the orderer signatures over the block header, and check each of the orderer certificates
+// matches the member's entry in the network's Membership. At least one orderer signature is required.
+// 5. Ensure the transaction envelope in the block is an endorser transaction on the channel of the address.
+// 6. Check the transaction was committed as valid, according to the qscc response endorsed by the peers, as the
+// validation flags of a block are not signed by the orderers.
+// 7. Verify each of the endorser signatures in the transaction and check each of the endorser certificates matches
+// the member's entry in the network's Membership.
+// 8. Verify the response matches the response of the chaincode action in the transaction.
+// 9. Check the endorsements fulfill the verification policy of the request.
+func verifyFabricCommitment(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain string, address string) ([]string, error) {
+	// 1. Ensure the response is in a valid format
+	var fabricCommitView fabric.FabricCommitView
+	err := protoV2.Unmarshal(data, &fabricCommitView)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode fabric commit view data: %s", err.Error())
+	}
+	if fabricCommitView.BlockHeader == nil || fabricCommitView.BlockData == nil || fabricCommitView.BlockSignatures == nil {
+		return nil, fmt.Errorf("Fabric commit view does not contain the block header, data and signatures")
+	}
+	// 2. Verify address in payload is the same as original address
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal(fabricCommitView.Response.GetPayload(), &interopPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+	}
+	if address != interopPayload.Address {
+		return nil, fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+	}
+	// 3. Verify the block data hashes to the data hash in the block header
+	dataHash := sha256.Sum256(bytes.Join(fabricCommitView.BlockData.Data, nil))
+	if !bytes.Equal(dataHash[:], fabricCommitView.BlockHeader.DataHash) {
+		return nil, fmt.Errorf("Block data does not match the data hash of block %d", fabricCommitView.BlockHeader.Number)
+	}
+	// 4. Verify each of the orderer signatures over the block header
+	err = verifyFabricBlockSignatures(s, ctx, fabricCommitView.BlockHeader, fabricCommitView.BlockSignatures, securityDomain)
+	if err != nil {
+		return nil, err
+	}
+	// 5. Ensure the transaction envelope in the block is an endorser transaction on the channel of the address
+	if int(fabricCommitView.TransactionIndex) >= len(fabricCommitView.BlockData.Data) {
+		return nil, fmt.Errorf("Transaction index %d is out of range for block %d", fabricCommitView.TransactionIndex, fabricCommitView.BlockHeader.Number)
+	}
+	envelopeBytes := fabricCommitView.BlockData.Data[fabricCommitView.TransactionIndex]
+	endorsedAction, err := getEndorsedAction(envelopeBytes, address)
+	if err != nil {
+		return nil, err
+	}
+	// 6. Check the transaction was committed as valid, according to the qscc response endorsed by the peers
+	err = verifyFabricTransactionValidation(s, ctx, &fabricCommitView, envelopeBytes, verificationPolicy, securityDomain)
+	if err != nil {
+		return nil, err
+	}
+	// 7. Verify each of the endorser signatures in the transaction
+	signerList, err := verifyFabricEndorsements(s, ctx, endorsedAction.ProposalResponsePayload, endorsedAction.Endorsements, securityDomain)
+	if err != nil {
+		return nil, err
+	}
+	// 8. Verify the response matches the response of the chaincode action in the transaction
+	var proposalResponsePayload peer.ProposalResponsePayload
+	err = proto.Unmarshal(endorsedAction.ProposalResponsePayload, &proposalResponsePayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal ProposalResponsePayload: %s", err.Error())
+	}
+	err = verifyFabricChaincodeResponse(proposalResponsePayload.Extension, fabricCommitView.Response.GetPayload(), &interopPayload)
+	if err != nil {
+		return nil, err
+	}
+	// 9. Check the endorsements fulfill the verification policy of the request.
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Commitment of response '%s' from Fabric network in block %d for query '%s' is VALID", string(fabricCommitView.Response.GetPayload()), fabricCommitView.BlockHeader.Number, address)
+	return signerList, nil
+}
+
+// verifyFabricTransactionValidation checks that the transaction envelope at the index of a commit view was committed
+// as valid, according to the ProcessedTransaction returned by qscc to GetTransactionByID. The qscc response must be
+// endorsed by members of the network satisfying the verification policy.
+func verifyFabricTransactionValidation(s *SmartContract, ctx contractapi.TransactionContextInterface, fabricCommitView *fabric.FabricCommitView, envelopeBytes []byte, verificationPolicy *common.Policy, securityDomain string) error {
+	blockNumber := fabricCommitView.BlockHeader.Number
+	if fabricCommitView.ValidationProposalResponsePayload == nil {
+		return fmt.Errorf("Fabric commit view does not contain the validation response for the transaction in block %d", blockNumber)
+	}
+	proposalResponsePayloadBytes, err := proto.Marshal(fabricCommitView.ValidationProposalResponsePayload)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal validation proposal response payload: %s", err.Error())
+	}
+	signerList, err := verifyFabricEndorsements(s, ctx, proposalResponsePayloadBytes, fabricCommitView.ValidationEndorsements, securityDomain)
+	if err != nil {
+		return fmt.Errorf("Validation response verification failed: %s", err.Error())
+	}
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return fmt.Errorf("Validation response verification failed: %s", err.Error())
+	}
+	var chaincodeAction peer.ChaincodeAction
+	err = proto.Unmarshal(fabricCommitView.ValidationProposalResponsePayload.Extension, &chaincodeAction)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal ChaincodeAction: %s", err.Error())
+	}
+	if chaincodeAction.ChaincodeId.GetName() != qsccChaincodeName {
+		return fmt.Errorf("Validation response was returned by chaincode %s instead of %s", chaincodeAction.ChaincodeId.GetName(), qsccChaincodeName)
+	}
+	var processedTransaction peer.ProcessedTransaction
+	err = proto.Unmarshal(chaincodeAction.Response.GetPayload(), &processedTransaction)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal ProcessedTransaction: %s", err.Error())
+	}
+	var envelope fabriccommon.Envelope
+	err = proto.Unmarshal(envelopeBytes, &envelope)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal transaction envelope: %s", err.Error())
+	}
+	if processedTransaction.TransactionEnvelope == nil || !proto.Equal(processedTransaction.TransactionEnvelope, &envelope) {
+		return fmt.Errorf("Validation response does not refer to the transaction at index %d of block %d", fabricCommitView.TransactionIndex, blockNumber)
+	}
+	validationCode := peer.TxValidationCode(processedTransaction.ValidationCode)
+	if validationCode != peer.TxValidationCode_VALID {
+		return fmt.Errorf("Transaction in block %d was not committed as valid: %s", blockNumber, validationCode)
+	}
+	return nil
+}
+
+// verifyFabricBlockSignatures verifies the orderer signatures in the SIGNATURES entry of the metadata of a block
+// over its header, and checks the orderer certificates against the network's Membership
+func verifyFabricBlockSignatures(s *SmartContract, ctx contractapi.TransactionContextInterface, blockHeader *fabriccommon.BlockHeader, blockSignatures *fabriccommon.Metadata, securityDomain string) error {
+	if len(blockSignatures.Signatures) == 0 {
+		return fmt.Errorf("Block %d is not signed by any orderer", blockHeader.Number)
+	}
+	blockHeaderBytes, err := asn1.Marshal(asn1BlockHeader{
+		Number:       new(big.Int).SetUint64(blockHeader.Number),
+		PreviousHash: blockHeader.PreviousHash,
+		DataHash:     blockHeader.DataHash,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to marshal block header: %s", err.Error())
+	}
+	for _, blockSignature := range blockSignatures.Signatures {
+		var signatureHeader fabriccommon.SignatureHeader
+		err = proto.Unmarshal(blockSignature.SignatureHeader, &signatureHeader)
+		if err != nil {
+			return fmt.Errorf("Unable to Unmarshal block signature header: %s", err.Error())
+		}
+		var serialisedIdentity msp.SerializedIdentity
+		err = proto.Unmarshal(signatureHeader.Creator, &serialisedIdentity)
+		if err != nil {
+			return fmt.Errorf("Unable to Unmarshal orderer identity: %s", err.Error())
+		}
+		x509Cert, err := parseCert(string(serialisedIdentity.IdBytes))
+		if err != nil {
+			return fmt.Errorf("Unable to parse certificate: %s", err.Error())
+		}
+		signedBytes := append(append(append([]byte{}, blockSignatures.Value...), blockSignature.SignatureHeader...), blockHeaderBytes...)
+		err = validateSignature(string(signedBytes), x509Cert, string(blockSignature.Signature))
+		if err != nil {
+			return fmt.Errorf("Unable to Validate orderer Signature: %s", err.Error())
+		}
+		err = verifyMemberInSecurityDomain(s, ctx, x509Cert, securityDomain, serialisedIdentity.Mspid)
+		if err != nil {
+			return fmt.Errorf("Verify membership failed. Orderer certificate not valid: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// getEndorsedAction decodes a transaction envelope and returns the endorsed chaincode action of the transaction,
+// after checking that it is an endorser transaction on the channel of the address
+func getEndorsedAction(envelopeBytes []byte, address string) (*peer.ChaincodeEndorsedAction, error) {
+	addressStruct, err := parseAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse address: %s", err.Error())
+	}
+	viewAddress, err := parseFabricViewAddress(addressStruct.ViewSegment)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse view address: %s", err.Error())
+	}
+	var envelope fabriccommon.Envelope
+	err = proto.Unmarshal(envelopeBytes, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal transaction envelope: %s", err.Error())
+	}
+	var payload fabriccommon.Payload
+	err = proto.Unmarshal(envelope.Payload, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal transaction payload: %s", err.Error())
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("Transaction payload does not contain a header")
+	}
+	var channelHeader fabriccommon.ChannelHeader
+	err = proto.Unmarshal(payload.Header.ChannelHeader, &channelHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal channel header: %s", err.Error())
+	}
+	if channelHeader.Type != int32(fabriccommon.HeaderType_ENDORSER_TRANSACTION) {
+		return nil, fmt.Errorf("Transaction %s is not an endorser transaction", channelHeader.TxId)
+	}
+	if channelHeader.ChannelId != viewAddress.Channel {
+		return nil, fmt.Errorf("Transaction %s was committed on channel %s instead of channel %s", channelHeader.TxId, channelHeader.ChannelId, viewAddress.Channel)
+	}
+	var transaction peer.Transaction
+	err = proto.Unmarshal(payload.Data, &transaction)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal transaction: %s", err.Error())
+	}
+	if len(transaction.Actions) == 0 {
+		return nil, fmt.Errorf("Transaction %s does not contain any actions", channelHeader.TxId)
+	}
+	var chaincodeActionPayload peer.ChaincodeActionPayload
+	err = proto.Unmarshal(transaction.Actions[0].Payload, &chaincodeActionPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal ChaincodeActionPayload: %s", err.Error())
+	}
+	if chaincodeActionPayload.Action == nil {
+		return nil, fmt.Errorf("Transaction %s does not contain an endorsed action", channelHeader.TxId)
+	}
+	return chaincodeActionPayload.Action, nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	fabriccommon "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// commitProofSigner signs endorsements and blocks on behalf of a member of the remote network
+type commitProofSigner struct {
+	mspID   string
+	certPEM string
+	key     *ecdsa.PrivateKey
+}
+
+func newCommitProofSigner(t *testing.T, mspID string, serialNumber int64) *commitProofSigner {
+	certDER, key, err := createECDSACertAndKeyFromTemplate(x509.Certificate{
+		Subject:      pkix.Name{CommonName: mspID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(serialNumber),
+	})
+	require.NoError(t, err)
+	return &commitProofSigner{mspID: mspID, certPEM: encodePEM("CERTIFICATE", certDER), key: key}
+}
+
+func (signer *commitProofSigner) identity(t *testing.T) []byte {
+	identityBytes, err := proto.Marshal(&msp.SerializedIdentity{Mspid: signer.mspID, IdBytes: []byte(signer.certPEM)})
+	require.NoError(t, err)
+	return identityBytes
+}
+
+func (signer *commitProofSigner) sign(t *testing.T, message []byte) []byte {
+	hashed := sha256.Sum256(message)
+	signature, err := ecdsa.SignASN1(rand.Reader, signer.key, hashed[:])
+	require.NoError(t, err)
+	return signature
+}
+
+// createFabricCommitView returns a commit view for a transaction on a channel returning a response, endorsed and
+// ordered by the signers, whose validation as VALID is attested by the endorser through qscc
+func createFabricCommitView(t *testing.T, channel string, responsePayload []byte, endorser *commitProofSigner, orderer *commitProofSigner) *fabric.FabricCommitView {
+	chaincodeActionBytes, err := proto.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: responsePayload}})
+	require.NoError(t, err)
+	proposalResponsePayloadBytes, err := proto.Marshal(&peer.ProposalResponsePayload{ProposalHash: []byte("proposal"), Extension: chaincodeActionBytes})
+	require.NoError(t, err)
+	endorserIdentity := endorser.identity(t)
+	chaincodeActionPayloadBytes, err := proto.Marshal(&peer.ChaincodeActionPayload{
+		Action: &peer.ChaincodeEndorsedAction{
+			ProposalResponsePayload: proposalResponsePayloadBytes,
+			Endorsements: []*peer.Endorsement{{
+				Endorser:  endorserIdentity,
+				Signature: endorser.sign(t, append(append([]byte{}, proposalResponsePayloadBytes...), endorserIdentity...)),
+			}},
+		},
+	})
+	require.NoError(t, err)
+	transactionBytes, err := proto.Marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{Payload: chaincodeActionPayloadBytes}}})
+	require.NoError(t, err)
+	channelHeaderBytes, err := proto.Marshal(&fabriccommon.ChannelHeader{Type: int32(fabriccommon.HeaderType_ENDORSER_TRANSACTION), ChannelId: channel, TxId: "tx1"})
+	require.NoError(t, err)
+	payloadBytes, err := proto.Marshal(&fabriccommon.Payload{Header: &fabriccommon.Header{ChannelHeader: channelHeaderBytes}, Data: transactionBytes})
+	require.NoError(t, err)
+	envelope := &fabriccommon.Envelope{Payload: payloadBytes}
+	envelopeBytes, err := proto.Marshal(envelope)
+	require.NoError(t, err)
+
+	blockData := &fabriccommon.BlockData{Data: [][]byte{[]byte("config"), envelopeBytes}}
+	dataHash := sha256.Sum256(bytes.Join(blockData.Data, nil))
+	blockHeader := &fabriccommon.BlockHeader{Number: 5, PreviousHash: []byte("previous"), DataHash: dataHash[:]}
+	blockHeaderBytes, err := asn1.Marshal(asn1BlockHeader{Number: big.NewInt(5), PreviousHash: blockHeader.PreviousHash, DataHash: blockHeader.DataHash})
+	require.NoError(t, err)
+	signatureHeaderBytes, err := proto.Marshal(&fabriccommon.SignatureHeader{Creator: orderer.identity(t), Nonce: []byte("nonce")})
+	require.NoError(t, err)
+	metadataValue := []byte("ordererBlockMetadata")
+	signedBytes := append(append(append([]byte{}, metadataValue...), signatureHeaderBytes...), blockHeaderBytes...)
+	validationProposalResponsePayload, validationEndorsements := createValidationResponse(t, envelope, peer.TxValidationCode_VALID, qsccChaincodeName, endorser)
+	return &fabric.FabricCommitView{
+		Response:    &peer.Response{Status: 200, Payload: responsePayload},
+		BlockHeader: blockHeader,
+		BlockData:   blockData,
+		BlockSignatures: &fabriccommon.Metadata{
+			Value:      metadataValue,
+			Signatures: []*fabriccommon.MetadataSignature{{SignatureHeader: signatureHeaderBytes, Signature: orderer.sign(t, signedBytes)}},
+		},
+		TransactionIndex:                  1,
+		ValidationProposalResponsePayload: validationProposalResponsePayload,
+		ValidationEndorsements:            validationEndorsements,
+	}
+}
+
+// createValidationResponse returns the response of a chaincode to GetTransactionByID for a transaction envelope with
+// a validation code, endorsed by the signer
+func createValidationResponse(t *testing.T, envelope *fabriccommon.Envelope, validationCode peer.TxValidationCode, chaincodeName string, endorser *commitProofSigner) (*peer.ProposalResponsePayload, []*peer.Endorsement) {
+	processedTransactionBytes, err := proto.Marshal(&peer.ProcessedTransaction{TransactionEnvelope: envelope, ValidationCode: int32(validationCode)})
+	require.NoError(t, err)
+	chaincodeActionBytes, err := proto.Marshal(&peer.ChaincodeAction{
+		Response:    &peer.Response{Status: 200, Payload: processedTransactionBytes},
+		ChaincodeId: &peer.ChaincodeID{Name: chaincodeName},
+	})
+	require.NoError(t, err)
+	proposalResponsePayload := &peer.ProposalResponsePayload{ProposalHash: []byte("qsccProposal"), Extension: chaincodeActionBytes}
+	proposalResponsePayloadBytes, err := proto.Marshal(proposalResponsePayload)
+	require.NoError(t, err)
+	endorserIdentity := endorser.identity(t)
+	return proposalResponsePayload, []*peer.Endorsement{{
+		Endorser:  endorserIdentity,
+		Signature: endorser.sign(t, append(append([]byte{}, proposalResponsePayloadBytes...), endorserIdentity...)),
+	}}
+}
+
+// getCommittedEnvelope returns the envelope of the transaction in a commit view
+func getCommittedEnvelope(t *testing.T, commitView *fabric.FabricCommitView) *fabriccommon.Envelope {
+	var envelope fabriccommon.Envelope
+	require.NoError(t, proto.Unmarshal(commitView.BlockData.Data[commitView.TransactionIndex], &envelope))
+	return &envelope
+}
+
+func TestVerifyFabricCommitment(t *testing.T) {
+	ctx, _, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	endorser := newCommitProofSigner(t, "Org1MSP", 1)
+	orderer := newCommitProofSigner(t, "OrdererMSP", 2)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: fabricNetwork,
+		Members: map[string]*common.Member{
+			"Org1MSP":    {Value: endorser.certPEM, Type: "ca"},
+			"OrdererMSP": {Value: orderer.certPEM, Type: "ca"},
+		},
+	})
+	require.NoError(t, err)
+	worldState["membership:"+fabricNetwork] = membershipBytes
+	verificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy)
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: fabricViewAddress, Payload: []byte("Arcturus")})
+	require.NoError(t, err)
+	// encodeView returns the base64 encoded view carrying a commit view
+	encodeView := func(commitView *fabric.FabricCommitView) string {
+		commitViewBytes, err := protoV2.Marshal(commitView)
+		require.NoError(t, err)
+		viewBytes, err := protoV2.Marshal(&common.View{
+			Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: fabricCommitmentProofType},
+			Data: commitViewBytes,
+		})
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(viewBytes)
+	}
+
+	// Test: Happy case
+	commitView := createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	require.NoError(t, interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress))
+	viewData, err := interopcc.ParseAndValidateView(ctx, fabricViewAddress, encodeView(commitView))
+	require.NoError(t, err)
+	require.Equal(t, []byte("Arcturus"), viewData)
+
+	// Test: The block data must match the data hash signed by the orderers
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.BlockData.Data[0] = []byte("tampered")
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Block data does not match the data hash of block 5")
+
+	// Test: The block must be signed by an orderer of the remote network
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, newCommitProofSigner(t, "OrdererMSP", 3))
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Orderer certificate not valid")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.BlockSignatures.Signatures = nil
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Block 5 is not signed by any orderer")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.BlockHeader.Number = 6
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unable to Validate orderer Signature")
+
+	// Test: The transaction must have been committed as valid, according to the qscc response endorsed by the peers
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload, commitView.ValidationEndorsements = createValidationResponse(t, getCommittedEnvelope(t, commitView), peer.TxValidationCode_MVCC_READ_CONFLICT, qsccChaincodeName, endorser)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Transaction in block 5 was not committed as valid: MVCC_READ_CONFLICT")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload = nil
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Fabric commit view does not contain the validation response for the transaction in block 5")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload, commitView.ValidationEndorsements = createValidationResponse(t, getCommittedEnvelope(t, commitView), peer.TxValidationCode_VALID, "mycc", endorser)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Validation response was returned by chaincode mycc instead of qscc")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload, commitView.ValidationEndorsements = createValidationResponse(t, &fabriccommon.Envelope{Payload: []byte("other")}, peer.TxValidationCode_VALID, qsccChaincodeName, endorser)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Validation response does not refer to the transaction at index 1 of block 5")
+	// The validation response must be signed by peers satisfying the verification policy
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload, commitView.ValidationEndorsements = createValidationResponse(t, getCommittedEnvelope(t, commitView), peer.TxValidationCode_VALID, qsccChaincodeName, orderer)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Validation response verification failed")
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	commitView.ValidationProposalResponsePayload.ProposalHash = []byte("tampered")
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Validation response verification failed: Unable to Validate Signature")
+
+	// Test: The transaction must be on the channel of the address
+	commitView = createFabricCommitView(t, "otherchannel", interopPayloadBytes, endorser, orderer)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Transaction tx1 was committed on channel otherchannel instead of channel mychannel")
+
+	// Test: The response must match the committed response
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer)
+	otherInteropPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: fabricViewAddress, Payload: []byte("Vega")})
+	require.NoError(t, err)
+	commitView.Response.Payload = otherInteropPayloadBytes
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.EqualError(t, err, "Response in fabric view does not match response in proposal response")
+
+	// Test: The endorsements must satisfy the verification policy
+	commitView = createFabricCommitView(t, "mychannel", interopPayloadBytes, orderer, orderer)
+	err = interopcc.VerifyView(ctx, encodeView(commitView), fabricViewAddress)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "do not satisfy verification policy")
+}
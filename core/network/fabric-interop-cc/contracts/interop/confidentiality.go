@@ -0,0 +1,235 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// confidentiality contains the functions used to encrypt view payloads to the requestor's public key
+// and to check decrypted payloads against the commitment in the view, as described in
+// rfcs/models/security/confidentiality.md
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+const (
+	eciesNonceSize = 12
+	// confidentialityCollection is the private data collection of the interop chaincode holding the secret from
+	// which the randomness of confidential payloads is derived. It must be shared by the orgs whose peers endorse
+	// HandleExternalRequest, and readable by their peers only.
+	confidentialityCollection         = "interopConfidentiality"
+	confidentialitySecretKey          = "confidentialitySecret"
+	confidentialitySecretTransientKey = "secret"
+	minConfidentialitySecretLength    = 32
+)
+
+// SetConfidentialitySecret cc stores the secret, passed in the transient map, from which the randomness used in
+// encrypting confidential payloads is derived. The secret must be set by an admin of a local org. Once governance
+// is enabled, it is set by the transaction that approves a proposal without arguments, and the secret must be
+// passed in the transient map of that transaction, since the arguments of proposals are recorded on the ledger.
+func (s *SmartContract) SetConfidentialitySecret(ctx contractapi.TransactionContextInterface) error {
+	err := verifyClientIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("Unable to get transient map: %s", err)
+	}
+	secret := transientMap[confidentialitySecretTransientKey]
+	if len(secret) < minConfidentialitySecretLength {
+		return fmt.Errorf("Confidentiality secret must be passed in the transient map under key %s and be at least %d bytes long", confidentialitySecretTransientKey, minConfidentialitySecretLength)
+	}
+	err = ctx.GetStub().PutPrivateData(confidentialityCollection, confidentialitySecretKey, secret)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "SetConfidentialitySecret", localSecurityDomain)
+}
+
+// deriveConfidentialitySeed returns the seed from which the randomness used in encrypting a payload is derived.
+//
+// Every endorser must produce the same ciphertext for the endorsements to match, so the randomness cannot be
+// generated locally. Instead it is derived from the signed proposal, so that it is unique to each query, keyed
+// with the secret in the confidentiality collection. The driver submitting the proposal cannot read the secret,
+// so it cannot recompute the randomness to decrypt the payload.
+func deriveConfidentialitySeed(ctx contractapi.TransactionContextInterface) ([]byte, error) {
+	secret, err := ctx.GetStub().GetPrivateData(confidentialityCollection, confidentialitySecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get confidentiality secret: %s", err)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("Confidentiality secret is not set in collection %s", confidentialityCollection)
+	}
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get signed proposal: %s", err)
+	}
+	if signedProposal == nil {
+		return nil, fmt.Errorf("Signed proposal is not set")
+	}
+	seed := hmac.New(sha256.New, secret)
+	seed.Write(signedProposal.ProposalBytes)
+	seed.Write(signedProposal.Signature)
+	return seed.Sum(nil), nil
+}
+
+// deriveBytes expands the seed into a value specific to the given label
+func deriveBytes(seed []byte, label string) []byte {
+	mac := hmac.New(sha256.New, seed)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// computeConfidentialPayloadHash computes the commitment to a payload
+func computeConfidentialPayloadHash(payload []byte, random []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// deriveECIESKey computes the symmetric key from the ECDH shared secret and the ephemeral public key
+func deriveECIESKey(curve elliptic.Curve, sharedX *big.Int, ephemeralPublicKey []byte) []byte {
+	sharedBytes := make([]byte, (curve.Params().BitSize+7)/8)
+	sharedX.FillBytes(sharedBytes)
+	key := sha256.New()
+	key.Write(sharedBytes)
+	key.Write(ephemeralPublicKey)
+	return key.Sum(nil)
+}
+
+// encryptECIES encrypts a message to an ECDSA public key using an ephemeral key derived from the seed.
+// The output is the uncompressed ephemeral public key, followed by the AES-GCM nonce and ciphertext.
+func encryptECIES(publicKey *ecdsa.PublicKey, message []byte, seed []byte) ([]byte, error) {
+	curve := publicKey.Curve
+	n := curve.Params().N
+	ephemeralScalar := new(big.Int).SetBytes(deriveBytes(seed, "ephemeral-key"))
+	ephemeralScalar.Mod(ephemeralScalar, new(big.Int).Sub(n, big.NewInt(1)))
+	ephemeralScalar.Add(ephemeralScalar, big.NewInt(1))
+	ephemeralX, ephemeralY := curve.ScalarBaseMult(ephemeralScalar.Bytes())
+	ephemeralPublicKey := elliptic.Marshal(curve, ephemeralX, ephemeralY)
+	sharedX, _ := curve.ScalarMult(publicKey.X, publicKey.Y, ephemeralScalar.Bytes())
+
+	block, err := aes.NewCipher(deriveECIESKey(curve, sharedX, ephemeralPublicKey))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := deriveBytes(seed, "nonce")[:eciesNonceSize]
+	ciphertext := gcm.Seal(nil, nonce, message, nil)
+	output := append(ephemeralPublicKey, nonce...)
+	return append(output, ciphertext...), nil
+}
+
+// generateConfidentialInteropPayload encrypts a response to the public key in the requestor's certificate
+// and returns the interop payload containing it along with the commitment to the response
+func generateConfidentialInteropPayload(ctx contractapi.TransactionContextInterface, payload []byte, address string, certificate string, x509Cert *x509.Certificate) (*common.InteropPayload, error) {
+	publicKey, ok := x509Cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("Confidential payloads are only supported for ECDSA certificates")
+	}
+	seed, err := deriveConfidentialitySeed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contents := common.ConfidentialPayloadContents{
+		Payload: payload,
+		Random:  deriveBytes(seed, "commitment-random"),
+	}
+	contentsBytes, err := protoV2.Marshal(&contents)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal confidential payload contents: %s", err)
+	}
+	encryptedPayload, err := encryptECIES(publicKey, contentsBytes, seed)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to encrypt payload: %s", err)
+	}
+	confidentialPayload := common.ConfidentialPayload{
+		EncryptedPayload: encryptedPayload,
+		HashType:         common.ConfidentialPayload_HMAC_SHA256,
+		Hash:             computeConfidentialPayloadHash(contents.Payload, contents.Random),
+	}
+	confidentialPayloadBytes, err := protoV2.Marshal(&confidentialPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal confidential payload: %s", err)
+	}
+	return &common.InteropPayload{
+		Payload:              confidentialPayloadBytes,
+		Address:              address,
+		Confidential:         true,
+		RequestorCertificate: certificate,
+	}, nil
+}
+
+// extractDecryptedContents returns the decrypted contents of a confidential payload after checking them
+// against the commitment in the payload
+func extractDecryptedContents(confidentialPayload *common.ConfidentialPayload) (*common.ConfidentialPayloadContents, error) {
+	if len(confidentialPayload.DecryptedPayload) == 0 {
+		return nil, fmt.Errorf("Confidential payload has not been decrypted")
+	}
+	var contents common.ConfidentialPayloadContents
+	err := protoV2.Unmarshal(confidentialPayload.DecryptedPayload, &contents)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal decrypted payload: %s", err)
+	}
+	if confidentialPayload.HashType != common.ConfidentialPayload_HMAC_SHA256 {
+		return nil, fmt.Errorf("Confidential payload hash type not supported: %s", confidentialPayload.HashType)
+	}
+	if !hmac.Equal(computeConfidentialPayloadHash(contents.Payload, contents.Random), confidentialPayload.Hash) {
+		return nil, fmt.Errorf("Decrypted payload does not match the hash in the confidential payload")
+	}
+	return &contents, nil
+}
+
+// verifyConfidentialInteropPayload checks that the interop payload in a view matches the one signed by the
+// source network, including the redaction applied to the response and the timestamp checked for freshness, except
+// for the decrypted contents which must match the signed commitment instead
+func verifyConfidentialInteropPayload(signedPayloadBytes []byte, viewPayload *common.InteropPayload) error {
+	var signedPayload common.InteropPayload
+	err := protoV2.Unmarshal(signedPayloadBytes, &signedPayload)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal signed interopPayload: %s", err.Error())
+	}
+	if !signedPayload.Confidential || signedPayload.Address != viewPayload.Address || signedPayload.RequestorCertificate != viewPayload.RequestorCertificate {
+		return fmt.Errorf("Interop payload in view does not match signed interop payload")
+	}
+	if signedPayload.Timestamp != viewPayload.Timestamp {
+		return fmt.Errorf("Timestamp in view does not match signed timestamp")
+	}
+	if !protoV2.Equal(signedPayload.Redaction, viewPayload.Redaction) {
+		return fmt.Errorf("Redaction in view does not match signed redaction")
+	}
+	var signedConfidentialPayload, viewConfidentialPayload common.ConfidentialPayload
+	err = protoV2.Unmarshal(signedPayload.Payload, &signedConfidentialPayload)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal signed confidential payload: %s", err.Error())
+	}
+	err = protoV2.Unmarshal(viewPayload.Payload, &viewConfidentialPayload)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal confidential payload: %s", err.Error())
+	}
+	decryptedPayload := viewConfidentialPayload.DecryptedPayload
+	viewConfidentialPayload.DecryptedPayload = nil
+	if !protoV2.Equal(&signedConfidentialPayload, &viewConfidentialPayload) {
+		return fmt.Errorf("Confidential payload in view does not match signed confidential payload")
+	}
+	signedConfidentialPayload.DecryptedPayload = decryptedPayload
+	_, err = extractDecryptedContents(&signedConfidentialPayload)
+	return err
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+var testConfidentialitySecret = []byte("0123456789abcdef0123456789abcdef")
+
+// decryptECIES reverses encryptECIES using the recipient's private key, as the requestor would
+func decryptECIES(privateKey *ecdsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	curve := privateKey.Curve
+	publicKeyLength := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(ciphertext) < publicKeyLength+eciesNonceSize {
+		return nil, fmt.Errorf("Ciphertext too short")
+	}
+	ephemeralPublicKey := ciphertext[:publicKeyLength]
+	ephemeralX, ephemeralY := elliptic.Unmarshal(curve, ephemeralPublicKey)
+	if ephemeralX == nil {
+		return nil, fmt.Errorf("Invalid ephemeral public key")
+	}
+	sharedX, _ := curve.ScalarMult(ephemeralX, ephemeralY, privateKey.D.Bytes())
+	block, err := aes.NewCipher(deriveECIESKey(curve, sharedX, ephemeralPublicKey))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[publicKeyLength : publicKeyLength+eciesNonceSize]
+	return gcm.Open(nil, nonce, ciphertext[publicKeyLength+eciesNonceSize:], nil)
+}
+
+func getConfidentialityTestTemplate() x509.Certificate {
+	now := time.Now()
+	return x509.Certificate{
+		Subject:      pkix.Name{CommonName: "example-a.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+}
+
+func TestGenerateConfidentialInteropPayload(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	address := "localhost:9080/network1/mychannel:interop:Read:a"
+	certDERBytes, key, err := createECDSACertAndKeyFromTemplate(getConfidentialityTestTemplate())
+	require.NoError(t, err)
+	x509Cert, err := x509.ParseCertificate(certDERBytes)
+	require.NoError(t, err)
+
+	// The confidentiality secret and the signed proposal are required to derive the randomness
+	_, err = generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.EqualError(t, err, "Confidentiality secret is not set in collection interopConfidentiality")
+	chaincodeStub.GetPrivateDataReturns(testConfidentialitySecret, nil)
+	_, err = generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.EqualError(t, err, "Signed proposal is not set")
+	collection, secretKey := chaincodeStub.GetPrivateDataArgsForCall(0)
+	require.Equal(t, confidentialityCollection, collection)
+	require.Equal(t, confidentialitySecretKey, secretKey)
+
+	chaincodeStub.GetSignedProposalReturns(&pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("signature")}, nil)
+	interopPayload, err := generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.NoError(t, err)
+	require.True(t, interopPayload.Confidential)
+	require.Equal(t, address, interopPayload.Address)
+	require.Equal(t, "cert", interopPayload.RequestorCertificate)
+
+	// Every endorser produces the same payload for the same proposal
+	otherPayload, err := generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.NoError(t, err)
+	require.True(t, protoV2.Equal(interopPayload, otherPayload))
+
+	// The requestor can decrypt the payload with its private key
+	var confidentialPayload common.ConfidentialPayload
+	require.NoError(t, protoV2.Unmarshal(interopPayload.Payload, &confidentialPayload))
+	require.NotContains(t, string(confidentialPayload.EncryptedPayload), "17.12")
+	decryptedPayload, err := decryptECIES(key, confidentialPayload.EncryptedPayload)
+	require.NoError(t, err)
+	confidentialPayload.DecryptedPayload = decryptedPayload
+	contents, err := extractDecryptedContents(&confidentialPayload)
+	require.NoError(t, err)
+	require.Equal(t, []byte("17.12"), contents.Payload)
+
+	// The driver knowing the signed proposal, but not the secret, cannot derive the ephemeral key to decrypt
+	// the payload, nor the randomness of the commitment
+	publicKey := x509Cert.PublicKey.(*ecdsa.PublicKey)
+	for _, guessedSeed := range [][]byte{
+		deriveTestSeed(nil, []byte("proposal"), []byte("signature")),
+		deriveTestSeed([]byte("guessed secret"), []byte("proposal"), []byte("signature")),
+		sha256Sum([]byte("proposalsignature")),
+	} {
+		_, err = decryptECIESWithSeed(publicKey, guessedSeed, confidentialPayload.EncryptedPayload)
+		require.Error(t, err)
+		require.NotEqual(t, deriveBytes(guessedSeed, "commitment-random"), contents.Random)
+	}
+	// whereas it can with the secret
+	plaintext, err := decryptECIESWithSeed(publicKey, deriveTestSeed(testConfidentialitySecret, []byte("proposal"), []byte("signature")), confidentialPayload.EncryptedPayload)
+	require.NoError(t, err)
+	require.Equal(t, decryptedPayload, plaintext)
+
+	// A different secret yields a different ciphertext for the same proposal
+	chaincodeStub.GetPrivateDataReturns([]byte("fedcba9876543210fedcba9876543210"), nil)
+	otherPayload, err = generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.NoError(t, err)
+	require.False(t, protoV2.Equal(interopPayload, otherPayload))
+	chaincodeStub.GetPrivateDataReturns(testConfidentialitySecret, nil)
+
+	// Only ECDSA certificates are supported
+	edCertBytes, _, err := createED25519CertAndKeyFromTemplate(getConfidentialityTestTemplate())
+	require.NoError(t, err)
+	edCert, err := x509.ParseCertificate(edCertBytes)
+	require.NoError(t, err)
+	_, err = generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", edCert)
+	require.EqualError(t, err, "Confidential payloads are only supported for ECDSA certificates")
+}
+
+// deriveTestSeed derives a seed from a signed proposal the way deriveConfidentialitySeed does, for a given secret
+func deriveTestSeed(secret []byte, proposalBytes []byte, signature []byte) []byte {
+	seed := hmac.New(sha256.New, secret)
+	seed.Write(proposalBytes)
+	seed.Write(signature)
+	return seed.Sum(nil)
+}
+
+func sha256Sum(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// decryptECIESWithSeed decrypts a payload encrypted by encryptECIES using the public key of the recipient and the
+// seed of the randomness, as anyone able to derive the seed could
+func decryptECIESWithSeed(publicKey *ecdsa.PublicKey, seed []byte, ciphertext []byte) ([]byte, error) {
+	curve := publicKey.Curve
+	n := curve.Params().N
+	ephemeralScalar := new(big.Int).SetBytes(deriveBytes(seed, "ephemeral-key"))
+	ephemeralScalar.Mod(ephemeralScalar, new(big.Int).Sub(n, big.NewInt(1)))
+	ephemeralScalar.Add(ephemeralScalar, big.NewInt(1))
+	ephemeralX, ephemeralY := curve.ScalarBaseMult(ephemeralScalar.Bytes())
+	ephemeralPublicKey := elliptic.Marshal(curve, ephemeralX, ephemeralY)
+	sharedX, _ := curve.ScalarMult(publicKey.X, publicKey.Y, ephemeralScalar.Bytes())
+	block, err := aes.NewCipher(deriveECIESKey(curve, sharedX, ephemeralPublicKey))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < len(ephemeralPublicKey)+eciesNonceSize {
+		return nil, fmt.Errorf("Ciphertext too short")
+	}
+	nonce := ciphertext[len(ephemeralPublicKey) : len(ephemeralPublicKey)+eciesNonceSize]
+	return gcm.Open(nil, nonce, ciphertext[len(ephemeralPublicKey)+eciesNonceSize:], nil)
+}
+
+func TestSetConfidentialitySecret(t *testing.T) {
+	ctx, chaincodeStub, _, setClient := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetTransientReturns(map[string][]byte{"secret": testConfidentialitySecret}, nil)
+
+	// Only admins can set the secret
+	setClient("Org1MSP", false)
+	err := interopcc.SetConfidentialitySecret(ctx)
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+	require.Equal(t, 0, chaincodeStub.PutPrivateDataCallCount())
+
+	// The secret must be long enough
+	setClient("Org1MSP", true)
+	chaincodeStub.GetTransientReturns(map[string][]byte{"secret": []byte("short")}, nil)
+	err = interopcc.SetConfidentialitySecret(ctx)
+	require.EqualError(t, err, "Confidentiality secret must be passed in the transient map under key secret and be at least 32 bytes long")
+	require.Equal(t, 0, chaincodeStub.PutPrivateDataCallCount())
+
+	// The secret is stored in the confidentiality collection only
+	chaincodeStub.GetTransientReturns(map[string][]byte{"secret": testConfidentialitySecret}, nil)
+	putStateCount := chaincodeStub.PutStateCallCount()
+	err = interopcc.SetConfidentialitySecret(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, chaincodeStub.PutPrivateDataCallCount())
+	collection, key, value := chaincodeStub.PutPrivateDataArgsForCall(0)
+	require.Equal(t, confidentialityCollection, collection)
+	require.Equal(t, confidentialitySecretKey, key)
+	require.Equal(t, testConfidentialitySecret, value)
+	for i := putStateCount; i < chaincodeStub.PutStateCallCount(); i++ {
+		_, stateValue := chaincodeStub.PutStateArgsForCall(i)
+		require.NotContains(t, string(stateValue), string(testConfidentialitySecret))
+	}
+}
+
+func TestVerifyConfidentialInteropPayload(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	chaincodeStub.GetPrivateDataReturns(testConfidentialitySecret, nil)
+	chaincodeStub.GetSignedProposalReturns(&pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("signature")}, nil)
+	address := "localhost:9080/network1/mychannel:interop:Read:a"
+	certDERBytes, key, err := createECDSACertAndKeyFromTemplate(getConfidentialityTestTemplate())
+	require.NoError(t, err)
+	x509Cert, err := x509.ParseCertificate(certDERBytes)
+	require.NoError(t, err)
+	signedPayload, err := generateConfidentialInteropPayload(ctx, []byte("17.12"), address, "cert", x509Cert)
+	require.NoError(t, err)
+	signedPayload.Timestamp = 1600000000
+	signedPayloadBytes, err := protoV2.Marshal(signedPayload)
+	require.NoError(t, err)
+
+	// getViewPayload builds the interop payload the requestor puts in the view after decryption
+	getViewPayload := func(decryptedPayload []byte) *common.InteropPayload {
+		var confidentialPayload common.ConfidentialPayload
+		require.NoError(t, protoV2.Unmarshal(signedPayload.Payload, &confidentialPayload))
+		confidentialPayload.DecryptedPayload = decryptedPayload
+		confidentialPayloadBytes, err := protoV2.Marshal(&confidentialPayload)
+		require.NoError(t, err)
+		viewPayload := protoV2.Clone(signedPayload).(*common.InteropPayload)
+		viewPayload.Payload = confidentialPayloadBytes
+		return viewPayload
+	}
+	var confidentialPayload common.ConfidentialPayload
+	require.NoError(t, protoV2.Unmarshal(signedPayload.Payload, &confidentialPayload))
+	decryptedPayload, err := decryptECIES(key, confidentialPayload.EncryptedPayload)
+	require.NoError(t, err)
+
+	// Happy case
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, getViewPayload(decryptedPayload))
+	require.NoError(t, err)
+
+	// Not decrypted
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, getViewPayload(nil))
+	require.EqualError(t, err, "Confidential payload has not been decrypted")
+
+	// Decrypted contents replaced by the requestor
+	var contents common.ConfidentialPayloadContents
+	require.NoError(t, protoV2.Unmarshal(decryptedPayload, &contents))
+	contents.Payload = []byte("99.99")
+	fakeContents, err := protoV2.Marshal(&contents)
+	require.NoError(t, err)
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, getViewPayload(fakeContents))
+	require.EqualError(t, err, "Decrypted payload does not match the hash in the confidential payload")
+
+	// Address in view differs from the signed one
+	viewPayload := getViewPayload(decryptedPayload)
+	viewPayload.Address = "localhost:9080/network1/mychannel:interop:Read:b"
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, viewPayload)
+	require.EqualError(t, err, "Interop payload in view does not match signed interop payload")
+
+	// Timestamp in view differs from the signed one
+	viewPayload = getViewPayload(decryptedPayload)
+	viewPayload.Timestamp = signedPayload.Timestamp + 3600
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, viewPayload)
+	require.EqualError(t, err, "Timestamp in view does not match signed timestamp")
+
+	// Redaction stripped from or changed in the view
+	redactedPayload := protoV2.Clone(signedPayload).(*common.InteropPayload)
+	redactedPayload.Redaction = &common.Redaction{ExcludePaths: []string{"price"}}
+	redactedPayloadBytes, err := protoV2.Marshal(redactedPayload)
+	require.NoError(t, err)
+	err = verifyConfidentialInteropPayload(redactedPayloadBytes, getViewPayload(decryptedPayload))
+	require.EqualError(t, err, "Redaction in view does not match signed redaction")
+	viewPayload = getViewPayload(decryptedPayload)
+	viewPayload.Redaction = &common.Redaction{ExcludePaths: []string{"owner"}}
+	err = verifyConfidentialInteropPayload(redactedPayloadBytes, viewPayload)
+	require.EqualError(t, err, "Redaction in view does not match signed redaction")
+	viewPayload.Redaction = &common.Redaction{ExcludePaths: []string{"price"}}
+	err = verifyConfidentialInteropPayload(redactedPayloadBytes, viewPayload)
+	require.NoError(t, err)
+
+	// Commitment in view differs from the signed one
+	viewPayload = getViewPayload(decryptedPayload)
+	var viewConfidentialPayload common.ConfidentialPayload
+	require.NoError(t, protoV2.Unmarshal(viewPayload.Payload, &viewConfidentialPayload))
+	viewConfidentialPayload.Hash = computeConfidentialPayloadHash(contents.Payload, contents.Random)
+	viewPayload.Payload, err = protoV2.Marshal(&viewConfidentialPayload)
+	require.NoError(t, err)
+	err = verifyConfidentialInteropPayload(signedPayloadBytes, viewPayload)
+	require.EqualError(t, err, "Confidential payload in view does not match signed confidential payload")
+}
@@ -50,3 +50,80 @@ func decodeAccessControlPolicy(jsonBytes []byte) (*common.AccessControlPolicy, e
 	}
 	return &decodeObj, nil
 }
+
+func decodeReplayProtectionConfig(jsonBytes []byte) (*ReplayProtectionConfig, error) {
+	var decodeObj ReplayProtectionConfig
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeMembershipConfig(jsonBytes []byte) (*MembershipConfig, error) {
+	var decodeObj MembershipConfig
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeGovernanceConfig(jsonBytes []byte) (*GovernanceConfig, error) {
+	var decodeObj GovernanceConfig
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeGovernanceProposal(jsonBytes []byte) (*GovernanceProposal, error) {
+	var decodeObj GovernanceProposal
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeApplicationChaincode(jsonBytes []byte) (*ApplicationChaincode, error) {
+	var decodeObj ApplicationChaincode
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeEventSubscription(jsonBytes []byte) (*EventSubscription, error) {
+	var decodeObj EventSubscription
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
+
+func decodeSubscribedEvent(jsonBytes []byte) (*SubscribedEvent, error) {
+	var decodeObj SubscribedEvent
+	dec := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&decodeObj)
+	if err != nil {
+		return nil, err
+	}
+	return &decodeObj, nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// ethereumUtils contains helper functions for dealing with the secp256k1 signatures and
+// addresses used by Besu (Ethereum) networks
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+const ethereumSignatureLength = 65
+
+// computeKeccak256Hash returns the keccak256 hash used by Ethereum (which predates the final SHA3 standard)
+func computeKeccak256Hash(msg []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(msg)
+	return hasher.Sum(nil)
+}
+
+// normalizeEthereumAddress lowercases a hex address and strips the optional '0x' prefix
+func normalizeEthereumAddress(address string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(address)), "0x")
+}
+
+// recoverEthereumAddress recovers the address of the account that produced a signature over the
+// keccak256 hash of a message. The signature is in the Ethereum [R || S || V] format, with V being
+// either the recovery ID (0 or 1) or the recovery ID + 27.
+func recoverEthereumAddress(message []byte, signature []byte) (string, error) {
+	if len(signature) != ethereumSignatureLength {
+		return "", fmt.Errorf("Invalid signature length: %d", len(signature))
+	}
+	recoveryID := signature[64]
+	if recoveryID >= 27 {
+		recoveryID -= 27
+	}
+	if recoveryID > 1 {
+		return "", fmt.Errorf("Invalid signature recovery ID: %d", signature[64])
+	}
+	// The decred library expects the compact format [V || R || S], with V = 27 + recovery ID for uncompressed keys
+	compactSignature := append([]byte{27 + recoveryID}, signature[:64]...)
+	publicKey, _, err := ecdsa.RecoverCompact(compactSignature, computeKeccak256Hash(message))
+	if err != nil {
+		return "", err
+	}
+	// The address is the last 20 bytes of the hash of the uncompressed public key, without its 0x04 prefix
+	publicKeyHash := computeKeccak256Hash(publicKey.SerializeUncompressed()[1:])
+	return hex.EncodeToString(publicKeyHash[12:]), nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/stretchr/testify/require"
+)
+
+// signEthereumMessage signs the keccak256 hash of a message and returns the signature in [R || S || V] format
+func signEthereumMessage(privateKey *secp256k1.PrivateKey, message []byte) []byte {
+	// The compact signature is [V || R || S] with V = 27 + recovery ID
+	compactSignature := ecdsa.SignCompact(privateKey, computeKeccak256Hash(message), false)
+	return append(compactSignature[1:], compactSignature[0]-27)
+}
+
+// getEthereumAddress returns the hex address for a private key
+func getEthereumAddress(privateKey *secp256k1.PrivateKey) string {
+	publicKeyHash := computeKeccak256Hash(privateKey.PubKey().SerializeUncompressed()[1:])
+	return "0x" + hex.EncodeToString(publicKeyHash[12:])
+}
+
+func TestComputeKeccak256Hash(t *testing.T) {
+	require.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(computeKeccak256Hash([]byte{})))
+}
+
+func TestRecoverEthereumAddress(t *testing.T) {
+	// Known key from the Besu developer quickstart
+	privateKeyBytes, err := hex.DecodeString("8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63")
+	require.NoError(t, err)
+	privateKey := secp256k1.PrivKeyFromBytes(privateKeyBytes)
+	require.Equal(t, "0xfe3b557e8fb62b89f4916b721be55ceb828dbd73", getEthereumAddress(privateKey))
+
+	message := []byte("message")
+	signature := signEthereumMessage(privateKey, message)
+	address, err := recoverEthereumAddress(message, signature)
+	require.NoError(t, err)
+	require.Equal(t, "fe3b557e8fb62b89f4916b721be55ceb828dbd73", address)
+
+	// V offset by 27, as produced by most Ethereum tooling
+	signature[64] += 27
+	address, err = recoverEthereumAddress(message, signature)
+	require.NoError(t, err)
+	require.Equal(t, "fe3b557e8fb62b89f4916b721be55ceb828dbd73", address)
+
+	// Signature over a different message recovers a different address
+	address, err = recoverEthereumAddress([]byte("other message"), signature)
+	if err == nil {
+		require.NotEqual(t, "fe3b557e8fb62b89f4916b721be55ceb828dbd73", address)
+	}
+
+	_, err = recoverEthereumAddress(message, signature[:64])
+	require.EqualError(t, err, "Invalid signature length: 64")
+	signature[64] = 5
+	_, err = recoverEthereumAddress(message, signature)
+	require.EqualError(t, err, "Invalid signature recovery ID: 5")
+}
+
+func TestNormalizeEthereumAddress(t *testing.T) {
+	require.Equal(t, "fe3b557e8fb62b89f4916b721be55ceb828dbd73", normalizeEthereumAddress("0xFE3B557E8Fb62b89F4916B721be55cEb828dBd73"))
+	require.Equal(t, "fe3b557e8fb62b89f4916b721be55ceb828dbd73", normalizeEthereumAddress(" fe3b557e8fb62b89f4916b721be55ceb828dbd73"))
+}
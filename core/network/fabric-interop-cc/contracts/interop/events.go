@@ -0,0 +1,445 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// events contains the chaincode functions through which remote networks subscribe to the events of application
+// chaincodes, and through which the events are published and delivered with proofs, as described in
+// rfcs/protocols/events/event-bus.md
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+const (
+	eventSubscriptionObjectType = "eventSubscription"
+	subscribedEventObjectType   = "subscribedEvent"
+	subscribeEventsEventName    = "SubscribeEvents"
+	// The relay that events are delivered to is signed after the timestamp of the subscription request
+	subscriptionSignedMessageSeparator = ";deliver:"
+)
+
+// EventSubscription is a subscription of a remote network to the events of an application chaincode.
+//
+// Subscriptions are keyed by the channel and ID of the application chaincode and the subscription ID, which is
+// the ID of the transaction that registered it. Address is the address of the subscription request, whose view
+// segment names the application chaincode and the event name pattern. Events are delivered as views for this
+// address, so subscribers verify them against the verification policy for it. DeliveryEndpoint is the relay of
+// the subscriber's network, which the subscriber signs along with the subscription request.
+type EventSubscription struct {
+	ID                    string `json:"id"`
+	Address               string `json:"address"`
+	AppChannel            string `json:"appChannel"`
+	AppChaincode          string `json:"appChaincode"`
+	EventNamePattern      string `json:"eventNamePattern"`
+	SubscriberNetwork     string `json:"subscriberNetwork"`
+	SubscriberOrg         string `json:"subscriberOrg"`
+	SubscriberCertificate string `json:"subscriberCertificate"`
+	DeliveryEndpoint      string `json:"deliveryEndpoint"`
+	CreatedAt             int64  `json:"createdAt"`
+}
+
+// SubscribedEvent is an event published by an application chaincode that is pending delivery for a subscription.
+//
+// Events are keyed by the subscription ID, the ID of the transaction that published them and the event name, and
+// are kept until the relay acknowledges their delivery, so that they are delivered at least once.
+type SubscribedEvent struct {
+	SubscriptionID   string `json:"subscriptionId"`
+	Address          string `json:"address"`
+	DeliveryEndpoint string `json:"deliveryEndpoint"`
+	EventName        string `json:"eventName"`
+	Payload          []byte `json:"payload"`
+	TxID             string `json:"txId"`
+	Timestamp        int64  `json:"timestamp"`
+}
+
+// SubscribedEventPage is a page of events pending delivery, along with the bookmark to fetch the next page
+type SubscribedEventPage struct {
+	Events   []*SubscribedEvent `json:"events"`
+	Bookmark string             `json:"bookmark"`
+}
+
+// SubscribeEvents cc registers the subscription of a remote network to the events of an application chaincode.
+// The address of the query has the view segment <channel>:<chaincode>:<event name pattern>, where the pattern
+// follows the syntax of address patterns, e.g., "Asset*". The subscription ID is returned.
+//
+// The query must carry a signed timestamp, and the requester signs the address, nonce and timestamp followed by
+// ";deliver:" and the requesting relay, to which the events are delivered.
+//
+// The flow coordinates the following:
+//  1. Checks the query as for HandleExternalRequest, i.e., its signature, the certificate of the requester, that
+//     the application chaincode is registered, that the access control policy for the requester permits reading
+//     the subscription address and that the query is not a replay
+//  2. Checks that the subscription names an event name pattern and the relay to deliver the events to, and is
+//     timestamped
+//  3. Records the subscription, and records the request in the audit log and emits an event
+func (s *SmartContract) SubscribeEvents(ctx contractapi.TransactionContextInterface, b64QueryBytes string) (string, error) {
+	// 1. Checks the query as for HandleExternalRequest
+	query, x509Cert, viewAddress, _, err := verifyExternalRequest(s, ctx, b64QueryBytes, accessPermissionRead, getSubscriptionSignedMessage)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Checks that the subscription names an event name pattern and the relay to deliver the events to
+	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", err
+	}
+	if viewAddress.Contract == localCCId {
+		errorMessage := "Events of the Interop Chaincode cannot be subscribed to"
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	if viewAddress.Collection != "" || len(viewAddress.Args) != 0 || !validPatternString(viewAddress.CCFunc) {
+		errorMessage := fmt.Sprintf("Address %s does not specify an event name pattern", query.Address)
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	if query.RequestingRelay == "" {
+		errorMessage := "Subscription does not specify the relay to deliver events to"
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	if query.Timestamp == 0 {
+		errorMessage := "Subscription does not contain a timestamp"
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+
+	// 3. Records the subscription
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return "", err
+	}
+	subscription := &EventSubscription{
+		ID:                    ctx.GetStub().GetTxID(),
+		Address:               query.Address,
+		AppChannel:            viewAddress.Channel,
+		AppChaincode:          viewAddress.Contract,
+		EventNamePattern:      viewAddress.CCFunc,
+		SubscriberNetwork:     query.RequestingNetwork,
+		SubscriberOrg:         query.RequestingOrg,
+		SubscriberCertificate: query.Certificate,
+		DeliveryEndpoint:      query.RequestingRelay,
+		CreatedAt:             txTimeSecs,
+	}
+	subscriptionKey, err := ctx.GetStub().CreateCompositeKey(eventSubscriptionObjectType, []string{subscription.AppChannel, subscription.AppChaincode, subscription.ID})
+	if err != nil {
+		return "", err
+	}
+	subscriptionBytes, err := json.Marshal(subscription)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().PutState(subscriptionKey, subscriptionBytes)
+	if err != nil {
+		return "", err
+	}
+	err = recordExternalRequest(ctx, subscribeEventsEventName, query, x509Cert)
+	if err != nil {
+		return "", err
+	}
+	return subscription.ID, nil
+}
+
+// PublishEvent cc is invoked by an application chaincode to publish an event to the remote networks subscribed to
+// it. The application chaincode is identified as the chaincode invoked by the transaction proposal, and must be
+// registered and enabled. The event is recorded for delivery to each subscription of the application chaincode
+// whose event name pattern matches the event name. Each subscription receives at most one event with a given name
+// per transaction.
+func (s *SmartContract) PublishEvent(ctx contractapi.TransactionContextInterface, eventName string, payload string) error {
+	if eventName == "" {
+		return fmt.Errorf("Event name must be specified")
+	}
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	channel := ctx.GetStub().GetChannelID()
+	applicationChaincode, err := getApplicationChaincode(ctx, channel, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+	if applicationChaincode == nil || !applicationChaincode.Enabled {
+		errorMessage := fmt.Sprintf("Events can only be published by registered application chaincodes, found %s on channel %s", callerChaincodeID, channel)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(eventSubscriptionObjectType, []string{channel, callerChaincodeID})
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return err
+		}
+		subscription, err := decodeEventSubscription(result.Value)
+		if err != nil {
+			return fmt.Errorf("Failed to unmarshal event subscription: %s", err.Error())
+		}
+		if !isPatternAndAddressMatch(subscription.EventNamePattern, eventName) {
+			continue
+		}
+		err = putSubscribedEvent(ctx, &SubscribedEvent{
+			SubscriptionID:   subscription.ID,
+			Address:          subscription.Address,
+			DeliveryEndpoint: subscription.DeliveryEndpoint,
+			EventName:        eventName,
+			Payload:          []byte(payload),
+			TxID:             ctx.GetStub().GetTxID(),
+			Timestamp:        txTimeSecs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubscribedEvent cc is used by the relay to fetch an event pending delivery. The event is returned in an
+// InteropPayload for the address of the subscription, so that the endorsed response forms a view that the
+// subscriber verifies with VerifyView.
+func (s *SmartContract) GetSubscribedEvent(ctx contractapi.TransactionContextInterface, subscriptionID string, txID string, eventName string) (string, error) {
+	err := verifyRelayClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	event, err := getSubscribedEvent(ctx, subscriptionID, txID, eventName)
+	if err != nil {
+		return "", err
+	}
+	eventPayloadBytes, err := protoV2.Marshal(&common.EventPayload{
+		SubscriptionId: event.SubscriptionID,
+		EventName:      event.EventName,
+		Payload:        event.Payload,
+		TxId:           event.TxID,
+		Timestamp:      uint64(event.Timestamp),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Unable to marshal event payload: %s", err)
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return "", err
+	}
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{
+		Address:   event.Address,
+		Payload:   eventPayloadBytes,
+		Timestamp: uint64(txTimeSecs),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Unable to marshal interop payload: %s", err)
+	}
+	return string(interopPayloadBytes), nil
+}
+
+// ListSubscribedEvents cc is used by the relay to list a page of the events pending delivery for a subscription.
+// An empty bookmark fetches the first page.
+func (s *SmartContract) ListSubscribedEvents(ctx contractapi.TransactionContextInterface, subscriptionID string, pageSize int32, bookmark string) (string, error) {
+	err := verifyRelayClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = validatePageSize(pageSize)
+	if err != nil {
+		return "", err
+	}
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(subscribedEventObjectType, []string{subscriptionID}, pageSize, bookmark)
+	if err != nil {
+		return "", err
+	}
+	defer iterator.Close()
+	page := SubscribedEventPage{Events: []*SubscribedEvent{}}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return "", err
+		}
+		event, err := decodeSubscribedEvent(result.Value)
+		if err != nil {
+			return "", fmt.Errorf("Failed to unmarshal subscribed event: %s", err.Error())
+		}
+		page.Events = append(page.Events, event)
+	}
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+	}
+	pageBytes, err := json.Marshal(&page)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(pageBytes), nil
+}
+
+// AcknowledgeSubscribedEvent cc is used by the relay to remove an event once it has been delivered
+func (s *SmartContract) AcknowledgeSubscribedEvent(ctx contractapi.TransactionContextInterface, subscriptionID string, txID string, eventName string) error {
+	err := verifyRelayClient(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = getSubscribedEvent(ctx, subscriptionID, txID, eventName)
+	if err != nil {
+		return err
+	}
+	eventKey, err := ctx.GetStub().CreateCompositeKey(subscribedEventObjectType, []string{subscriptionID, txID, eventName})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().DelState(eventKey)
+}
+
+// GetEventSubscription cc returns a subscription to the events of an application chaincode. The client must be
+// the relay or an admin of a local org.
+func (s *SmartContract) GetEventSubscription(ctx contractapi.TransactionContextInterface, appChannel string, appChaincode string, subscriptionID string) (string, error) {
+	err := verifyEventSubscriptionReader(ctx)
+	if err != nil {
+		return "", err
+	}
+	subscriptionBytes, err := getEventSubscriptionBytes(ctx, appChannel, appChaincode, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	return string(subscriptionBytes), nil
+}
+
+// ListEventSubscriptions cc returns a page of the event subscriptions, optionally restricted to those of the given
+// subscriber network. An empty bookmark fetches the first page. The client must be the relay or an admin of a local
+// org.
+func (s *SmartContract) ListEventSubscriptions(ctx contractapi.TransactionContextInterface, subscriberNetwork string, pageSize int32, bookmark string) (string, error) {
+	err := verifyEventSubscriptionReader(ctx)
+	if err != nil {
+		return "", err
+	}
+	return getConfigPage(ctx, eventSubscriptionObjectType, pageSize, bookmark, func(bytes []byte) (bool, error) {
+		if subscriberNetwork == "" {
+			return true, nil
+		}
+		subscription, err := decodeEventSubscription(bytes)
+		if err != nil {
+			return false, fmt.Errorf("Failed to unmarshal event subscription: %s", err.Error())
+		}
+		return subscription.SubscriberNetwork == subscriberNetwork, nil
+	})
+}
+
+// DeleteEventSubscription cc is used to cancel a subscription to the events of an application chaincode. Events
+// already published for the subscription remain available to the relay until acknowledged. The client must be an
+// admin of a local org.
+func (s *SmartContract) DeleteEventSubscription(ctx contractapi.TransactionContextInterface, appChannel string, appChaincode string, subscriptionID string) error {
+	err := verifyClientIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	subscriptionBytes, err := getEventSubscriptionBytes(ctx, appChannel, appChaincode, subscriptionID)
+	if err != nil {
+		return err
+	}
+	subscription, err := decodeEventSubscription(subscriptionBytes)
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal event subscription: %s", err.Error())
+	}
+	subscriptionKey, err := ctx.GetStub().CreateCompositeKey(eventSubscriptionObjectType, []string{appChannel, appChaincode, subscriptionID})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(subscriptionKey)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed to delete event subscription %s: %s", subscriptionID, err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	return recordConfigChange(ctx, "DeleteEventSubscription", subscription.SubscriberNetwork)
+}
+
+// verifyRelayClient ensures that events can only be fetched for delivery and acknowledged by the relay
+func verifyRelayClient(ctx contractapi.TransactionContextInterface) error {
+	isClientRelay, err := wutils.IsClientRelay(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	if !isClientRelay {
+		return fmt.Errorf("Illegal access by relay")
+	}
+	return nil
+}
+
+// verifyEventSubscriptionReader ensures that subscriptions, which name the subscribers and their relays, can only
+// be read by the relay and by admins of local orgs
+func verifyEventSubscriptionReader(ctx contractapi.TransactionContextInterface) error {
+	isClientRelay, err := wutils.IsClientRelay(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	if isClientRelay {
+		return nil
+	}
+	return verifyClientIsAdmin(ctx)
+}
+
+// getSubscriptionSignedMessage returns the message a requestor signs when subscribing to events, which binds the
+// relay that the events are delivered to
+func getSubscriptionSignedMessage(query *common.Query) string {
+	return getQuerySignedMessage(query) + subscriptionSignedMessageSeparator + query.RequestingRelay
+}
+
+func getEventSubscriptionBytes(ctx contractapi.TransactionContextInterface, appChannel string, appChaincode string, subscriptionID string) ([]byte, error) {
+	subscriptionKey, err := ctx.GetStub().CreateCompositeKey(eventSubscriptionObjectType, []string{appChannel, appChaincode, subscriptionID})
+	if err != nil {
+		return nil, err
+	}
+	subscriptionBytes, err := ctx.GetStub().GetState(subscriptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptionBytes == nil {
+		return nil, fmt.Errorf("Event subscription %s to application chaincode %s on channel %s does not exist", subscriptionID, appChaincode, appChannel)
+	}
+	return subscriptionBytes, nil
+}
+
+func getSubscribedEvent(ctx contractapi.TransactionContextInterface, subscriptionID string, txID string, eventName string) (*SubscribedEvent, error) {
+	eventKey, err := ctx.GetStub().CreateCompositeKey(subscribedEventObjectType, []string{subscriptionID, txID, eventName})
+	if err != nil {
+		return nil, err
+	}
+	eventBytes, err := ctx.GetStub().GetState(eventKey)
+	if err != nil {
+		return nil, err
+	}
+	if eventBytes == nil {
+		return nil, fmt.Errorf("Event %s published by transaction %s is not pending delivery for subscription %s", eventName, txID, subscriptionID)
+	}
+	event, err := decodeSubscribedEvent(eventBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal subscribed event: %s", err.Error())
+	}
+	return event, nil
+}
+
+func putSubscribedEvent(ctx contractapi.TransactionContextInterface, event *SubscribedEvent) error {
+	eventKey, err := ctx.GetStub().CreateCompositeKey(subscribedEventObjectType, []string{event.SubscriptionID, event.TxID, event.EventName})
+	if err != nil {
+		return err
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	return ctx.GetStub().PutState(eventKey, eventBytes)
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+func TestEventSubscriptions(t *testing.T) {
+	ctx, chaincodeStub, worldState, setClient := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	chaincodeStub.GetChannelIDReturns("mychannel")
+	chaincodeStub.DelStateCalls(func(key string) error {
+		delete(worldState, key)
+		return nil
+	})
+	chaincodeStub.GetStateByPartialCompositeKeyCalls(func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		iterator := &mocks.StateQueryIterator{}
+		prefix := objectType + ":" + strings.Join(attributes, ":") + ":"
+		i := 0
+		for key, value := range worldState {
+			if strings.HasPrefix(key, prefix) {
+				iterator.HasNextReturnsOnCall(i, true)
+				iterator.NextReturnsOnCall(i, &queryresult.KV{Key: key, Value: value}, nil)
+				i++
+			}
+		}
+		return iterator, nil
+	})
+
+	template := x509.Certificate{
+		Subject:      pkix.Name{CommonName: "example-a.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+	certDER, key, err := createECDSACertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	certPEM := encodePEM("CERTIFICATE", certDER)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: certPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	worldState["membership:network1"] = membershipBytes
+	acpBytes, err := json.Marshal(&common.AccessControlPolicy{
+		SecurityDomain: "network1",
+		Rules:          []*common.Rule{{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:assetcc:*", Read: true}},
+	})
+	require.NoError(t, err)
+	worldState["accessControl:network1"] = acpBytes
+	// createSubscription returns a subscription request with a unique nonce for an address, signed for the given
+	// relay and submitted by the requesting relay
+	nonceCount := 0
+	createSubscription := func(address string, timestamp uint64, signedRelay string, requestingRelay string) string {
+		nonceCount++
+		query := common.Query{
+			Address:           address,
+			RequestingRelay:   signedRelay,
+			RequestingNetwork: "network1",
+			RequestingOrg:     "Org1MSP",
+			Certificate:       certPEM,
+			Nonce:             fmt.Sprintf("nonce%d", nonceCount),
+			Timestamp:         timestamp,
+		}
+		hashed, err := computeSHA2Hash([]byte(getSubscriptionSignedMessage(&query)), key.PublicKey.Params().BitSize)
+		require.NoError(t, err)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, hashed)
+		require.NoError(t, err)
+		query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+		query.RequestingRelay = requestingRelay
+		queryBytes, err := protoV2.Marshal(&query)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(queryBytes)
+	}
+	createQuery := func(address string) string {
+		return createSubscription(address, uint64(time.Now().Unix()), "network1-relay", "network1-relay")
+	}
+	address := "localhost:9080/network1/mychannel:assetcc:Asset*"
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"mychannel","enabled":true}`)
+	require.NoError(t, err)
+
+	// Test: Happy case
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+	chaincodeStub.GetTxIDReturns("sub1")
+	subscriptionID, err := interopcc.SubscribeEvents(ctx, createQuery(address))
+	require.NoError(t, err)
+	require.Equal(t, "sub1", subscriptionID)
+	subscriptionJSON, err := interopcc.GetEventSubscription(ctx, "mychannel", "assetcc", "sub1")
+	require.NoError(t, err)
+	var subscription EventSubscription
+	require.NoError(t, json.Unmarshal([]byte(subscriptionJSON), &subscription))
+	require.Equal(t, address, subscription.Address)
+	require.Equal(t, "Asset*", subscription.EventNamePattern)
+	require.Equal(t, "network1", subscription.SubscriberNetwork)
+	require.Equal(t, "network1-relay", subscription.DeliveryEndpoint)
+	eventName, _ := chaincodeStub.SetEventArgsForCall(chaincodeStub.SetEventCallCount() - 1)
+	require.Equal(t, subscribeEventsEventName, eventName)
+
+	// Test: Subscriptions are signed for the relay that events are delivered to, and must be timestamped
+	_, err = interopcc.SubscribeEvents(ctx, createSubscription(address, uint64(time.Now().Unix()), "network1-relay", "attacker-relay"))
+	require.EqualError(t, err, "Invalid Signature: Signature Verification failed. ECDSA VERIFY")
+	_, err = interopcc.SubscribeEvents(ctx, createSubscription(address, 0, "network1-relay", "network1-relay"))
+	require.EqualError(t, err, "Subscription does not contain a timestamp")
+
+	// Test: Subscriptions must name an event name pattern and be permitted by the access control policy
+	_, err = interopcc.SubscribeEvents(ctx, createQuery("localhost:9080/network1/mychannel:assetcc:Asset*:a"))
+	require.EqualError(t, err, "Address localhost:9080/network1/mychannel:assetcc:Asset*:a does not specify an event name pattern")
+	_, err = interopcc.SubscribeEvents(ctx, createQuery("localhost:9080/network1/mychannel:othercc:Asset*"))
+	require.Error(t, err)
+
+	// Test: Events matching the pattern are recorded for delivery
+	wtest.SetMockStubCCId(chaincodeStub, "assetcc")
+	chaincodeStub.GetTxIDReturns("tx1")
+	require.NoError(t, interopcc.PublishEvent(ctx, "AssetCreated", "a01"))
+	require.NoError(t, interopcc.PublishEvent(ctx, "BondCreated", "b01"))
+	require.Contains(t, worldState, "subscribedEvent:sub1:tx1:AssetCreated")
+	require.NotContains(t, worldState, "subscribedEvent:sub1:tx1:BondCreated")
+
+	// Test: Only registered application chaincodes can publish events
+	wtest.SetMockStubCCId(chaincodeStub, "othercc")
+	err = interopcc.PublishEvent(ctx, "AssetCreated", "a01")
+	require.EqualError(t, err, "Events can only be published by registered application chaincodes, found othercc on channel mychannel")
+
+	// Test: The relay fetches the event as a view for the subscription address
+	interopResponse, err := interopcc.GetSubscribedEvent(ctx, "sub1", "tx1", "AssetCreated")
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	require.NoError(t, protoV2.Unmarshal([]byte(interopResponse), &interopPayload))
+	require.Equal(t, address, interopPayload.Address)
+	var eventPayload common.EventPayload
+	require.NoError(t, protoV2.Unmarshal(interopPayload.Payload, &eventPayload))
+	require.Equal(t, "sub1", eventPayload.SubscriptionId)
+	require.Equal(t, "AssetCreated", eventPayload.EventName)
+	require.Equal(t, []byte("a01"), eventPayload.Payload)
+	require.Equal(t, "tx1", eventPayload.TxId)
+
+	// Test: Delivered events are removed once acknowledged
+	require.NoError(t, interopcc.AcknowledgeSubscribedEvent(ctx, "sub1", "tx1", "AssetCreated"))
+	_, err = interopcc.GetSubscribedEvent(ctx, "sub1", "tx1", "AssetCreated")
+	require.EqualError(t, err, "Event AssetCreated published by transaction tx1 is not pending delivery for subscription sub1")
+
+	// Test: Only the relay can fetch events
+	chaincodeStub.GetCreatorReturns([]byte{}, nil)
+	_, err = interopcc.GetSubscribedEvent(ctx, "sub1", "tx1", "AssetCreated")
+	require.EqualError(t, err, "Illegal access by relay")
+
+	// Test: Only the relay and admins can read subscriptions, and only admins can delete them
+	setClient("Org1MSP", false)
+	_, err = interopcc.GetEventSubscription(ctx, "mychannel", "assetcc", "sub1")
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+	_, err = interopcc.ListEventSubscriptions(ctx, "", 10, "")
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+	err = interopcc.DeleteEventSubscription(ctx, "mychannel", "assetcc", "sub1")
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+	setClient("Org1MSP", true)
+	_, err = interopcc.GetEventSubscription(ctx, "mychannel", "assetcc", "sub1")
+	require.NoError(t, err)
+
+	// Test: Deleted subscriptions no longer receive events
+	require.NoError(t, interopcc.DeleteEventSubscription(ctx, "mychannel", "assetcc", "sub1"))
+	_, err = interopcc.GetEventSubscription(ctx, "mychannel", "assetcc", "sub1")
+	require.EqualError(t, err, "Event subscription sub1 to application chaincode assetcc on channel mychannel does not exist")
+	wtest.SetMockStubCCId(chaincodeStub, "assetcc")
+	chaincodeStub.GetTxIDReturns("tx2")
+	require.NoError(t, interopcc.PublishEvent(ctx, "AssetCreated", "a02"))
+	require.NotContains(t, worldState, "subscribedEvent:sub1:tx2:AssetCreated")
+}
+
+func TestListSubscribedEvents(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	prepConfigPage(t, chaincodeStub, &SubscribedEvent{SubscriptionID: "sub1", EventName: "AssetCreated", TxID: "tx1"})
+
+	// Test: Only the relay can list events
+	chaincodeStub.GetCreatorReturns([]byte{}, nil)
+	_, err := interopcc.ListSubscribedEvents(ctx, "sub1", 10, "")
+	require.EqualError(t, err, "Illegal access by relay")
+
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	pageJSON, err := interopcc.ListSubscribedEvents(ctx, "sub1", 10, "")
+	require.NoError(t, err)
+	var page SubscribedEventPage
+	require.NoError(t, json.Unmarshal([]byte(pageJSON), &page))
+	require.Equal(t, "bookmark1", page.Bookmark)
+	require.Len(t, page.Events, 1)
+	require.Equal(t, "AssetCreated", page.Events[0].EventName)
+	objectType, keys, pageSize, _ := chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	require.Equal(t, subscribedEventObjectType, objectType)
+	require.Equal(t, []string{"sub1"}, keys)
+	require.Equal(t, int32(10), pageSize)
+}
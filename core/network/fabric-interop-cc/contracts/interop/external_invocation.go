@@ -0,0 +1,165 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// externalinvocation contains the chaincode functions to process transaction invocations coming from remote networks,
+// as described in rfcs/protocols/contract-invocation/invocation.md
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	invocationReceiptObjectType       = "invocationReceipt"
+	handleExternalInvocationEventName = "HandleExternalInvocation"
+	invocationSignedMessageSuffix     = ";invoke"
+)
+
+// HandleExternalInvocation chaincode processes transaction invocations that come from external networks.
+// Unlike HandleExternalRequest, the application chaincode is expected to change the ledger state, so this
+// must be submitted as a transaction rather than evaluated as a query.
+//
+// The flow coordinates the following:
+// 1. Checks that the query is marked as an invocation, and the validity of query signature
+// 2. Checks that the certificate of the requester is valid according to the network's Membership
+// 3. Checks that the view address refers to a registered application chaincode, and that the access control
+//    policy for the requester and view address permits writes, including that the query is confidential if the
+//    rule permitting it requires so
+// 4. Checks that the query is not a replay of an earlier one
+// 5. Calls application chaincode, and redacts the response as specified by the access control rule
+// 6. Records a receipt of the invocation, which proves its commitment once the transaction is committed
+// 7. Encrypts the response to the requestor's public key if the query is confidential
+// 8. Records the invocation in the audit log and emits an event
+//
+// The requesting network gets the proof of commitment by querying GetInvocationReceipt on the interop chaincode
+// through HandleExternalRequest after the transaction is committed: the receipt only exists in the world state
+// if the invocation transaction was valid and committed. Receipts are looked up by the hash of the nonce signed
+// by the requestor, since the request ID is assigned by the relay.
+func (s *SmartContract) HandleExternalInvocation(ctx contractapi.TransactionContextInterface, b64QueryBytes string) (string, error) {
+	query, x509Cert, viewAddress, rule, err := verifyExternalRequest(s, ctx, b64QueryBytes, accessPermissionWrite, getQuerySignedMessage)
+	if err != nil {
+		return "", err
+	}
+	// 5. Calls application chaincode
+	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", err
+	}
+	if localCCId == viewAddress.Contract {
+		errorMessage := "Interop Chaincode functions can not be invoked by remote networks."
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	if viewAddress.Collection != "" {
+		errorMessage := "Private data collections can only be queried by remote networks."
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	arr := append([]string{viewAddress.CCFunc}, viewAddress.Args...)
+	pbResp := ctx.GetStub().InvokeChaincode(viewAddress.Contract, strArrToBytesArr(arr), viewAddress.Channel)
+	if pbResp.Status != shim.OK {
+		errorMessage := fmt.Sprintf("Application chaincode invoke error: %s", string(pbResp.GetMessage()))
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	redaction := getRuleRedaction(rule)
+	payload, err := redactJSONPayload(pbResp.Payload, redaction)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unable to redact response: %s", err)
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	// 6. Records a receipt of the invocation
+	err = putInvocationReceipt(ctx, query, payload)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unable to record invocation receipt: %s", err)
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	// 7. Encrypts the response to the requestor's public key if the query is confidential
+	interopPayloadBytes, err := generateInteropPayload(ctx, query, payload, redaction, x509Cert)
+	if err != nil {
+		return "", err
+	}
+	// 8. Records the invocation in the audit log
+	err = recordExternalRequest(ctx, handleExternalInvocationEventName, query, x509Cert)
+	if err != nil {
+		return "", err
+	}
+	return string(interopPayloadBytes), nil
+}
+
+// GetInvocationReceipt cc gets the receipt of a transaction invoked by a remote network, given the hex encoded
+// SHA-256 hash of the nonce of the invocation
+func (s *SmartContract) GetInvocationReceipt(ctx contractapi.TransactionContextInterface, requestingNetwork string, nonceHash string) (string, error) {
+	receiptKey, err := ctx.GetStub().CreateCompositeKey(invocationReceiptObjectType, []string{requestingNetwork, nonceHash})
+	if err != nil {
+		return "", err
+	}
+	bytes, err := ctx.GetStub().GetState(receiptKey)
+	if err != nil {
+		return "", err
+	}
+	if bytes == nil {
+		return "", fmt.Errorf("Invocation receipt with nonce hash: %s does not exist for network: %s", nonceHash, requestingNetwork)
+	}
+	return string(bytes), nil
+}
+
+// getNonceHash returns the hex encoded SHA-256 hash of a nonce, which, unlike the nonce, can be part of an address
+func getNonceHash(nonce string) string {
+	nonceHash := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(nonceHash[:])
+}
+
+// putInvocationReceipt records the receipt of an invocation by a remote network, keyed by the hash of its nonce
+func putInvocationReceipt(ctx contractapi.TransactionContextInterface, query *common.Query, response []byte) error {
+	if query.Nonce == "" {
+		return fmt.Errorf("Query does not contain a nonce")
+	}
+	nonceHash := getNonceHash(query.Nonce)
+	receiptKey, err := ctx.GetStub().CreateCompositeKey(invocationReceiptObjectType, []string{query.RequestingNetwork, nonceHash})
+	if err != nil {
+		return err
+	}
+	existingReceipt, err := ctx.GetStub().GetState(receiptKey)
+	if err != nil {
+		return err
+	}
+	if existingReceipt != nil {
+		return fmt.Errorf("Invocation receipt already exists with nonce hash: %s", nonceHash)
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	responseHash := sha256.Sum256(response)
+	receipt := common.InvocationReceipt{
+		RequestId:         getExternalRequestID(query),
+		RequestingNetwork: query.RequestingNetwork,
+		RequestingOrg:     query.RequestingOrg,
+		Address:           query.Address,
+		TxId:              ctx.GetStub().GetTxID(),
+		Timestamp:         uint64(txTimeSecs),
+		ResponseHash:      responseHash[:],
+		NonceHash:         nonceHash,
+	}
+	receiptBytes, err := json.Marshal(&receipt)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	return ctx.GetStub().PutState(receiptKey, receiptBytes)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+func TestHandleExternalInvocation(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+	chaincodeStub.GetChannelIDReturns("mychannel")
+	chaincodeStub.GetTxIDReturns("tx1")
+	chaincodeStub.InvokeChaincodeReturns(pb.Response{Status: shim.OK, Payload: []byte("transferred")})
+
+	template := x509.Certificate{
+		Subject:      pkix.Name{CommonName: "example-a.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+	certDER, key, err := createECDSACertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	certPEM := encodePEM("CERTIFICATE", certDER)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: certPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	worldState["membership:network1"] = membershipBytes
+	setRules := func(rules ...*common.Rule) {
+		acpBytes, err := json.Marshal(&common.AccessControlPolicy{SecurityDomain: "network1", Rules: rules})
+		require.NoError(t, err)
+		worldState["accessControl:network1"] = acpBytes
+	}
+	// createSignedQuery returns a signed query, or invocation, with a unique nonce for an address
+	nonceCount := 0
+	createSignedQuery := func(address string, invoke bool, timestamp uint64) string {
+		nonceCount++
+		query := common.Query{
+			Address:           address,
+			RequestingRelay:   "network1-relay",
+			RequestingNetwork: "network1",
+			RequestingOrg:     "Org1MSP",
+			Certificate:       certPEM,
+			Nonce:             fmt.Sprintf("nonce%d", nonceCount),
+			RequestId:         fmt.Sprintf("request%d", nonceCount),
+			Timestamp:         timestamp,
+			Invoke:            invoke,
+		}
+		hashed, err := computeSHA2Hash([]byte(getQuerySignedMessage(&query)), key.PublicKey.Params().BitSize)
+		require.NoError(t, err)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, hashed)
+		require.NoError(t, err)
+		query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+		queryBytes, err := protoV2.Marshal(&query)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(queryBytes)
+	}
+	createQuery := func(address string) string {
+		return createSignedQuery(address, false, uint64(time.Now().Unix()))
+	}
+	createInvocation := func(address string) string {
+		return createSignedQuery(address, true, uint64(time.Now().Unix()))
+	}
+	address := "localhost:9080/network1/mychannel:assetcc:Transfer:a:b"
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"assetcc","channel":"mychannel","enabled":true}`)
+	require.NoError(t, err)
+
+	// Test: Rules that only permit queries do not permit invocations
+	setRules(&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:assetcc:Transfer:*", Read: true})
+	_, err = interopcc.HandleExternalInvocation(ctx, createInvocation(address))
+	require.EqualError(t, err, fmt.Sprintf("CC Access Denied: Access Control Policy DOES NOT PERMIT the request 'mychannel:assetcc:Transfer:a:b' from 'network1:%s'", certPEM))
+
+	// Test: Invocations and queries cannot be submitted through the function handling the other
+	_, err = interopcc.HandleExternalRequest(ctx, createInvocation(address))
+	require.EqualError(t, err, "Invocations must be submitted through HandleExternalInvocation")
+	_, err = interopcc.HandleExternalInvocation(ctx, createQuery(address))
+	require.EqualError(t, err, "Queries must be submitted through HandleExternalRequest")
+	_, err = interopcc.HandleExternalInvocation(ctx, createSignedQuery(address, true, 0))
+	require.EqualError(t, err, "Invocation does not contain a timestamp")
+
+	// Test: Happy case
+	setRules(
+		&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:assetcc:Transfer:*", Write: true},
+		&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:interopcc:GetInvocationReceipt:*", Read: true},
+	)
+	interopResponse, err := interopcc.HandleExternalInvocation(ctx, createInvocation(address))
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	require.NoError(t, protoV2.Unmarshal([]byte(interopResponse), &interopPayload))
+	require.Equal(t, []byte("transferred"), interopPayload.Payload)
+	require.Equal(t, address, interopPayload.Address)
+	contract, args, channel := chaincodeStub.InvokeChaincodeArgsForCall(chaincodeStub.InvokeChaincodeCallCount() - 1)
+	require.Equal(t, "assetcc", contract)
+	require.Equal(t, [][]byte{[]byte("Transfer"), []byte("a"), []byte("b")}, args)
+	require.Equal(t, "mychannel", channel)
+	eventName, _ := chaincodeStub.SetEventArgsForCall(chaincodeStub.SetEventCallCount() - 1)
+	require.Equal(t, handleExternalInvocationEventName, eventName)
+
+	nonceHash := getNonceHash(fmt.Sprintf("nonce%d", nonceCount))
+	receiptJSON, err := interopcc.GetInvocationReceipt(ctx, "network1", nonceHash)
+	require.NoError(t, err)
+	var receipt common.InvocationReceipt
+	require.NoError(t, json.Unmarshal([]byte(receiptJSON), &receipt))
+	responseHash := sha256.Sum256([]byte("transferred"))
+	require.Equal(t, "tx1", receipt.TxId)
+	require.Equal(t, address, receipt.Address)
+	require.Equal(t, responseHash[:], receipt.ResponseHash)
+	require.Equal(t, nonceHash, receipt.NonceHash)
+
+	// Test: Write-only rules do not permit queries
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery(address))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "DOES NOT PERMIT")
+
+	// Test: The requesting network fetches the receipt of its invocation as a view
+	interopResponse, err = interopcc.HandleExternalRequest(ctx, createQuery("localhost:9080/network1/mychannel:interopcc:GetInvocationReceipt:network1:" + nonceHash))
+	require.NoError(t, err)
+	require.NoError(t, protoV2.Unmarshal([]byte(interopResponse), &interopPayload))
+	require.Equal(t, receiptJSON, string(interopPayload.Payload))
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery("localhost:9080/network1/mychannel:interopcc:GetInvocationReceipt:network2:" + nonceHash))
+	require.EqualError(t, err, "Network network1 cannot fetch receipts of invocations by network network2")
+
+	// Test: Interop chaincode functions cannot be invoked
+	setRules(&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:interopcc:*", Write: true})
+	_, err = interopcc.HandleExternalInvocation(ctx, createInvocation("localhost:9080/network1/mychannel:interopcc:DeleteMembership:network1"))
+	require.EqualError(t, err, "Interop Chaincode functions can not be invoked by remote networks.")
+
+	// Test: Unknown receipt
+	_, err = interopcc.GetInvocationReceipt(ctx, "network1", "unknown")
+	require.EqualError(t, err, "Invocation receipt with nonce hash: unknown does not exist for network: network1")
+}
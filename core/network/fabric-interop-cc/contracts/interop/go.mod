@@ -3,8 +3,10 @@ module github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabr
 go 1.16
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
 	github.com/golang/protobuf v1.5.2
 	github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go v1.2.4
+	github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern v1.0.0
 	github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/assetexchange v1.2.4
 	github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils v0.0.0-20211117075003-d4cef34c8832
 	github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils v1.2.5
@@ -0,0 +1,507 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// governance contains the proposal and approval workflow through which the local orgs sharing the interop
+// chaincode jointly manage memberships, access control policies, verification policies and other configuration
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	governanceConfigKey                = "governanceConfig"
+	governanceProposalObjectType       = "governanceProposal"
+	governanceAdminOU                  = "admin"
+	governanceProposalStatusPending    = "PENDING"
+	governanceProposalStatusApplied    = "APPLIED"
+	governanceProposalStatusRejected   = "REJECTED"
+	setGovernanceConfigFunctionName    = "SetGovernanceConfig"
+	governanceFunctionNamespaceDivider = ":"
+)
+
+// GovernanceConfig lists the local orgs (by MSP ID) that take part in governance, and the number of
+// them that must approve a proposal before it is applied
+type GovernanceConfig struct {
+	Orgs      []string `json:"orgs"`
+	Threshold int      `json:"threshold"`
+}
+
+// GovernanceVote records the approval or rejection of a proposal by an org
+type GovernanceVote struct {
+	Org       string `json:"org"`
+	Approve   bool   `json:"approve"`
+	TxID      string `json:"txId"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// GovernanceProposal is a proposed invocation of a governed chaincode function, along with the votes cast on it.
+// Proposals are never deleted, so they make up the history of configuration changes.
+type GovernanceProposal struct {
+	ProposalID string           `json:"proposalId"`
+	Function   string           `json:"function"`
+	Args       []string         `json:"args"`
+	Proposer   string           `json:"proposer"`
+	Status     string           `json:"status"`
+	Votes      []GovernanceVote `json:"votes"`
+	CreatedAt  int64            `json:"createdAt"`
+	ClosedAt   int64            `json:"closedAt"`
+}
+
+type governedFunction struct {
+	numArgs int
+	apply   func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error
+}
+
+// governedFunctions are the chaincode functions that can only be applied through an approved proposal once
+// governance is enabled
+var governedFunctions = map[string]governedFunction{
+	"CreateMembership": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.CreateMembership(ctx, args[0])
+	}},
+	"UpdateMembership": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateMembership(ctx, args[0])
+	}},
+	"DeleteMembership": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.DeleteMembership(ctx, args[0])
+	}},
+	"CreateLocalMembership": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.CreateLocalMembership(ctx, args[0])
+	}},
+	"UpdateLocalMembership": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateLocalMembership(ctx, args[0])
+	}},
+	"PublishMemberCRL": {3, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.PublishMemberCRL(ctx, args[0], args[1], args[2])
+	}},
+	"UpdateMemberRevokedSerials": {3, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateMemberRevokedSerials(ctx, args[0], args[1], args[2])
+	}},
+	"SetMembershipConfig": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.SetMembershipConfig(ctx, args[0])
+	}},
+	"CreateAccessControlPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.CreateAccessControlPolicy(ctx, args[0])
+	}},
+	"UpdateAccessControlPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateAccessControlPolicy(ctx, args[0])
+	}},
+	"DeleteAccessControlPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.DeleteAccessControlPolicy(ctx, args[0])
+	}},
+	"CreateVerificationPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.CreateVerificationPolicy(ctx, args[0])
+	}},
+	"UpdateVerificationPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateVerificationPolicy(ctx, args[0])
+	}},
+	"DeleteVerificationPolicy": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.DeleteVerificationPolicy(ctx, args[0])
+	}},
+	"CreateApplicationChaincode": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.CreateApplicationChaincode(ctx, args[0])
+	}},
+	"UpdateApplicationChaincode": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.UpdateApplicationChaincode(ctx, args[0])
+	}},
+	"DeleteApplicationChaincode": {2, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.DeleteApplicationChaincode(ctx, args[0], args[1])
+	}},
+	"SetConfidentialitySecret": {0, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.SetConfidentialitySecret(ctx)
+	}},
+	"SetReplayProtectionConfig": {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.SetReplayProtectionConfig(ctx, args[0])
+	}},
+	"DeleteEventSubscription": {3, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return s.DeleteEventSubscription(ctx, args[0], args[1], args[2])
+	}},
+	setGovernanceConfigFunctionName: {1, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) error {
+		return setGovernanceConfig(ctx, args[0])
+	}},
+}
+
+// checkGovernedTransaction is run before every transaction. Once governance is enabled it rejects direct
+// invocations of governed functions, which must then be applied through ApproveGovernanceProposal.
+func checkGovernedTransaction(ctx contractapi.TransactionContextInterface) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	// Functions may be prefixed by the contract namespace
+	if index := strings.LastIndex(function, governanceFunctionNamespaceDivider); index >= 0 {
+		function = function[index+1:]
+	}
+	if _, governed := governedFunctions[function]; !governed {
+		return nil
+	}
+	config, err := getGovernanceConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		errorMessage := fmt.Sprintf("Function %s can only be invoked through an approved governance proposal", function)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	return nil
+}
+
+// initTransactionStub marks the stub of a chaincode initialization transaction. An initialization transaction is
+// only accepted once for each chaincode definition, which the channel orgs approve through the chaincode lifecycle,
+// so it is the only transaction that can bootstrap governance.
+type initTransactionStub struct {
+	shim.ChaincodeStubInterface
+}
+
+// interopChaincode passes the stubs of initialization transactions to the contract as initTransactionStubs
+type interopChaincode struct {
+	*contractapi.ContractChaincode
+}
+
+// Init runs the function of an initialization transaction
+func (cc *interopChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return cc.ContractChaincode.Init(&initTransactionStub{stub})
+}
+
+// bootstrapGovernance enables governance with the config passed to InitLedger. This is only allowed in an
+// initialization transaction and while governance is not enabled; once enabled, the config can only be changed
+// through an approved proposal.
+func bootstrapGovernance(ctx contractapi.TransactionContextInterface, configJSON string) error {
+	if _, ok := ctx.GetStub().(*initTransactionStub); !ok {
+		return fmt.Errorf("Governance can only be enabled when initializing the chaincode")
+	}
+	config, err := getGovernanceConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		return fmt.Errorf("Governance is already enabled and its config can only be changed through an approved governance proposal")
+	}
+	return setGovernanceConfig(ctx, configJSON)
+}
+
+func setGovernanceConfig(ctx contractapi.TransactionContextInterface, configJSON string) error {
+	config, err := decodeGovernanceConfig([]byte(configJSON))
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	err = validateGovernanceConfig(config)
+	if err != nil {
+		return err
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().PutState(governanceConfigKey, configBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "SetGovernanceConfig", localSecurityDomain)
+}
+
+// GetGovernanceConfig cc returns the governance config, or an error if governance has not been enabled
+func (s *SmartContract) GetGovernanceConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	bytes, err := ctx.GetStub().GetState(governanceConfigKey)
+	if err != nil {
+		return "", err
+	}
+	if bytes == nil {
+		return "", fmt.Errorf("Governance has not been enabled")
+	}
+	return string(bytes), nil
+}
+
+func validateGovernanceConfig(config *GovernanceConfig) error {
+	if len(config.Orgs) == 0 {
+		return fmt.Errorf("Governance config must contain at least one org")
+	}
+	orgs := map[string]bool{}
+	for _, org := range config.Orgs {
+		if org == "" {
+			return fmt.Errorf("Governance config contains an empty org")
+		}
+		if orgs[org] {
+			return fmt.Errorf("Governance config contains duplicate org: %s", org)
+		}
+		orgs[org] = true
+	}
+	if config.Threshold < 1 || config.Threshold > len(config.Orgs) {
+		return fmt.Errorf("Governance threshold must be between 1 and %d, found %d", len(config.Orgs), config.Threshold)
+	}
+	return nil
+}
+
+// getGovernanceConfig returns the governance config, or nil if governance has not been enabled
+func getGovernanceConfig(ctx contractapi.TransactionContextInterface) (*GovernanceConfig, error) {
+	bytes, err := ctx.GetStub().GetState(governanceConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if bytes == nil {
+		return nil, nil
+	}
+	config, err := decodeGovernanceConfig(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal error: %s", err)
+	}
+	return config, nil
+}
+
+// getGovernanceVoter checks that the client is an admin of one of the orgs taking part in governance
+// and returns the MSP ID of that org
+func getGovernanceVoter(ctx contractapi.TransactionContextInterface, config *GovernanceConfig) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("Unable to get client MSP ID: %s", err)
+	}
+	isMember := false
+	for _, org := range config.Orgs {
+		if org == mspID {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return "", fmt.Errorf("Org %s does not take part in governance", mspID)
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", fmt.Errorf("Unable to get client certificate: %s", err)
+	}
+	if cert != nil && hasCertificateOU(cert, governanceAdminOU) {
+		return mspID, nil
+	}
+	return "", fmt.Errorf("Client is not an admin of org %s", mspID)
+}
+
+// verifyClientIsAdmin checks that the client is an admin of its org
+func verifyClientIsAdmin(ctx contractapi.TransactionContextInterface) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("Unable to get client MSP ID: %s", err)
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return fmt.Errorf("Unable to get client certificate: %s", err)
+	}
+	if cert == nil || !hasCertificateOU(cert, governanceAdminOU) {
+		return fmt.Errorf("Client is not an admin of org %s", mspID)
+	}
+	return nil
+}
+
+func getGovernanceProposalKey(ctx contractapi.TransactionContextInterface, proposalID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(governanceProposalObjectType, []string{proposalID})
+}
+
+func getGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*GovernanceProposal, error) {
+	proposalKey, err := getGovernanceProposalKey(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := ctx.GetStub().GetState(proposalKey)
+	if err != nil {
+		return nil, err
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("Governance proposal with id: %s does not exist", proposalID)
+	}
+	proposal, err := decodeGovernanceProposal(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal error: %s", err)
+	}
+	return proposal, nil
+}
+
+func putGovernanceProposal(ctx contractapi.TransactionContextInterface, proposal *GovernanceProposal) error {
+	proposalKey, err := getGovernanceProposalKey(ctx, proposal.ProposalID)
+	if err != nil {
+		return err
+	}
+	proposalBytes, err := json.Marshal(proposal)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	return ctx.GetStub().PutState(proposalKey, proposalBytes)
+}
+
+// ProposeGovernanceChange cc records a proposal to invoke a governed function with the provided arguments
+// (a JSON array of strings), counting as an approval by the proposer's org. The proposal ID, which is the
+// transaction ID, is returned. The change is applied right away if the threshold is already met.
+func (s *SmartContract) ProposeGovernanceChange(ctx contractapi.TransactionContextInterface, function string, argsJSON string) (string, error) {
+	config, err := getGovernanceConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "", fmt.Errorf("Governance has not been enabled")
+	}
+	voter, err := getGovernanceVoter(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	governed, ok := governedFunctions[function]
+	if !ok {
+		return "", fmt.Errorf("Function %s is not subject to governance", function)
+	}
+	var args []string
+	err = json.Unmarshal([]byte(argsJSON), &args)
+	if err != nil {
+		return "", fmt.Errorf("Unmarshal error: %s", err)
+	}
+	if len(args) != governed.numArgs {
+		return "", fmt.Errorf("Incorrect number of arguments for %s. Expecting %d, found %d", function, governed.numArgs, len(args))
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return "", err
+	}
+	proposal := &GovernanceProposal{
+		ProposalID: ctx.GetStub().GetTxID(),
+		Function:   function,
+		Args:       args,
+		Proposer:   voter,
+		Status:     governanceProposalStatusPending,
+		CreatedAt:  txTimeSecs,
+	}
+	err = s.voteOnGovernanceProposal(ctx, config, proposal, voter, true, txTimeSecs)
+	if err != nil {
+		return "", err
+	}
+	return proposal.ProposalID, nil
+}
+
+// ApproveGovernanceProposal cc records the approval of a pending proposal by the client's org, and applies
+// the proposed change once the number of approvals meets the threshold
+func (s *SmartContract) ApproveGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	return s.castGovernanceVote(ctx, proposalID, true)
+}
+
+// RejectGovernanceProposal cc records the rejection of a pending proposal by the client's org. The proposal
+// is closed once the threshold can no longer be met.
+func (s *SmartContract) RejectGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	return s.castGovernanceVote(ctx, proposalID, false)
+}
+
+func (s *SmartContract) castGovernanceVote(ctx contractapi.TransactionContextInterface, proposalID string, approve bool) error {
+	config, err := getGovernanceConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("Governance has not been enabled")
+	}
+	voter, err := getGovernanceVoter(ctx, config)
+	if err != nil {
+		return err
+	}
+	proposal, err := getGovernanceProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != governanceProposalStatusPending {
+		return fmt.Errorf("Governance proposal %s is %s", proposalID, proposal.Status)
+	}
+	for _, vote := range proposal.Votes {
+		if vote.Org == voter {
+			return fmt.Errorf("Org %s has already voted on governance proposal %s", voter, proposalID)
+		}
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	return s.voteOnGovernanceProposal(ctx, config, proposal, voter, approve, txTimeSecs)
+}
+
+// voteOnGovernanceProposal adds a vote to a proposal, applies or closes the proposal if the vote decides it,
+// and records the proposal in the ledger. Only votes from orgs in the current config are counted.
+func (s *SmartContract) voteOnGovernanceProposal(ctx contractapi.TransactionContextInterface, config *GovernanceConfig, proposal *GovernanceProposal, voter string, approve bool, txTimeSecs int64) error {
+	proposal.Votes = append(proposal.Votes, GovernanceVote{
+		Org:       voter,
+		Approve:   approve,
+		TxID:      ctx.GetStub().GetTxID(),
+		Timestamp: txTimeSecs,
+	})
+	orgs := map[string]bool{}
+	for _, org := range config.Orgs {
+		orgs[org] = true
+	}
+	approvals, rejections := 0, 0
+	for _, vote := range proposal.Votes {
+		if !orgs[vote.Org] {
+			continue
+		}
+		if vote.Approve {
+			approvals++
+		} else {
+			rejections++
+		}
+	}
+
+	if approvals >= config.Threshold {
+		err := governedFunctions[proposal.Function].apply(s, ctx, proposal.Args)
+		if err != nil {
+			errorMessage := fmt.Sprintf("Unable to apply governance proposal %s: %s", proposal.ProposalID, err.Error())
+			log.Error(errorMessage)
+			return errors.New(errorMessage)
+		}
+		proposal.Status = governanceProposalStatusApplied
+		proposal.ClosedAt = txTimeSecs
+		log.Infof("Governance proposal %s applied %s", proposal.ProposalID, proposal.Function)
+	} else if rejections > len(config.Orgs)-config.Threshold {
+		proposal.Status = governanceProposalStatusRejected
+		proposal.ClosedAt = txTimeSecs
+		log.Infof("Governance proposal %s rejected", proposal.ProposalID)
+	}
+	return putGovernanceProposal(ctx, proposal)
+}
+
+// GetGovernanceProposal cc gets a proposal, including the votes cast on it
+func (s *SmartContract) GetGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) (string, error) {
+	proposal, err := getGovernanceProposal(ctx, proposalID)
+	if err != nil {
+		return "", err
+	}
+	proposalBytes, err := json.Marshal(proposal)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(proposalBytes), nil
+}
+
+// GetAllGovernanceProposals cc gets the history of all proposals recorded in the ledger as a JSON array
+func (s *SmartContract) GetAllGovernanceProposals(ctx contractapi.TransactionContextInterface) (string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(governanceProposalObjectType, []string{})
+	if err != nil {
+		return "", err
+	}
+	defer iterator.Close()
+	proposals := []*GovernanceProposal{}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return "", err
+		}
+		proposal, err := decodeGovernanceProposal(result.Value)
+		if err != nil {
+			return "", fmt.Errorf("Unmarshal error: %s", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+	proposalsBytes, err := json.Marshal(proposals)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(proposalsBytes), nil
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+)
+
+const governanceConfigJSON = `{"orgs": ["Org1MSP", "Org2MSP", "Org3MSP"], "threshold": 2}`
+
+// prepGovernanceMockStub returns a stub backed by an in-memory world state, and a function to switch
+// the client to an admin (or plain member) of an org
+func prepGovernanceMockStub() (*mocks.TransactionContext, *mocks.ChaincodeStub, map[string][]byte, func(mspID string, admin bool)) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	worldState := map[string][]byte{}
+	chaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		return worldState[key], nil
+	})
+	chaincodeStub.PutStateCalls(func(key string, value []byte) error {
+		worldState[key] = value
+		return nil
+	})
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+	chaincodeStub.GetTxTimestampReturns(ptypes.TimestampNow(), nil)
+
+	setClient := func(mspID string, admin bool) {
+		setMockClient(ctx, mspID, admin)
+	}
+	return ctx, chaincodeStub, worldState, setClient
+}
+
+// setMockClient sets the client of the transaction to a member, or an admin, of the given org
+func setMockClient(ctx *mocks.TransactionContext, mspID string, admin bool) {
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetMSPIDReturns(mspID, nil)
+	ou := "client"
+	if admin {
+		ou = "admin"
+	}
+	clientIdentity.GetX509CertificateReturns(&x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{ou}}}, nil)
+	ctx.GetClientIdentityReturns(clientIdentity)
+}
+
+// initGovernance enables governance as InitLedger does in a chaincode initialization transaction
+func initGovernance(ctx *mocks.TransactionContext, configJSON string) error {
+	stub := ctx.GetStub()
+	ctx.GetStubReturns(&initTransactionStub{stub})
+	defer ctx.GetStubReturns(stub)
+	return bootstrapGovernance(ctx, configJSON)
+}
+
+func TestBootstrapGovernance(t *testing.T) {
+	ctx, _, worldState, _ := prepGovernanceMockStub()
+
+	// Test: Governance cannot be enabled outside of an initialization transaction
+	err := bootstrapGovernance(ctx, governanceConfigJSON)
+	require.EqualError(t, err, "Governance can only be enabled when initializing the chaincode")
+
+	// Test: Invalid configs
+	err = initGovernance(ctx, `{"orgs": ["Org1MSP", "Org2MSP"], "threshold": 3}`)
+	require.EqualError(t, err, "Governance threshold must be between 1 and 2, found 3")
+	err = initGovernance(ctx, `{"orgs": ["Org1MSP", "Org1MSP"], "threshold": 1}`)
+	require.EqualError(t, err, "Governance config contains duplicate org: Org1MSP")
+	interopcc := SmartContract{}
+	_, err = interopcc.GetGovernanceConfig(ctx)
+	require.EqualError(t, err, "Governance has not been enabled")
+
+	// Test: Happy case
+	err = initGovernance(ctx, governanceConfigJSON)
+	require.NoError(t, err)
+	config, err := decodeGovernanceConfig(worldState[governanceConfigKey])
+	require.NoError(t, err)
+	require.Equal(t, 2, config.Threshold)
+	_, err = interopcc.GetGovernanceConfig(ctx)
+	require.NoError(t, err)
+
+	// Test: Reinitializing the chaincode cannot replace the config
+	err = initGovernance(ctx, `{"orgs": ["Org4MSP"], "threshold": 1}`)
+	require.EqualError(t, err, "Governance is already enabled and its config can only be changed through an approved governance proposal")
+}
+
+func TestCheckGovernedTransaction(t *testing.T) {
+	ctx, chaincodeStub, _, setClient := prepGovernanceMockStub()
+
+	// Test: Governed functions can be invoked directly before governance is enabled
+	chaincodeStub.GetFunctionAndParametersReturns("CreateMembership", []string{"{}"})
+	err := checkGovernedTransaction(ctx)
+	require.NoError(t, err)
+
+	setClient("Org1MSP", true)
+	err = initGovernance(ctx, governanceConfigJSON)
+	require.NoError(t, err)
+
+	// Test: Governed functions are rejected once governance is enabled, with or without namespace
+	err = checkGovernedTransaction(ctx)
+	require.EqualError(t, err, "Function CreateMembership can only be invoked through an approved governance proposal")
+	chaincodeStub.GetFunctionAndParametersReturns("SmartContract:SetGovernanceConfig", []string{"{}"})
+	err = checkGovernedTransaction(ctx)
+	require.EqualError(t, err, "Function SetGovernanceConfig can only be invoked through an approved governance proposal")
+	chaincodeStub.GetFunctionAndParametersReturns("SetConfidentialitySecret", []string{})
+	err = checkGovernedTransaction(ctx)
+	require.EqualError(t, err, "Function SetConfidentialitySecret can only be invoked through an approved governance proposal")
+
+	// Test: Other functions are not affected
+	chaincodeStub.GetFunctionAndParametersReturns("GetMembershipBySecurityDomain", []string{"network1"})
+	err = checkGovernedTransaction(ctx)
+	require.NoError(t, err)
+}
+
+func TestGovernanceProposalWorkflow(t *testing.T) {
+	ctx, chaincodeStub, worldState, setClient := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	acpBytes, err := json.Marshal(&accessControlAsset)
+	require.NoError(t, err)
+	argsBytes, err := json.Marshal([]string{string(acpBytes)})
+	require.NoError(t, err)
+
+	// Test: Governance not enabled
+	setClient("Org1MSP", true)
+	_, err = interopcc.ProposeGovernanceChange(ctx, "CreateAccessControlPolicy", string(argsBytes))
+	require.EqualError(t, err, "Governance has not been enabled")
+
+	err = initGovernance(ctx, governanceConfigJSON)
+	require.NoError(t, err)
+
+	// Test: Invalid proposals
+	_, err = interopcc.ProposeGovernanceChange(ctx, "HandleExternalRequest", string(argsBytes))
+	require.EqualError(t, err, "Function HandleExternalRequest is not subject to governance")
+	_, err = interopcc.ProposeGovernanceChange(ctx, "PublishMemberCRL", string(argsBytes))
+	require.EqualError(t, err, "Incorrect number of arguments for PublishMemberCRL. Expecting 3, found 1")
+	setClient("Org1MSP", false)
+	_, err = interopcc.ProposeGovernanceChange(ctx, "CreateAccessControlPolicy", string(argsBytes))
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+
+	// Test: Proposal is pending until the threshold is met
+	setClient("Org1MSP", true)
+	chaincodeStub.GetTxIDReturns("proposal1")
+	proposalID, err := interopcc.ProposeGovernanceChange(ctx, "CreateAccessControlPolicy", string(argsBytes))
+	require.NoError(t, err)
+	require.Equal(t, "proposal1", proposalID)
+	proposal, err := getGovernanceProposal(ctx, proposalID)
+	require.NoError(t, err)
+	require.Equal(t, governanceProposalStatusPending, proposal.Status)
+	require.Equal(t, "Org1MSP", proposal.Proposer)
+	_, err = interopcc.GetAccessControlPolicyBySecurityDomain(ctx, accessControlAsset.SecurityDomain)
+	require.Error(t, err)
+
+	// Test: An org can only vote once
+	err = interopcc.ApproveGovernanceProposal(ctx, proposalID)
+	require.EqualError(t, err, "Org Org1MSP has already voted on governance proposal proposal1")
+
+	// Test: Change is applied when the threshold is met
+	setClient("Org2MSP", true)
+	chaincodeStub.GetTxIDReturns("approval1")
+	err = interopcc.ApproveGovernanceProposal(ctx, proposalID)
+	require.NoError(t, err)
+	proposal, err = getGovernanceProposal(ctx, proposalID)
+	require.NoError(t, err)
+	require.Equal(t, governanceProposalStatusApplied, proposal.Status)
+	require.Len(t, proposal.Votes, 2)
+	require.Equal(t, "approval1", proposal.Votes[1].TxID)
+	_, err = interopcc.GetAccessControlPolicyBySecurityDomain(ctx, accessControlAsset.SecurityDomain)
+	require.NoError(t, err)
+
+	// Test: Closed proposals cannot be voted on
+	setClient("Org3MSP", true)
+	err = interopcc.ApproveGovernanceProposal(ctx, proposalID)
+	require.EqualError(t, err, "Governance proposal proposal1 is APPLIED")
+	err = interopcc.ApproveGovernanceProposal(ctx, "unknown")
+	require.EqualError(t, err, "Governance proposal with id: unknown does not exist")
+
+	// Test: Proposal is rejected once the threshold cannot be met
+	setClient("Org1MSP", true)
+	chaincodeStub.GetTxIDReturns("proposal2")
+	deleteArgs := `["` + accessControlAsset.SecurityDomain + `"]`
+	_, err = interopcc.ProposeGovernanceChange(ctx, "DeleteAccessControlPolicy", deleteArgs)
+	require.NoError(t, err)
+	setClient("Org2MSP", true)
+	err = interopcc.RejectGovernanceProposal(ctx, "proposal2")
+	require.NoError(t, err)
+	proposal, err = getGovernanceProposal(ctx, "proposal2")
+	require.NoError(t, err)
+	require.Equal(t, governanceProposalStatusPending, proposal.Status)
+	setClient("Org3MSP", true)
+	err = interopcc.RejectGovernanceProposal(ctx, "proposal2")
+	require.NoError(t, err)
+	proposal, err = getGovernanceProposal(ctx, "proposal2")
+	require.NoError(t, err)
+	require.Equal(t, governanceProposalStatusRejected, proposal.Status)
+	_, err = interopcc.GetAccessControlPolicyBySecurityDomain(ctx, accessControlAsset.SecurityDomain)
+	require.NoError(t, err)
+
+	// Test: Proposals that fail to apply leave the proposal pending
+	setClient("Org1MSP", true)
+	chaincodeStub.GetTxIDReturns("proposal3")
+	_, err = interopcc.ProposeGovernanceChange(ctx, "CreateAccessControlPolicy", string(argsBytes))
+	require.NoError(t, err)
+	proposalBytes := worldState["governanceProposal:proposal3"]
+	setClient("Org2MSP", true)
+	err = interopcc.ApproveGovernanceProposal(ctx, "proposal3")
+	require.EqualError(t, err, "Unable to apply governance proposal proposal3: AccessControlPolicy already exists for securityDomain: "+accessControlAsset.SecurityDomain)
+	require.Equal(t, proposalBytes, worldState["governanceProposal:proposal3"])
+}
+
+func TestGetAllGovernanceProposals(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	proposal := GovernanceProposal{ProposalID: "proposal1", Function: "DeleteMembership", Args: []string{"network1"}, Status: governanceProposalStatusPending}
+	proposalBytes, err := json.Marshal(&proposal)
+	require.NoError(t, err)
+
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, false)
+	iterator.NextReturns(&queryresult.KV{Key: "proposal1", Value: proposalBytes}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+
+	proposalsJSON, err := interopcc.GetAllGovernanceProposals(ctx)
+	require.NoError(t, err)
+	var proposals []GovernanceProposal
+	require.NoError(t, json.Unmarshal([]byte(proposalsJSON), &proposals))
+	require.Equal(t, []GovernanceProposal{proposal}, proposals)
+}
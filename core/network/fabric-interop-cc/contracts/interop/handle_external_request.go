@@ -9,9 +9,11 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -24,18 +26,91 @@ import (
 // HandleExternalRequest chaincode processes requests that come from external networks.
 //
 // The flow coordinates the following:
-// 1. Checks the validity of query signature
+// 1. Checks that the query is not an invocation, and the validity of query signature
 // 2. Checks that the certificate of the requester is valid according to the network's Membership
-// 3. Checks the access control policy for the requester and view address is met
-// 4. Calls application chaincode
+// 3. Checks that the view address refers to a registered application chaincode, and that the access control
+//    policy for the requester and view address is met, including that the query is confidential if the rule
+//    permitting it requires so
+// 4. Checks that the query is not a replay of an earlier one
+// 5. Calls application chaincode, and checks that the response to a view of a private data collection holds a value
+//    that matches its hash on the ledger
+// 6. Redacts the response as specified by the access control rule, and encrypts it to the requestor's public key
+//    if the query is confidential
+// 7. Records the request in the audit log and emits an event
 func (s *SmartContract) HandleExternalRequest(ctx contractapi.TransactionContextInterface, b64QueryBytes string) (string, error) {
+	query, x509Cert, viewAddress, rule, err := verifyExternalRequest(s, ctx, b64QueryBytes, accessPermissionRead, getQuerySignedMessage)
+	if err != nil {
+		return "", err
+	}
+	// 5. Calls application chaincode
+	arr := append([]string{viewAddress.CCFunc}, viewAddress.Args...)
+	byteArgs := strArrToBytesArr(arr)
+	
+	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	payload := []byte("")
+	if localCCId == viewAddress.Contract {
+		// Interop call to InteropCC itself, which is limited to the functions it exposes to remote networks
+		payload, err = queryLocalFunction(s, ctx, query, viewAddress)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// General Interop Call to AppCC
+		pbResp := ctx.GetStub().InvokeChaincode(viewAddress.Contract, byteArgs, viewAddress.Channel)
+		if pbResp.Status != shim.OK {
+			errorMessage := fmt.Sprintf("Application chaincode invoke error: %s", string(pbResp.GetMessage()))
+			log.Error(errorMessage)
+			return "", errors.New(errorMessage)
+		}
+		payload = pbResp.Payload
+	}
+	if viewAddress.Collection != "" {
+		_, err = verifyPrivateDataPayload(payload, viewAddress.Collection)
+		if err != nil {
+			errorMessage := fmt.Sprintf("Invalid private data response: %s", err)
+			log.Error(errorMessage)
+			return "", errors.New(errorMessage)
+		}
+		if getRuleRedaction(rule) != nil {
+			errorMessage := "Responses to views of private data collections cannot be redacted"
+			log.Error(errorMessage)
+			return "", errors.New(errorMessage)
+		}
+	}
+
+	// 6. Redacts the response as specified by the access control rule and encrypts it to the requestor's public
+	// key if the query is confidential
+	redaction := getRuleRedaction(rule)
+	payload, err = redactJSONPayload(payload, redaction)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unable to redact response: %s", err)
+		log.Error(errorMessage)
+		return "", errors.New(errorMessage)
+	}
+	interopPayloadBytes, err := generateInteropPayload(ctx, query, payload, redaction, x509Cert)
+	if err != nil {
+		return "", err
+	}
+	// 7. Records the request in the audit log
+	err = recordExternalRequest(ctx, handleExternalRequestEventName, query, x509Cert)
+	if err != nil {
+		return "", err
+	}
+	return string(interopPayloadBytes), nil
+}
+
+// verifyExternalRequest decodes a request from a remote network and performs the checks that are common to
+// queries and invocations, i.e., steps 1 to 4 of HandleExternalRequest, for the given access permission.
+// The signature of the requester is checked over the message returned by getSignedMessage for the query.
+// The access control rule that permits the request is returned along with the query.
+func verifyExternalRequest(s *SmartContract, ctx contractapi.TransactionContextInterface, b64QueryBytes string, permission string, getSignedMessage func(*common.Query) string) (*common.Query, *x509.Certificate, *FabricViewAddress, *common.Rule, error) {
 	// Ensure that this function cannot be called by a client without relay permissions
 	relayAccessCheck, err := wutils.IsClientRelay(ctx.GetStub())
 	if err != nil {
-		return "", err
+		return nil, nil, nil, nil, err
 	}
 	if !relayAccessCheck {
-		return "", fmt.Errorf("Illegal access by relay")
+		return nil, nil, nil, nil, fmt.Errorf("Illegal access by relay")
 	}
 	fmt.Println("Relay access check passed")
 
@@ -43,33 +118,47 @@ func (s *SmartContract) HandleExternalRequest(ctx contractapi.TransactionContext
 	if err != nil {
 		errorMessage := fmt.Sprintf("Unable to base64 decode data: %s", err.Error())
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
-	var query common.Query
-	err = protoV2.Unmarshal(queryBytes, &query)
+	query := &common.Query{}
+	err = protoV2.Unmarshal(queryBytes, query)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Unable to unmarshal query: %s", err.Error())
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
+	}
+	// Invocations are marked as such in the signed message, so they cannot be submitted as queries or vice versa
+	if query.Invoke != (permission == accessPermissionWrite) {
+		errorMessage := "Queries must be submitted through HandleExternalRequest"
+		if query.Invoke {
+			errorMessage = "Invocations must be submitted through HandleExternalInvocation"
+		}
+		log.Error(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
+	}
+	if query.Invoke && query.Timestamp == 0 {
+		errorMessage := "Invocation does not contain a timestamp"
+		log.Error(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
 	x509Cert, err := parseCert(query.Certificate)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Unable to parse certificate: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
 	// 1. Checks the validity of query signature
 	signatureBytes, err := base64.StdEncoding.DecodeString(query.RequestorSignature)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Signature base64 decoding failed: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
-	err = validateSignature(query.Address+query.Nonce, x509Cert, string(signatureBytes))
+	err = validateSignature(getSignedMessage(query), x509Cert, string(signatureBytes))
 	if err != nil {
 		errorMessage := fmt.Sprintf("Invalid Signature: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
 	// 2. Checks that the certificate of the requester is valid according to the network's Membership
 	if query.RequestingOrg == "" {
@@ -80,93 +169,104 @@ func (s *SmartContract) HandleExternalRequest(ctx contractapi.TransactionContext
 	if err != nil {
 		errorMessage := fmt.Sprintf("Membership Verification failed: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
-	// 3. Checks the access control policy for the requester and view address is met
+	// 3. Checks that the view address refers to a registered application chaincode and that the access control
+	// policy for the requester and view address is met
 	address, err := parseAddress(query.Address)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Invalid address: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
 	viewAddress, err := parseFabricViewAddress(address.ViewSegment)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Invalid view address: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
-	err = verifyAccessToCC(s, ctx, viewAddress, address.ViewSegment, &query)
+	err = verifyApplicationChaincode(ctx, viewAddress)
 	if err != nil {
 		errorMessage := fmt.Sprintf("CC Access Denied: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
-	// 4. Calls application chaincode
-	arr := append([]string{viewAddress.CCFunc}, viewAddress.Args...)
-	byteArgs := strArrToBytesArr(arr)
-	
-	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
-	payload := []byte("")
-	if localCCId == viewAddress.Contract {
-		// Interop call to InteropCC itself.
-		resp := ""
-		if viewAddress.CCFunc == "GetHTLCHash" {
-			if len(viewAddress.Args) > 2 {
-				errorMessage := fmt.Sprintf("Recieved more arguments than required 2 argument.")
-				log.Error(errorMessage)
-				return "", errors.New(errorMessage)
-			}
-			resp, err = s.GetHTLCHash(ctx, viewAddress.Args[0], viewAddress.Args[1])
-		} else if viewAddress.CCFunc == "GetHTLCHashByContractId" {
-			if len(viewAddress.Args) > 1 {
-				errorMessage := fmt.Sprintf("Recieved more arguments than required 1 argument.")
-				log.Error(errorMessage)
-				return "", errors.New(errorMessage)
-			}
-			resp, err = s.GetHTLCHashByContractId(ctx, viewAddress.Args[0])
-		} else if viewAddress.CCFunc == "GetHTLCHashPreImage" {
-			if len(viewAddress.Args) > 2 {
-				errorMessage := fmt.Sprintf("Recieved more arguments than required 2 argument.")
-				log.Error(errorMessage)
-				return "", errors.New(errorMessage)
-			}
-			resp, err = s.GetHTLCHashPreImage(ctx, viewAddress.Args[0], viewAddress.Args[1])
-		} else if viewAddress.CCFunc == "GetHTLCHashPreImageByContractId" {
-			if len(viewAddress.Args) > 1 {
-				errorMessage := fmt.Sprintf("Recieved more arguments than required 1 argument.")
-				log.Error(errorMessage)
-				return "", errors.New(errorMessage)
-			}
-			resp, err = s.GetHTLCHashPreImageByContractId(ctx, viewAddress.Args[0])
-		} else {
-			errorMessage := fmt.Sprintf("Given function %s can not be invoked in Interop Chaincode.", viewAddress.CCFunc)
-			err = errors.New(errorMessage)
-		}
-		if err != nil {
-			log.Error(err)
-			return "", err
-		}
-		payload = []byte(resp)
-	} else {
-		// General Interop Call to AppCC
-		pbResp := ctx.GetStub().InvokeChaincode(viewAddress.Contract, byteArgs, viewAddress.Channel)
-		if pbResp.Status != shim.OK {
-			errorMessage := fmt.Sprintf("Application chaincode invoke error: %s", string(pbResp.GetMessage()))
-			log.Error(errorMessage)
-			return "", errors.New(errorMessage)
-		}
-		payload = pbResp.Payload
+	rule, err := verifyAccessToCC(s, ctx, viewAddress, address.ViewSegment, query, x509Cert, permission)
+	if err != nil {
+		errorMessage := fmt.Sprintf("CC Access Denied: %s", err)
+		log.Error(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
 	}
+	if rule.GetConfidential() && !query.Confidential {
+		errorMessage := fmt.Sprintf("Access control rule for %s requires a confidential query", query.Address)
+		log.Error(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
+	}
+	// 4. Checks that the query is not a replay of an earlier one
+	err = verifyQueryNotReplayed(ctx, query, x509Cert)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Replay check failed: %s", err)
+		log.Error(errorMessage)
+		return nil, nil, nil, nil, errors.New(errorMessage)
+	}
+	return query, x509Cert, viewAddress, rule, nil
+}
 
-	interopPayloadStruct := common.InteropPayload{
+// generateInteropPayload wraps the response to a request from a remote network in an InteropPayload, encrypting it
+// to the requestor's public key if the query is confidential. The redaction applied to the response, if any, and
+// the time of the proposal are recorded in the InteropPayload, so that requestors can check the age of the view.
+func generateInteropPayload(ctx contractapi.TransactionContextInterface, query *common.Query, payload []byte, redaction *common.Redaction, x509Cert *x509.Certificate) ([]byte, error) {
+	interopPayloadStruct := &common.InteropPayload{
 		Address: query.Address,
 		Payload: payload,
 	}
-	interopPayloadBytes, err := protoV2.Marshal(&interopPayloadStruct)
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query.Confidential {
+		// Encrypt the response to the requestor's public key so that relays cannot read it
+		interopPayloadStruct, err = generateConfidentialInteropPayload(ctx, payload, query.Address, query.Certificate, x509Cert)
+		if err != nil {
+			errorMessage := fmt.Sprintf("Unable to generate confidential payload: %s", err)
+			log.Error(errorMessage)
+			return nil, errors.New(errorMessage)
+		}
+	}
+	interopPayloadStruct.Redaction = redaction
+	interopPayloadStruct.Timestamp = uint64(txTimeSecs)
+	interopPayloadBytes, err := protoV2.Marshal(interopPayloadStruct)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Unable to marshal interop payload: %s", err)
 		log.Error(errorMessage)
-		return "", errors.New(errorMessage)
+		return nil, errors.New(errorMessage)
 	}
-	return string(interopPayloadBytes), nil
+	return interopPayloadBytes, nil
+}
+
+// getExternalRequestID returns the ID of a request from a remote network, which is the nonce if the request ID is not set
+func getExternalRequestID(query *common.Query) string {
+	if query.RequestId != "" {
+		return query.RequestId
+	}
+	return query.Nonce
+}
+
+// recordExternalRequest records a request from a remote network in the audit log and emits the corresponding event
+func recordExternalRequest(ctx contractapi.TransactionContextInterface, eventName string, query *common.Query, x509Cert *x509.Certificate) error {
+	auditLogEntry, err := newAuditLogEntry(ctx, eventName, query.RequestingNetwork, getExternalRequestID(query), map[string]string{
+		"address":       query.Address,
+		"requestingOrg": query.RequestingOrg,
+		"requestor":     x509Cert.Subject.String(),
+		"confidential":  strconv.FormatBool(query.Confidential),
+	})
+	if err == nil {
+		err = recordInteropEvent(ctx, eventName, auditLogEntry)
+	}
+	if err != nil {
+		errorMessage := fmt.Sprintf("Unable to record request: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	return nil
 }
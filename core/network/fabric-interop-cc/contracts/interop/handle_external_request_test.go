@@ -26,9 +26,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	protoV2 "google.golang.org/protobuf/proto"
 	mspProtobuf "github.com/hyperledger/fabric-protos-go/msp"
 	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
 )
 
 // function that supplies value that is to be returned by ctx.GetStub().GetCreator()
@@ -107,6 +109,13 @@ func TestHandleExternalRequest(t *testing.T) {
 		}},
 	}
 
+	// Application chaincode registry entry
+	var applicationChaincode = ApplicationChaincode{
+		ChaincodeID: "interop",
+		Channel:     "mychannel",
+		Enabled:     true,
+	}
+
 	// Invoke Response
 	var pbResp = pb.Response{
 		Status:  shim.OK,
@@ -123,11 +132,17 @@ func TestHandleExternalRequest(t *testing.T) {
 	// Invalid Cert
 	testHandleExternalRequestInvalidCert(t, &query)
 	// No matching Access control policy for requesting network
-	testHandleExternalRequestNoAccessControlPolicy(t, &query, validCertificate, signature, pbResp, &membershipAsset)
+	testHandleExternalRequestNoAccessControlPolicy(t, &query, validCertificate, signature, pbResp, &applicationChaincode, &membershipAsset)
+	// Application chaincode not registered or disabled
+	testHandleExternalRequestUnregisteredApplication(t, &query, validCertificate, signature, &applicationChaincode, &membershipAsset)
 	// No matching Membership for requesting network
 	testHandleExternalRequestNoMembership(t, &query, validCertificate, signature, pbResp)
 	// Happy case. ECDSA Cert and Valid Signature
-	testHandleExternalRequestECDSAHappyCase(t, &query, validCertificate, signature, pbResp, &accessControlAsset, &membershipAsset)
+	testHandleExternalRequestECDSAHappyCase(t, &query, validCertificate, signature, pbResp, &applicationChaincode, &accessControlAsset, &membershipAsset)
+	// Happy case with the response encrypted to the requestor's certificate
+	testHandleExternalRequestConfidential(t, &query, validCertificate, signature, key, pbResp, &applicationChaincode, &accessControlAsset, &membershipAsset)
+	// Happy case with the response redacted by the access control rule
+	testHandleExternalRequestRedaction(t, &query, validCertificate, signature, &applicationChaincode, &accessControlAsset, &membershipAsset)
 	// ed25519 Cert and Signature
 	testHandleExternalRequestED25519Signature(t, &query, pbResp, &accessControlAsset, &membershipAsset, template)
 }
@@ -193,7 +208,7 @@ func testHandleExternalRequestInvalidCert(t *testing.T, query *common.Query) {
 	require.EqualError(t, err, fmt.Sprintf("Unable to parse certificate: Client cert not in a known PEM format"))
 }
 
-func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query, validCertificate string, signature []byte, pbResp pb.Response, accessControl *common.AccessControlPolicy, membership *common.Membership) {
+func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query, validCertificate string, signature []byte, pbResp pb.Response, applicationChaincode *ApplicationChaincode, accessControl *common.AccessControlPolicy, membership *common.Membership) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
 	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
@@ -207,9 +222,12 @@ func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query,
 	queryBytes, err := protoV2.Marshal(query)
 	require.NoError(t, err)
 	b64QueryBytes := base64.StdEncoding.EncodeToString(queryBytes)
+	// the time of the proposal is stamped into the response
+	txTimestamp := ptypes.TimestampNow()
 	interopPayload := common.InteropPayload{
-		Payload: []byte("17.12"),
-		Address: "localhost:9080/network1/mychannel:interop:Read:a",
+		Payload:   []byte("17.12"),
+		Address:   "localhost:9080/network1/mychannel:interop:Read:a",
+		Timestamp: uint64(txTimestamp.Seconds),
 	}
 	interopPayloadBytes, err := protoV2.Marshal(&interopPayload)
 	require.NoError(t, err)
@@ -219,8 +237,12 @@ func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query,
 	require.NoError(t, err)
 	accessControlBytes, err := json.Marshal(accessControl)
 	require.NoError(t, err)
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(1, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, applicationChaincodeBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, accessControlBytes, nil)
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 
 	interopResponse, err := interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
@@ -228,6 +250,78 @@ func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query,
 	require.NoError(t, err)
 }
 
+func testHandleExternalRequestConfidential(t *testing.T, query *common.Query, validCertificate string, signature []byte, key *ecdsa.PrivateKey, pbResp pb.Response, applicationChaincode *ApplicationChaincode, accessControl *common.AccessControlPolicy, membership *common.Membership) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	interopCCId := "interopcc"
+	wtest.SetMockStubCCId(chaincodeStub, interopCCId)
+
+	// set correct values for this test case
+	query.Certificate = validCertificate
+	query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+	query.Confidential = true
+	defer func() { query.Confidential = false }()
+	queryBytes, err := protoV2.Marshal(query)
+	require.NoError(t, err)
+	b64QueryBytes := base64.StdEncoding.EncodeToString(queryBytes)
+
+	// mock all the calls to the chaincode stub
+	membershipBytes, err := json.Marshal(membership)
+	require.NoError(t, err)
+	accessControlBytes, err := json.Marshal(accessControl)
+	require.NoError(t, err)
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, applicationChaincodeBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, accessControlBytes, nil)
+	chaincodeStub.GetTxTimestampReturns(ptypes.TimestampNow(), nil)
+	chaincodeStub.InvokeChaincodeReturns(pbResp)
+	chaincodeStub.GetPrivateDataReturns(testConfidentialitySecret, nil)
+
+	interopResponse, err := interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal([]byte(interopResponse), &interopPayload)
+	require.NoError(t, err)
+	require.True(t, interopPayload.Confidential)
+	require.Equal(t, query.Address, interopPayload.Address)
+	require.Equal(t, validCertificate, interopPayload.RequestorCertificate)
+
+	// The requestor can decrypt the response and check it against the commitment
+	var confidentialPayload common.ConfidentialPayload
+	err = protoV2.Unmarshal(interopPayload.Payload, &confidentialPayload)
+	require.NoError(t, err)
+	confidentialPayload.DecryptedPayload, err = decryptECIES(key, confidentialPayload.EncryptedPayload)
+	require.NoError(t, err)
+	contents, err := extractDecryptedContents(&confidentialPayload)
+	require.NoError(t, err)
+	require.Equal(t, pbResp.Payload, contents.Payload)
+
+	// Test: Queries that are not confidential are rejected if the rule permitting them requires confidentiality
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, interopCCId)
+	confidentialAccessControl := protoV2.Clone(accessControl).(*common.AccessControlPolicy)
+	for _, rule := range confidentialAccessControl.Rules {
+		rule.Confidential = true
+	}
+	accessControlBytes, err = json.Marshal(confidentialAccessControl)
+	require.NoError(t, err)
+	query.Confidential = false
+	queryBytes, err = protoV2.Marshal(query)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, applicationChaincodeBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, accessControlBytes, nil)
+	chaincodeStub.GetTxTimestampReturns(ptypes.TimestampNow(), nil)
+	chaincodeStub.InvokeChaincodeReturns(pbResp)
+	_, err = interopcc.HandleExternalRequest(ctx, base64.StdEncoding.EncodeToString(queryBytes))
+	require.EqualError(t, err, fmt.Sprintf("Access control rule for %s requires a confidential query", query.Address))
+	require.Equal(t, 0, chaincodeStub.InvokeChaincodeCallCount())
+}
+
 func testHandleExternalRequestED25519Signature(t *testing.T, query *common.Query, pbResp pb.Response, accessControl *common.AccessControlPolicy, fabricMembership *common.Membership, template x509.Certificate) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
@@ -288,7 +382,43 @@ func testHandleExternalRequestNoMembership(t *testing.T, query *common.Query, va
 	require.EqualError(t, err, fmt.Sprintf("Membership Verification failed: Membership with id: %s does not exist", query.RequestingNetwork))
 }
 
-func testHandleExternalRequestNoAccessControlPolicy(t *testing.T, query *common.Query, validCertificate string, signature []byte, pbResp pb.Response, membership *common.Membership) {
+func testHandleExternalRequestUnregisteredApplication(t *testing.T, query *common.Query, validCertificate string, signature []byte, applicationChaincode *ApplicationChaincode, membership *common.Membership) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+
+	// set correct values for this test case
+	query.Certificate = validCertificate
+	query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+	queryBytes, err := protoV2.Marshal(query)
+	require.NoError(t, err)
+	b64QueryBytes := base64.StdEncoding.EncodeToString(queryBytes)
+
+	// mock all the calls to the chaincode stub
+	membershipBytes, err := json.Marshal(membership)
+	require.NoError(t, err)
+	disabledApplicationChaincode := *applicationChaincode
+	disabledApplicationChaincode.Enabled = false
+	disabledApplicationChaincodeBytes, err := json.Marshal(&disabledApplicationChaincode)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, nil, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, disabledApplicationChaincodeBytes, nil)
+	// other application chaincodes are registered
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturns(true)
+	chaincodeStub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+
+	_, err = interopcc.HandleExternalRequest(ctx, b64QueryBytes)
+	require.EqualError(t, err, "CC Access Denied: Application chaincode interop on channel mychannel is not registered")
+	_, err = interopcc.HandleExternalRequest(ctx, b64QueryBytes)
+	require.EqualError(t, err, "CC Access Denied: Application chaincode interop on channel mychannel is disabled")
+	require.Equal(t, 0, chaincodeStub.InvokeChaincodeCallCount())
+}
+
+func testHandleExternalRequestNoAccessControlPolicy(t *testing.T, query *common.Query, validCertificate string, signature []byte, pbResp pb.Response, applicationChaincode *ApplicationChaincode, membership *common.Membership) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
 	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
@@ -306,9 +436,49 @@ func testHandleExternalRequestNoAccessControlPolicy(t *testing.T, query *common.
 	// mock all the calls to the chaincode stub
 	membershipBytes, err := json.Marshal(membership)
 	require.NoError(t, err)
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(1, nil, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, applicationChaincodeBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, nil, nil)
 
 	_, err = interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
 	require.EqualError(t, err, fmt.Sprintf("CC Access Denied: Access control policy does not exist for network: %s", query.RequestingNetwork))
 }
+
+func testHandleExternalRequestRedaction(t *testing.T, query *common.Query, validCertificate string, signature []byte, applicationChaincode *ApplicationChaincode, accessControl *common.AccessControlPolicy, membership *common.Membership) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+
+	// set correct values for this test case
+	query.Certificate = validCertificate
+	query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+	queryBytes, err := protoV2.Marshal(query)
+	require.NoError(t, err)
+	b64QueryBytes := base64.StdEncoding.EncodeToString(queryBytes)
+	redactingRule := proto.Clone(accessControl.Rules[0]).(*common.Rule)
+	redactingRule.ExcludePaths = []string{"price"}
+	redactingAccessControl := common.AccessControlPolicy{SecurityDomain: accessControl.SecurityDomain, Rules: []*common.Rule{redactingRule}}
+
+	// mock all the calls to the chaincode stub
+	membershipBytes, err := json.Marshal(membership)
+	require.NoError(t, err)
+	accessControlBytes, err := json.Marshal(&redactingAccessControl)
+	require.NoError(t, err)
+	applicationChaincodeBytes, err := json.Marshal(applicationChaincode)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, applicationChaincodeBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, accessControlBytes, nil)
+	chaincodeStub.InvokeChaincodeReturns(pb.Response{Status: shim.OK, Payload: []byte(`{"id":"a","price":17.12}`)})
+
+	interopResponse, err := interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal([]byte(interopResponse), &interopPayload)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"a"}`, string(interopPayload.Payload))
+	require.Equal(t, []string{"price"}, interopPayload.Redaction.ExcludePaths)
+}
@@ -10,6 +10,11 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern"
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 // Address contains the information that was sent in the address field of a query from an external network
@@ -20,11 +25,13 @@ type Address struct {
 }
 
 // FabricViewAddress contains the data relevant to the view sent in the address string by the remote client.
+// Collection is set if the view targets a private data collection of the contract.
 type FabricViewAddress struct {
-	Channel  string
-	Contract string
-	CCFunc   string
-	Args     []string
+	Channel    string
+	Contract   string
+	Collection string
+	CCFunc     string
+	Args       []string
 }
 
 // strArrToBytesArr converts an array of strings into an array of []byte
@@ -54,6 +61,7 @@ func parseAddress(address string) (*Address, error) {
 
 // parseFabricViewAddress receives the view segment of an address and constructs a FabricViewAddress from it
 // It splits on ':' to get sections in the viewAddress. Channel, Contract, CCFunc, the rest are arguments for the chaincode
+// The Contract may be followed by '#' and the name of a private data collection, e.g. mychannel:pricecc#prices:GetPrice:a
 func parseFabricViewAddress(viewAddress string) (*FabricViewAddress, error) {
 	if strings.Contains(viewAddress, "/") {
 		return nil, fmt.Errorf("View segment contains a '/' %s", viewAddress)
@@ -62,8 +70,15 @@ func parseFabricViewAddress(viewAddress string) (*FabricViewAddress, error) {
 	if len(fabricArgs) < 3 {
 		return nil, fmt.Errorf("View segment not formatted correctly %s", viewAddress)
 	}
+	contract, collection := fabricArgs[1], ""
+	if index := strings.Index(contract, privateDataCollectionSeparator); index >= 0 {
+		contract, collection = contract[:index], contract[index+1:]
+		if contract == "" || collection == "" {
+			return nil, fmt.Errorf("View segment not formatted correctly %s", viewAddress)
+		}
+	}
 
-	return &FabricViewAddress{Channel: fabricArgs[0], Contract: fabricArgs[1], CCFunc: fabricArgs[2], Args: fabricArgs[3:]}, nil
+	return &FabricViewAddress{Channel: fabricArgs[0], Contract: contract, Collection: collection, CCFunc: fabricArgs[2], Args: fabricArgs[3:]}, nil
 }
 
 // Contains tells whether a contains x.
@@ -76,37 +91,38 @@ func Contains(a []string, x string) bool {
 	return false
 }
 
+// validPatternString checks if an address pattern is well formed (see the addresspattern library)
 func validPatternString(pattern string) bool {
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// check if 0 or 1 stars
-	if numStars <= 1 {
-		// if 0 stars, return true, if 1 star, make sure its at the end
-		return strings.HasSuffix(pattern, "*") || numStars == 0
-	}
-
-	return false
+	return addresspattern.IsValid(pattern)
 }
 
+// isPatternAndAddressMatch checks if the view segment of an address matches a pattern (see the addresspattern library)
 func isPatternAndAddressMatch(pattern string, address string) bool {
-	// make sure the pattern is valid
-	if !validPatternString(pattern) {
-		return false
-	}
-
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
+	return addresspattern.Match(pattern, address)
+}
 
-	// if 0 stars, and exact match, return true
-	if numStars == 0 && pattern == address {
-		return true
+// getTxTimeSecs returns the timestamp of the current transaction in seconds, subject to the tolerated skew
+// from the clock of the endorsing peer, if any
+func getTxTimeSecs(ctx contractapi.TransactionContextInterface) (int64, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("Unable to get transaction timestamp: %s", err)
 	}
-
-	// if 1 star and pattern is a substring of address, return true
-	if numStars == 1 && strings.Contains(address, strings.TrimRight(pattern, "*")) {
-		return true
+	if txTimestamp == nil {
+		return 0, fmt.Errorf("Transaction timestamp is not set")
+	}
+	_, err = wutils.GetTxTimeSecs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid transaction timestamp: %s", err)
 	}
+	return txTimestamp.GetSeconds(), nil
+}
 
-	return false
+// getTxTime returns the timestamp of the current transaction, against which certificate validity is checked
+func getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(txTimeSecs, 0), nil
 }
@@ -9,7 +9,10 @@ package main
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,6 +36,13 @@ func TestParseFabricViewAddress(t *testing.T) {
 	withSlash := "mychannel/mychannel:interop:Read:a"
 	result, err = parseFabricViewAddress(withSlash)
 	require.EqualError(t, err, fmt.Sprintf("View segment contains a '/' %s", withSlash))
+	// Success case view of a private data collection
+	result, err = parseFabricViewAddress("mychannel:pricecc#prices:GetPrice:a")
+	require.NoError(t, err)
+	require.Equal(t, &FabricViewAddress{Channel: "mychannel", Contract: "pricecc", Collection: "prices", CCFunc: "GetPrice", Args: []string{"a"}}, result)
+	// Error case collection is empty
+	result, err = parseFabricViewAddress("mychannel:pricecc#:GetPrice:a")
+	require.EqualError(t, err, "View segment not formatted correctly mychannel:pricecc#:GetPrice:a")
 }
 
 func TestParseAdress(t *testing.T) {
@@ -68,8 +78,11 @@ func TestValidPatternString(t *testing.T) {
 	tooManyStars := "One*:*too:many"
 	result = validPatternString(tooManyStars)
 	require.False(t, result)
+	//Happy star for a single segment
+	result = validPatternString("test:*:star")
+	require.True(t, result)
 	//Unhappy invalid star location
-	invalidStarLocation := "test:*:star"
+	invalidStarLocation := "test:s*tar"
 	result = validPatternString(invalidStarLocation)
 	require.False(t, result)
 	//Just star
@@ -99,4 +112,39 @@ func TestIsPatternAndAddressMatch(t *testing.T) {
 	result = isPatternAndAddressMatch("*", exactMatchString)
 	require.True(t, result)
 
+	// Prefixes are anchored at the start of the address
+	require.True(t, isPatternAndAddressMatch("mychannel:simpleasset:Read*", "mychannel:simpleasset:ReadAsset:a"))
+	require.False(t, isPatternAndAddressMatch("mychannel:simpleasset:Read*", "otherchannel:mychannel:simpleasset:ReadAsset:a"))
+	require.False(t, isPatternAndAddressMatch("test:exact", "test:exact:more"))
+
+	// Wildcards for a single segment and for arguments
+	require.True(t, isPatternAndAddressMatch("mychannel:*:Read:a", "mychannel:simpleasset:Read:a"))
+	require.False(t, isPatternAndAddressMatch("mychannel:*:Read:a", "mychannel:simpleasset:Read:b"))
+	require.True(t, isPatternAndAddressMatch("mychannel:simpleasset:Re*:a", "mychannel:simpleasset:Read:a"))
+	require.False(t, isPatternAndAddressMatch("mychannel:simpleasset:Re*:a", "mychannel:simpleasset:Read:a:b"))
+	require.True(t, isPatternAndAddressMatch("mychannel:simpleasset:Read:*", "mychannel:simpleasset:Read:a:b"))
+	require.False(t, isPatternAndAddressMatch("mychannel:simpleasset:Read:*", "mychannel:simpleasset:Read"))
+	require.True(t, isPatternAndAddressMatch("mychannel:simpleasset:Read:a*", "mychannel:simpleasset:Read:abc:d"))
+
+}
+
+func TestGetTxTimeSecs(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	txTime := time.Now().Add(-time.Hour)
+	wtest.SetMockStubTxTime(chaincodeStub, txTime)
+
+	// Test: The transaction timestamp is used regardless of the clock of the peer
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, txTime.Unix(), txTimeSecs)
+
+	// Test: Timestamps too far from the clock of the peer are rejected once a tolerance is set
+	wutils.SetTxTimeSkewTolerance(60)
+	defer wutils.SetTxTimeSkewTolerance(0)
+	_, err = getTxTimeSecs(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid transaction timestamp: transaction timestamp")
+	wtest.SetMockStubTxTime(chaincodeStub, time.Now())
+	_, err = getTxTimeSecs(ctx)
+	require.NoError(t, err)
 }
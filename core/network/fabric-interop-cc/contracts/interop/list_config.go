@@ -0,0 +1,183 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// listconfig contains the functions to enumerate the Memberships, AccessControlPolicies and VerificationPolicies
+// recorded in the ledger, one page at a time
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxPageSize is the largest number of records that can be fetched in a page by the list functions
+const maxPageSize = int32(1000)
+
+// ConfigPage is a page of configuration records, along with the bookmark to fetch the next page.
+//
+// Filters are applied before pagination, so a page only holds fewer records than the page size when it is the
+// last page. The bookmark is empty after the last page.
+type ConfigPage struct {
+	Records  []json.RawMessage `json:"records"`
+	Bookmark string            `json:"bookmark"`
+}
+
+// validatePageSize checks the page size requested from a list function
+func validatePageSize(pageSize int32) error {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return fmt.Errorf("Page size must be between 1 and %d, found %d", maxPageSize, pageSize)
+	}
+	return nil
+}
+
+// ListMemberships cc returns a page of the Memberships in the ledger, optionally restricted to those with
+// a member for the given org. An empty bookmark fetches the first page.
+func (s *SmartContract) ListMemberships(ctx contractapi.TransactionContextInterface, org string, pageSize int32, bookmark string) (string, error) {
+	return getConfigPage(ctx, membershipObjectType, pageSize, bookmark, func(bytes []byte) (bool, error) {
+		if org == "" {
+			return true, nil
+		}
+		membership, err := decodeMembership(bytes)
+		if err != nil {
+			return false, fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+		}
+		_, ok := membership.Members[org]
+		return ok, nil
+	})
+}
+
+// ListAccessControlPolicies cc returns a page of the AccessControlPolicies in the ledger, optionally restricted
+// to those with a rule for the given org and to those with a rule whose resource overlaps the given address
+// pattern. An empty bookmark fetches the first page.
+func (s *SmartContract) ListAccessControlPolicies(ctx contractapi.TransactionContextInterface, org string, addressPattern string, pageSize int32, bookmark string) (string, error) {
+	return getConfigPage(ctx, accessControlObjectType, pageSize, bookmark, func(bytes []byte) (bool, error) {
+		accessControlPolicy, err := decodeAccessControlPolicy(bytes)
+		if err != nil {
+			return false, fmt.Errorf("Failed to unmarshal access control policy: %s", err.Error())
+		}
+		return accessControlPolicyMatchesFilter(accessControlPolicy, org, addressPattern), nil
+	})
+}
+
+// ListVerificationPolicies cc returns a page of the VerificationPolicies in the ledger, optionally restricted
+// to those whose criteria mention the given org and to those with an identifier whose pattern overlaps the given
+// address pattern. An empty bookmark fetches the first page.
+func (s *SmartContract) ListVerificationPolicies(ctx contractapi.TransactionContextInterface, org string, addressPattern string, pageSize int32, bookmark string) (string, error) {
+	return getConfigPage(ctx, verificationPolicyObjectType, pageSize, bookmark, func(bytes []byte) (bool, error) {
+		verificationPolicy, err := decodeVerificationPolicy(bytes)
+		if err != nil {
+			return false, fmt.Errorf("Failed to unmarshal verification policy: %s", err.Error())
+		}
+		return verificationPolicyMatchesFilter(verificationPolicy, org, addressPattern)
+	})
+}
+
+// getConfigPage fetches a page of the records of an object type, keeping those accepted by the filter. Records are
+// read from the ledger until the page is full or the records run out, so that filtered out records do not leave
+// the page short.
+func getConfigPage(ctx contractapi.TransactionContextInterface, objectType string, pageSize int32, bookmark string, filter func([]byte) (bool, error)) (string, error) {
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return "", err
+	}
+	page := ConfigPage{Records: []json.RawMessage{}, Bookmark: bookmark}
+	for int32(len(page.Records)) < pageSize {
+		remaining := pageSize - int32(len(page.Records))
+		fetched, err := appendConfigRecords(ctx, objectType, remaining, &page, filter)
+		if err != nil {
+			return "", err
+		}
+		if fetched < remaining || page.Bookmark == "" {
+			break
+		}
+	}
+	pageBytes, err := json.Marshal(&page)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(pageBytes), nil
+}
+
+// appendConfigRecords fetches up to count records of an object type from the page bookmark, appends those accepted
+// by the filter to the page and moves the bookmark past them. It returns the number of records fetched.
+func appendConfigRecords(ctx contractapi.TransactionContextInterface, objectType string, count int32, page *ConfigPage, filter func([]byte) (bool, error)) (int32, error) {
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(objectType, []string{}, count, page.Bookmark)
+	if err != nil {
+		return 0, err
+	}
+	defer iterator.Close()
+	fetched := int32(0)
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		fetched++
+		matches, err := filter(result.Value)
+		if err != nil {
+			return 0, err
+		}
+		if matches {
+			page.Records = append(page.Records, json.RawMessage(result.Value))
+		}
+	}
+	page.Bookmark = ""
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+	}
+	return fetched, nil
+}
+
+// accessControlPolicyMatchesFilter checks if an AccessControlPolicy has a rule for the org, if set, and a rule
+// whose resource overlaps the address pattern, if set
+func accessControlPolicyMatchesFilter(accessControlPolicy *common.AccessControlPolicy, org string, addressPattern string) bool {
+	orgMatches := org == ""
+	addressMatches := addressPattern == ""
+	for _, rule := range accessControlPolicy.Rules {
+		if (rule.PrincipalType == "ca" && rule.Principal == org) || (org != "" && rule.Org == org) {
+			orgMatches = true
+		}
+		if addressPattern != "" && addressPatternsOverlap(rule.Resource, addressPattern) {
+			addressMatches = true
+		}
+	}
+	return orgMatches && addressMatches
+}
+
+// verificationPolicyMatchesFilter checks if a VerificationPolicy has an identifier whose criteria mention the org,
+// if set, and an identifier whose pattern overlaps the address pattern, if set
+func verificationPolicyMatchesFilter(verificationPolicy *common.VerificationPolicy, org string, addressPattern string) (bool, error) {
+	orgMatches := org == ""
+	addressMatches := addressPattern == ""
+	for _, identifier := range verificationPolicy.Identifiers {
+		if addressPattern != "" && addressPatternsOverlap(identifier.Pattern, addressPattern) {
+			addressMatches = true
+		}
+		if org == "" || identifier.Policy == nil {
+			continue
+		}
+		for _, criterion := range identifier.Policy.Criteria {
+			tokens, err := tokenizePolicyExpression(criterion)
+			if err != nil {
+				return false, err
+			}
+			for _, token := range tokens {
+				if token.kind == tokenID && token.value == org {
+					orgMatches = true
+				}
+			}
+		}
+	}
+	return orgMatches && addressMatches, nil
+}
+
+// addressPatternsOverlap checks if two addresses or address patterns match a common address
+func addressPatternsOverlap(pattern1 string, pattern2 string) bool {
+	return pattern1 == pattern2 || isPatternAndAddressMatch(pattern1, pattern2) || isPatternAndAddressMatch(pattern2, pattern1)
+}
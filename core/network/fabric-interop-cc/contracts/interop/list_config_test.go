@@ -0,0 +1,168 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// prepConfigPage mocks a page of records returned by GetStateByPartialCompositeKeyWithPagination
+func prepConfigPage(t *testing.T, chaincodeStub *mocks.ChaincodeStub, records ...interface{}) {
+	iterator := &mocks.StateQueryIterator{}
+	for i, record := range records {
+		recordBytes, err := json.Marshal(record)
+		require.NoError(t, err)
+		iterator.HasNextReturnsOnCall(i, true)
+		iterator.NextReturnsOnCall(i, &queryresult.KV{Value: recordBytes}, nil)
+	}
+	iterator.HasNextReturnsOnCall(len(records), false)
+	chaincodeStub.GetStateByPartialCompositeKeyWithPaginationReturns(iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(records)), Bookmark: "bookmark1"}, nil)
+}
+
+// getConfigPageSecurityDomains returns the security domains of the records in a page
+func getConfigPageSecurityDomains(t *testing.T, pageJSON string) []string {
+	var page ConfigPage
+	require.NoError(t, json.Unmarshal([]byte(pageJSON), &page))
+	require.Equal(t, "bookmark1", page.Bookmark)
+	securityDomains := []string{}
+	for _, record := range page.Records {
+		var securityDomain struct {
+			SecurityDomain string `json:"securityDomain"`
+		}
+		require.NoError(t, json.Unmarshal(record, &securityDomain))
+		securityDomains = append(securityDomains, securityDomain.SecurityDomain)
+	}
+	return securityDomains
+}
+
+func TestListMemberships(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	memberships := []interface{}{
+		&common.Membership{SecurityDomain: "network1", Members: map[string]*common.Member{"Org1MSP": {Value: "cert", Type: "ca"}}},
+		&common.Membership{SecurityDomain: "network2", Members: map[string]*common.Member{"Org2MSP": {Value: "cert", Type: "ca"}}},
+	}
+
+	prepConfigPage(t, chaincodeStub, memberships...)
+	pageJSON, err := interopcc.ListMemberships(ctx, "", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network1", "network2"}, getConfigPageSecurityDomains(t, pageJSON))
+	objectType, keys, pageSize, bookmark := chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	require.Equal(t, membershipObjectType, objectType)
+	require.Empty(t, keys)
+	require.Equal(t, int32(10), pageSize)
+	require.Equal(t, "", bookmark)
+
+	// Test: Filter by org
+	prepConfigPage(t, chaincodeStub, memberships...)
+	pageJSON, err = interopcc.ListMemberships(ctx, "Org2MSP", 10, "bookmark0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network2"}, getConfigPageSecurityDomains(t, pageJSON))
+
+	// Test: Records are read until the filtered page is full
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	for i, membership := range memberships {
+		membershipBytes, err := json.Marshal(membership)
+		require.NoError(t, err)
+		iterator := &mocks.StateQueryIterator{}
+		iterator.HasNextReturnsOnCall(0, true)
+		iterator.NextReturnsOnCall(0, &queryresult.KV{Value: membershipBytes}, nil)
+		chaincodeStub.GetStateByPartialCompositeKeyWithPaginationReturnsOnCall(i, iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: fmt.Sprintf("bookmark%d", i)}, nil)
+	}
+	pageJSON, err = interopcc.ListMemberships(ctx, "Org2MSP", 1, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network2"}, getConfigPageSecurityDomains(t, pageJSON))
+	require.Equal(t, 2, chaincodeStub.GetStateByPartialCompositeKeyWithPaginationCallCount())
+	_, _, pageSize, bookmark = chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(1)
+	require.Equal(t, int32(1), pageSize)
+	require.Equal(t, "bookmark0", bookmark)
+
+	// Test: Invalid page size
+	_, err = interopcc.ListMemberships(ctx, "", 1001, "")
+	require.EqualError(t, err, "Page size must be between 1 and 1000, found 1001")
+}
+
+func TestListAccessControlPolicies(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	policies := []interface{}{
+		&common.AccessControlPolicy{SecurityDomain: "network1", Rules: []*common.Rule{
+			{Principal: "Org1MSP", PrincipalType: "ca", Resource: "mychannel:simplestate:Read:*", Read: true},
+		}},
+		&common.AccessControlPolicy{SecurityDomain: "network2", Rules: []*common.Rule{
+			{Principal: "client", PrincipalType: "role", Org: "Org2MSP", Resource: "mychannel:simplestate:Read:a", Read: true},
+			{Principal: "cert", PrincipalType: "certificate", Resource: "otherchannel:asset:Read:b", Read: true},
+		}},
+	}
+
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err := interopcc.ListAccessControlPolicies(ctx, "", "", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network1", "network2"}, getConfigPageSecurityDomains(t, pageJSON))
+
+	// Test: Filter by org, in "ca" rules or restricting other rules
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListAccessControlPolicies(ctx, "Org2MSP", "", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network2"}, getConfigPageSecurityDomains(t, pageJSON))
+
+	// Test: Filter by address or address pattern
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListAccessControlPolicies(ctx, "", "mychannel:simplestate:Read:a", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network1", "network2"}, getConfigPageSecurityDomains(t, pageJSON))
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListAccessControlPolicies(ctx, "", "otherchannel:*", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network2"}, getConfigPageSecurityDomains(t, pageJSON))
+
+	// Test: Both filters must match
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListAccessControlPolicies(ctx, "Org1MSP", "otherchannel:*", 10, "")
+	require.NoError(t, err)
+	require.Empty(t, getConfigPageSecurityDomains(t, pageJSON))
+}
+
+func TestListVerificationPolicies(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	policies := []interface{}{
+		&common.VerificationPolicy{SecurityDomain: "network1", Identifiers: []*common.Identifier{
+			{Pattern: "mychannel:simplestate:Read:*", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP && (Org2MSP || count >= 2)"}}},
+		}},
+		&common.VerificationPolicy{SecurityDomain: "network2", Identifiers: []*common.Identifier{
+			{Pattern: "otherchannel:asset:Read:*", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org3MSP"}}},
+		}},
+	}
+
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err := interopcc.ListVerificationPolicies(ctx, "Org2MSP", "", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network1"}, getConfigPageSecurityDomains(t, pageJSON))
+	objectType, _, _, _ := chaincodeStub.GetStateByPartialCompositeKeyWithPaginationArgsForCall(0)
+	require.Equal(t, verificationPolicyObjectType, objectType)
+
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListVerificationPolicies(ctx, "", "otherchannel:asset:Read:b", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"network2"}, getConfigPageSecurityDomains(t, pageJSON))
+
+	// Test: The count keyword is not an org
+	prepConfigPage(t, chaincodeStub, policies...)
+	pageJSON, err = interopcc.ListVerificationPolicies(ctx, "count", "", 10, "")
+	require.NoError(t, err)
+	require.Empty(t, getConfigPageSecurityDomains(t, pageJSON))
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// local_functions contains the registry of interop chaincode functions that remote networks can query through
+// HandleExternalRequest
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+// localFunction describes an interop chaincode function that is exposed to remote networks
+type localFunction struct {
+	numArgs int
+	// validateArgs optionally restricts the arguments a requesting network may pass, e.g., to records it owns
+	validateArgs func(query *common.Query, args []string) error
+	handler      func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error)
+}
+
+// localFunctions are the interop chaincode functions that remote networks can query. A function is exposed by
+// adding it here; access to it is still subject to the access control policy for the requesting network.
+var localFunctions = map[string]localFunction{
+	"GetHTLCHash": {2, nil, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetHTLCHash(ctx, args[0], args[1])
+	}},
+	"GetHTLCHashByContractId": {1, nil, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetHTLCHashByContractId(ctx, args[0])
+	}},
+	"GetHTLCHashPreImage": {2, nil, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetHTLCHashPreImage(ctx, args[0], args[1])
+	}},
+	"GetHTLCHashPreImageByContractId": {1, nil, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetHTLCHashPreImageByContractId(ctx, args[0])
+	}},
+	"GetInvocationReceipt": {2, validateReceiptRequester, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetInvocationReceipt(ctx, args[0], args[1])
+	}},
+	"GetLocalMembership": {0, nil, func(s *SmartContract, ctx contractapi.TransactionContextInterface, args []string) (string, error) {
+		return s.GetLocalMembership(ctx)
+	}},
+}
+
+// validateReceiptRequester ensures that networks can only fetch the receipts of their own invocations
+func validateReceiptRequester(query *common.Query, args []string) error {
+	if args[0] != query.RequestingNetwork {
+		return fmt.Errorf("Network %s cannot fetch receipts of invocations by network %s", query.RequestingNetwork, args[0])
+	}
+	return nil
+}
+
+// queryLocalFunction looks up the function of a view address in the registry of exposed interop chaincode
+// functions, validates the arguments and calls it on behalf of the requesting network
+func queryLocalFunction(s *SmartContract, ctx contractapi.TransactionContextInterface, query *common.Query, viewAddress *FabricViewAddress) ([]byte, error) {
+	function, ok := localFunctions[viewAddress.CCFunc]
+	if !ok {
+		errorMessage := fmt.Sprintf("Given function %s can not be invoked in Interop Chaincode.", viewAddress.CCFunc)
+		log.Error(errorMessage)
+		return nil, errors.New(errorMessage)
+	}
+	if len(viewAddress.Args) != function.numArgs {
+		errorMessage := fmt.Sprintf("Recieved %d arguments instead of the required %d arguments.", len(viewAddress.Args), function.numArgs)
+		log.Error(errorMessage)
+		return nil, errors.New(errorMessage)
+	}
+	if function.validateArgs != nil {
+		err := function.validateArgs(query, viewAddress.Args)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+	}
+	resp, err := function.handler(s, ctx, viewAddress.Args)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	return []byte(resp), nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLocalFunction(t *testing.T) {
+	ctx, _, _, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	query := &common.Query{RequestingNetwork: "network1"}
+	err := interopcc.CreateLocalMembership(ctx, `{"securityDomain":"network2","members":{"Org2MSP":{"value":"cert","type":"ca"}}}`)
+	require.NoError(t, err)
+
+	// Test: Exposed function is called with the arguments of the view address
+	payload, err := queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "GetLocalMembership"})
+	require.NoError(t, err)
+	localMembership, err := interopcc.GetLocalMembership(ctx)
+	require.NoError(t, err)
+	require.Equal(t, localMembership, string(payload))
+
+	// Test: Functions that are not exposed are rejected uniformly
+	_, err = queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "DeleteMembership", Args: []string{"network1"}})
+	require.EqualError(t, err, "Given function DeleteMembership can not be invoked in Interop Chaincode.")
+
+	// Test: Arity is enforced for every exposed function
+	_, err = queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "GetHTLCHash", Args: []string{"assetcc"}})
+	require.EqualError(t, err, "Recieved 1 arguments instead of the required 2 arguments.")
+	_, err = queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "GetHTLCHashByContractId", Args: []string{"c1", "c2"}})
+	require.EqualError(t, err, "Recieved 2 arguments instead of the required 1 arguments.")
+
+	// Test: Arguments are validated against the query
+	_, err = queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "GetInvocationReceipt", Args: []string{"network2", "request1"}})
+	require.EqualError(t, err, "Network network1 cannot fetch receipts of invocations by network network2")
+	_, err = queryLocalFunction(&interopcc, ctx, query, &FabricViewAddress{Contract: "interopcc", CCFunc: "GetInvocationReceipt", Args: []string{"network1", "request1"}})
+	require.EqualError(t, err, "Invocation receipt with nonce hash: request1 does not exist for network: network1")
+}
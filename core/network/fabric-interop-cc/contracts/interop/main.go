@@ -10,15 +10,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	log "github.com/sirupsen/logrus"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/assetexchange"
 	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
 )
 
-const applicationCCKey = "applicationccid"
-
 // SmartContract provides functions for managing arbitrary key-value pairs
 type SmartContract struct {
 	contractapi.Contract
@@ -31,17 +31,27 @@ func init() {
 		log.SetLevel(log.DebugLevel)
 	}
 	log.SetOutput(os.Stdout)
+	// Optionally bound the skew between the timestamps of transactions and the clock of the endorsing peer
+	if toleranceSecs, ok := os.LookupEnv("FABRIC_INTEROP_CC_TX_TIME_SKEW_TOLERANCE_SECS"); ok {
+		tolerance, err := strconv.ParseUint(toleranceSecs, 10, 64)
+		if err != nil {
+			log.Errorf("Invalid transaction time skew tolerance %s: %s", toleranceSecs, err)
+		} else {
+			wutils.SetTxTimeSkewTolerance(tolerance)
+			assetexchange.SetTxTimeSkewTolerance(tolerance)
+		}
+	}
 }
 
 // InitLedger initilises ledger with data. Need the application chaincode id so the handleExtnernalRequest flow can
-// call the application chaincode.
+// call the application chaincode. A governance config may be passed as a second argument to enable governance.
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	var err error
 
 	_, args := ctx.GetStub().GetFunctionAndParameters()
 
-	if len(args) != 1 {
-		err = fmt.Errorf("Incorrect number of arguments. Expecting 1: {APPLICATION Chaincode ID/hash}. Found %d", len(args))
+	if len(args) != 1 && len(args) != 2 {
+		err = fmt.Errorf("Incorrect number of arguments. Expecting 1 or 2: {APPLICATION Chaincode ID/hash} and optionally {governance config}. Found %d", len(args))
 		fmt.Printf("Error %s", err.Error())
 		return err
 	}
@@ -53,6 +63,16 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 		return errors.New(errMsg)
 	}
 
+	// Optionally enable governance, which the orgs approving the chaincode definition thereby agree to
+	if len(args) == 2 {
+		err = bootstrapGovernance(ctx, args[1])
+		if err != nil {
+			errMsg := fmt.Sprintf("Error enabling governance: %s", err.Error())
+			fmt.Printf(errMsg)
+			return errors.New(errMsg)
+		}
+	}
+
 	// Infer local chaincode ID
 	localCCId, err := wutils.GetLocalChaincodeID(ctx.GetStub())
 	if err != nil {
@@ -78,22 +98,18 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 	return nil
 }
 
-// GetApplicationID retrieves the app CC id from the ledger
-func (s *SmartContract) GetApplicationID(ctx contractapi.TransactionContextInterface) (string, error) {
-	bytes, err := ctx.GetStub().GetState(applicationCCKey)
-	if err != nil {
-		return "", err
-	}
-	return string(bytes), nil
-}
-
 func main() {
-	chaincode, err := contractapi.NewChaincode(new(SmartContract))
+	// Direct invocations of governed functions are rejected once governance is enabled
+	contractChaincode, err := contractapi.NewChaincode(&SmartContract{
+		Contract: contractapi.Contract{BeforeTransaction: checkGovernedTransaction},
+	})
 
 	if err != nil {
 		fmt.Printf("Error creating Interop chaincode: %s", err.Error())
 		return
 	}
+	// Initialization transactions are marked so that only they can bootstrap governance
+	chaincode := &interopChaincode{contractChaincode}
 
 	_, ok := os.LookupEnv("EXTERNAL_SERVICE")
 	if ok {
@@ -61,6 +61,11 @@ func (s *SmartContract) LockAsset(ctx contractapi.TransactionContextInterface, a
 		return "", logThenErrorf(err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "LockAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -81,6 +86,11 @@ func (s *SmartContract) UnlockAsset(ctx contractapi.TransactionContextInterface,
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "UnlockAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -111,6 +121,11 @@ func (s *SmartContract) ClaimAsset(ctx contractapi.TransactionContextInterface,
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "ClaimAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -141,6 +156,11 @@ func (s *SmartContract) UnlockAssetUsingContractId(ctx contractapi.TransactionCo
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "UnlockAssetUsingContractId", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -171,6 +191,11 @@ func (s *SmartContract) ClaimAssetUsingContractId(ctx contractapi.TransactionCon
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "ClaimAssetUsingContractId", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -221,6 +246,11 @@ func (s *SmartContract) LockFungibleAsset(ctx contractapi.TransactionContextInte
 		return "", logThenErrorf(err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "LockFungibleAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -271,6 +301,11 @@ func (s *SmartContract) ClaimFungibleAsset(ctx contractapi.TransactionContextInt
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "ClaimFungibleAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -301,6 +336,11 @@ func (s *SmartContract) UnlockFungibleAsset(ctx contractapi.TransactionContextIn
 		return logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
 	}
 
+	err = recordAssetExchangeOperation(ctx, "UnlockFungibleAsset", contractId, callerChaincodeID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -86,6 +86,12 @@ func TestLockAsset(t *testing.T) {
 	// Test success with asset agreement specified properly
 	_, err := interopcc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
 	require.NoError(t, err)
+	eventName, eventBytes := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, assetexchange.LockAssetEventName, eventName)
+	var lockEvent assetexchange.AssetExchangeEvent
+	require.NoError(t, json.Unmarshal(eventBytes, &lockEvent))
+	require.Equal(t, assetId, lockEvent.Id)
+	require.Equal(t, recipient, lockEvent.Recipient)
 	fmt.Println("Test success as expected since the agreement and lock information are specified properly")
 
 	assetLockVal := assetexchange.AssetLockValue{Locker: locker, Recipient: recipient}
@@ -115,6 +121,23 @@ func TestLockAsset(t *testing.T) {
 	_, err = interopcc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
 	require.Error(t, err)
 	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	lockInfoHTLC = &common.AssetLockHTLC{
+		HashBase64:     []byte(hashBase64),
+		ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs,
+		TimeSpec:       common.AssetLockHTLC_EPOCH,
+		HashMechanism:  common.HashMechanism(7),
+	}
+	lockInfoHTLCBytes, _ = proto.Marshal(lockInfoHTLC)
+	lockInfo = &common.AssetLock{
+		LockMechanism: common.LockMechanism_HTLC,
+		LockInfo:      lockInfoHTLCBytes,
+	}
+	lockInfoBytes, _ = proto.Marshal(lockInfo)
+	// Test failure with a hash mechanism that is not supported
+	_, err = interopcc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "hash mechanism 7 is not supported")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
 }
 
 func TestUnlockAsset(t *testing.T) {
@@ -419,6 +442,71 @@ func TestClaimAsset(t *testing.T) {
 	log.Info(fmt.Println("Test failed as expected with error:", err))
 }
 
+func TestClaimAssetWithHashMechanism(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	recipient := getTxCreatorECertBase64()
+	locker := "Alice"
+	preimage := "abcd"
+	preimageBase64 := base64.StdEncoding.EncodeToString([]byte(preimage))
+	currentTimeSecs := uint64(time.Now().Unix())
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+
+	assetAgreement := &common.AssetExchangeAgreement{
+		Type:      "bond",
+		Id:        "A001",
+		Recipient: recipient,
+		Locker:    locker,
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashPreimageBase64: []byte(preimageBase64),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_HTLC,
+		ClaimInfo:     claimInfoHTLCBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+
+	// Keccak-256 is the original Keccak submission, which differs from the standardised SHA3-256
+	keccakHashBase64, err := assetexchange.GenerateHashInBase64Form(common.HashMechanism_KECCAK256, "")
+	require.NoError(t, err)
+	require.Equal(t, "xdJGAYb3IzySfn2y3McDwOUAtlPKgic7e/rYBF2FpHA=", keccakHashBase64)
+	sha3HashBase64, err := assetexchange.GenerateHashInBase64Form(common.HashMechanism_SHA3_256, "")
+	require.NoError(t, err)
+	require.NotEqual(t, keccakHashBase64, sha3HashBase64)
+	_, err = assetexchange.GenerateHashInBase64Form(common.HashMechanism(7), preimage)
+	require.EqualError(t, err, "hash mechanism 7 is not supported")
+
+	for _, hashMechanism := range []common.HashMechanism{common.HashMechanism_SHA512, common.HashMechanism_SHA3_256, common.HashMechanism_KECCAK256} {
+		hashBase64, err := assetexchange.GenerateHashInBase64Form(hashMechanism, preimage)
+		require.NoError(t, err)
+
+		hashLock := assetexchange.HashLock{HashBase64: hashBase64, HashMechanism: hashMechanism}
+		assetLockVal := assetexchange.AssetLockValue{Locker: locker, Recipient: recipient, LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+		assetLockValBytes, _ := json.Marshal(assetLockVal)
+		chaincodeStub.GetStateReturns(assetLockValBytes, nil)
+		// Test success with the preimage hashed using the hash mechanism of the lock
+		err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+		require.NoError(t, err)
+		log.Info(fmt.Println("Test success as expected since the preimage matches the", hashMechanism, "hash of the lock."))
+
+		hashLock = assetexchange.HashLock{HashBase64: hashBase64}
+		assetLockVal = assetexchange.AssetLockValue{Locker: locker, Recipient: recipient, LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+		assetLockValBytes, _ = json.Marshal(assetLockVal)
+		chaincodeStub.GetStateReturns(assetLockValBytes, nil)
+		// Test failure with the hash checked using the default SHA256 hash mechanism
+		err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+		require.Error(t, err)
+		log.Info(fmt.Println("Test failed as expected with error:", err))
+	}
+}
+
 func TestUnlockAssetUsingContractId(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	localCCId := "mycc"
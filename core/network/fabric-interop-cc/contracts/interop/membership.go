@@ -12,12 +12,39 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 const membershipObjectType = "membership"
 
+// membershipVersionObjectType is the object type of the versions of the Membership of a security domain
+const membershipVersionObjectType = "membershipVersion"
+
+// membershipConfigKey is the key of the MembershipConfig in the ledger
+const membershipConfigKey = "membershipConfig"
+
+// defaultMembershipVersionOverlapSecs is the version overlap used until a MembershipConfig is recorded
+const defaultMembershipVersionOverlapSecs = uint64(24 * 60 * 60)
+
+// ethereumAddressMemberType is the Member type for Besu validators, whose value is the validator's account address
+const ethereumAddressMemberType = "ethereum-address"
+
+// localMembershipKey is the key of the membership of the local network, which remote networks can fetch as a view
+// to synchronize their copy of it
+const localMembershipKey = "localMembership"
+
+// MembershipConfig holds the parameters used when recording new versions of a Membership.
+//
+// VersionOverlapSecs is the time for which a version of a Membership remains effective after the next version
+// takes effect, so that endorsements by members of either version are accepted while certificates are rotated.
+type MembershipConfig struct {
+	VersionOverlapSecs uint64 `json:"versionOverlapSecs"`
+}
+
 // CreateMembership cc is used to store a Membership in the ledger
 // TODO: Should we check here if certificates are valid
 func (s *SmartContract) CreateMembership(ctx contractapi.TransactionContextInterface, membershipJSON string) error {
@@ -25,6 +52,10 @@ func (s *SmartContract) CreateMembership(ctx contractapi.TransactionContextInter
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
+	err = validateMembership(membership)
+	if err != nil {
+		return err
+	}
 	membershipKey, err := ctx.GetStub().CreateCompositeKey(membershipObjectType, []string{membership.SecurityDomain})
 	acp, getErr := ctx.GetStub().GetState(membershipKey)
 	if getErr != nil {
@@ -34,31 +65,40 @@ func (s *SmartContract) CreateMembership(ctx contractapi.TransactionContextInter
 		return fmt.Errorf("Membership already exists for membership id: %s", membership.SecurityDomain)
 	}
 
-	membershipBytes, err := json.Marshal(membership)
+	membership.Version = 0
+	err = putMembership(ctx, membership)
 	if err != nil {
-		return fmt.Errorf("Marshal error: %s", err)
+		return err
 	}
-	return ctx.GetStub().PutState(membershipKey, membershipBytes)
+	return recordConfigChange(ctx, "CreateMembership", membership.SecurityDomain)
 }
 
-// UpdateMembership cc is used to update an existing Membership in the ledger
+// UpdateMembership cc is used to record a new version of an existing Membership in the ledger. The new version
+// takes effect at its effectiveFrom time, or immediately if it is not set, and the previous version remains
+// effective for an overlap window after that.
 func (s *SmartContract) UpdateMembership(ctx contractapi.TransactionContextInterface, membershipJSON string) error {
 	membership, err := decodeMembership([]byte(membershipJSON))
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
-	membershipKey, err := ctx.GetStub().CreateCompositeKey(membershipObjectType, []string{membership.SecurityDomain})
-	_, getErr := s.GetMembershipBySecurityDomain(ctx, membership.SecurityDomain)
+	previousMembershipString, getErr := s.GetMembershipBySecurityDomain(ctx, membership.SecurityDomain)
 	if getErr != nil {
 		return getErr
 	}
-
-	membershipBytes, err := json.Marshal(membership)
+	previousMembership, err := decodeMembership([]byte(previousMembershipString))
 	if err != nil {
-		return fmt.Errorf("Marshal error: %s", err)
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
 	}
-	return ctx.GetStub().PutState(membershipKey, membershipBytes)
 
+	config, err := getMembershipConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = addMembershipVersion(ctx, membership, previousMembership, config.VersionOverlapSecs)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateMembership", membership.SecurityDomain)
 }
 
 // DeleteMembership cc is used to delete an existing Membership in the ledger
@@ -71,12 +111,27 @@ func (s *SmartContract) DeleteMembership(ctx contractapi.TransactionContextInter
 	if bytes == nil {
 		return fmt.Errorf("Membership with id: %s does not exist", membershipID)
 	}
+	membership, err := decodeMembership(bytes)
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
 	err = ctx.GetStub().DelState(membershipKey)
 	if err != nil {
 		return fmt.Errorf("failed to delete asset %s: %v", membershipKey, err)
 	}
+	// The earlier versions are deleted too, so that none of them remain effective
+	for version := uint64(0); version <= membership.Version; version++ {
+		versionKey, err := getMembershipVersionKey(ctx, membershipID, version)
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(versionKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete asset %s: %v", versionKey, err)
+		}
+	}
 
-	return nil
+	return recordConfigChange(ctx, "DeleteMembership", membershipID)
 }
 
 // GetMembershipBySecurityDomain cc gets the Membership for the provided id
@@ -94,21 +149,452 @@ func (s *SmartContract) GetMembershipBySecurityDomain(ctx contractapi.Transactio
 
 }
 
-// verifyMemberInSecurityDomain function verifies the identity of the requester according to
-// the Membership for the external network the request originated from.
-func verifyMemberInSecurityDomain(s *SmartContract, ctx contractapi.TransactionContextInterface, cert *x509.Certificate, securityDomain string, requestingOrg string) error {
-	err := isCertificateWithinExpiry(cert)
+// GetMembershipHistory cc gets the versions of the Membership for the provided id as a JSON array, from the
+// earliest to the latest
+func (s *SmartContract) GetMembershipHistory(ctx contractapi.TransactionContextInterface, securityDomain string) (string, error) {
+	membershipString, err := s.GetMembershipBySecurityDomain(ctx, securityDomain)
+	if err != nil {
+		return "", err
+	}
+	membership, err := decodeMembership([]byte(membershipString))
+	if err != nil {
+		return "", fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	versions := []*common.Membership{}
+	for version := uint64(0); version < membership.Version; version++ {
+		earlierMembership, err := getMembershipVersion(ctx, securityDomain, version)
+		if err != nil {
+			return "", err
+		}
+		if earlierMembership != nil {
+			versions = append(versions, earlierMembership)
+		}
+	}
+	versions = append(versions, membership)
+	versionsBytes, err := json.Marshal(versions)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(versionsBytes), nil
+}
+
+// CreateLocalMembership cc is used to store the Membership of the local network in the ledger. Remote networks can
+// fetch it as a view through the GetLocalMembership function of the interop chaincode and use SyncMembership to
+// update their copy of it.
+func (s *SmartContract) CreateLocalMembership(ctx contractapi.TransactionContextInterface, membershipJSON string) error {
+	membership, err := decodeMembership([]byte(membershipJSON))
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	err = validateMembershipRevocations(membership)
+	if err != nil {
+		return err
+	}
+	bytes, err := ctx.GetStub().GetState(localMembershipKey)
 	if err != nil {
 		return err
 	}
+	if bytes != nil {
+		return fmt.Errorf("Local membership already exists")
+	}
+	membership.Sequence = 1
+	err = putLocalMembership(ctx, membership)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "CreateLocalMembership", localSecurityDomain)
+}
+
+// UpdateLocalMembership cc is used to update the Membership of the local network in the ledger. The sequence
+// number of the membership is incremented, so that remote networks can tell it apart from earlier versions.
+func (s *SmartContract) UpdateLocalMembership(ctx contractapi.TransactionContextInterface, membershipJSON string) error {
+	membership, err := decodeMembership([]byte(membershipJSON))
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	err = validateMembershipRevocations(membership)
+	if err != nil {
+		return err
+	}
+	currentMembershipString, err := s.GetLocalMembership(ctx)
+	if err != nil {
+		return err
+	}
+	currentMembership, err := decodeMembership([]byte(currentMembershipString))
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	membership.Sequence = currentMembership.Sequence + 1
+	err = putLocalMembership(ctx, membership)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateLocalMembership", localSecurityDomain)
+}
+
+// GetLocalMembership cc gets the Membership of the local network
+func (s *SmartContract) GetLocalMembership(ctx contractapi.TransactionContextInterface) (string, error) {
+	bytes, err := ctx.GetStub().GetState(localMembershipKey)
+	if err != nil {
+		return "", err
+	}
+	if bytes == nil {
+		return "", fmt.Errorf("Local membership does not exist")
+	}
+	return string(bytes), nil
+}
+
+// PublishMemberCRL cc is used to add a certificate revocation list to a member of an existing Membership.
+// The CRL must be signed by one of the member's certificate authorities, and replaces any earlier CRL from the same issuer.
+// The CRL is recorded in a new version of the Membership, which replaces the previous versions right away.
+func (s *SmartContract) PublishMemberCRL(ctx contractapi.TransactionContextInterface, securityDomain string, memberID string, crlPEM string) error {
+	crl, err := parseCRL(crlPEM)
+	if err != nil {
+		return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+	}
+	err = addMemberRevocationVersion(s, ctx, securityDomain, memberID, func(member *common.Member) error {
+		err := verifyCRLIssuedByMember(crl, member)
+		if err != nil {
+			return err
+		}
+		issuer := getCRLIssuer(crl)
+		crls := []string{}
+		for _, existingPEM := range member.Crls {
+			existingCRL, err := parseCRL(existingPEM)
+			if err != nil {
+				return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+			}
+			if getCRLIssuer(existingCRL) != issuer {
+				crls = append(crls, existingPEM)
+				continue
+			}
+			if crl.TBSCertList.ThisUpdate.Before(existingCRL.TBSCertList.ThisUpdate) {
+				return fmt.Errorf("CRL issued by %s is older than the published CRL", issuer)
+			}
+		}
+		member.Crls = append(crls, crlPEM)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "PublishMemberCRL", securityDomain)
+}
+
+// UpdateMemberRevokedSerials cc is used to replace the list of revoked certificate serial numbers (in hex)
+// of a member of an existing Membership. The list is supplied as a JSON array of strings, and is recorded in
+// a new version of the Membership, which replaces the previous versions right away.
+func (s *SmartContract) UpdateMemberRevokedSerials(ctx contractapi.TransactionContextInterface, securityDomain string, memberID string, revokedSerialsJSON string) error {
+	var revokedSerials []string
+	err := json.Unmarshal([]byte(revokedSerialsJSON), &revokedSerials)
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	for _, serial := range revokedSerials {
+		_, err := parseCertificateSerial(serial)
+		if err != nil {
+			return err
+		}
+	}
+	err = addMemberRevocationVersion(s, ctx, securityDomain, memberID, func(member *common.Member) error {
+		member.RevokedSerials = revokedSerials
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateMemberRevokedSerials", securityDomain)
+}
+
+// SetMembershipConfig cc is used to store the parameters used when recording new versions of a Membership
+func (s *SmartContract) SetMembershipConfig(ctx contractapi.TransactionContextInterface, configJSON string) error {
+	config, err := decodeMembershipConfig([]byte(configJSON))
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().PutState(membershipConfigKey, configBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "SetMembershipConfig", localSecurityDomain)
+}
+
+// GetMembershipConfig cc returns the Membership parameters in effect, which are the defaults if none have been
+// recorded in the ledger
+func (s *SmartContract) GetMembershipConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := getMembershipConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(configBytes), nil
+}
+
+func getMembershipConfig(ctx contractapi.TransactionContextInterface) (*MembershipConfig, error) {
+	configBytes, err := ctx.GetStub().GetState(membershipConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if configBytes == nil {
+		return &MembershipConfig{VersionOverlapSecs: defaultMembershipVersionOverlapSecs}, nil
+	}
+	config, err := decodeMembershipConfig(configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal error: %s", err)
+	}
+	return config, nil
+}
+
+// addMemberRevocationVersion records a new version of a Membership in which a member's revocation information
+// has been changed by update. The new version takes effect at the transaction time without any overlap, so that
+// no earlier version can be used to accept a revoked certificate.
+func addMemberRevocationVersion(s *SmartContract, ctx contractapi.TransactionContextInterface, securityDomain string, memberID string, update func(member *common.Member) error) error {
 	membershipString, err := s.GetMembershipBySecurityDomain(ctx, securityDomain)
 	if err != nil {
 		return err
 	}
+	previousMembership, err := decodeMembership([]byte(membershipString))
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
 	membership, err := decodeMembership([]byte(membershipString))
 	if err != nil {
 		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
 	}
+	member, ok := membership.Members[memberID]
+	if !ok {
+		return fmt.Errorf("Member does not exist for org: %s", memberID)
+	}
+	err = update(member)
+	if err != nil {
+		return err
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	if previousMembership.EffectiveFrom > uint64(txTimeSecs) {
+		return fmt.Errorf("Membership for %s does not take effect until %d, so revocations cannot be recorded yet", securityDomain, previousMembership.EffectiveFrom)
+	}
+	membership.EffectiveFrom = uint64(txTimeSecs)
+	return addMembershipVersion(ctx, membership, previousMembership, 0)
+}
+
+// putMembership records a Membership in the ledger as the latest version for its security domain
+func putMembership(ctx contractapi.TransactionContextInterface, membership *common.Membership) error {
+	membershipKey, err := ctx.GetStub().CreateCompositeKey(membershipObjectType, []string{membership.SecurityDomain})
+	if err != nil {
+		return err
+	}
+	membershipBytes, err := json.Marshal(membership)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().PutState(membershipKey, membershipBytes)
+	if err != nil {
+		return err
+	}
+	return putMembershipVersion(ctx, membership)
+}
+
+// getMembershipVersionKey returns the key of a version of the Membership of a security domain
+func getMembershipVersionKey(ctx contractapi.TransactionContextInterface, securityDomain string, version uint64) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(membershipVersionObjectType, []string{securityDomain, strconv.FormatUint(version, 10)})
+}
+
+// putMembershipVersion records a version of a Membership in the ledger
+func putMembershipVersion(ctx contractapi.TransactionContextInterface, membership *common.Membership) error {
+	versionKey, err := getMembershipVersionKey(ctx, membership.SecurityDomain, membership.Version)
+	if err != nil {
+		return err
+	}
+	membershipBytes, err := json.Marshal(membership)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	return ctx.GetStub().PutState(versionKey, membershipBytes)
+}
+
+// getMembershipVersion looks up a version of the Membership of a security domain, returning nil if it does not exist
+func getMembershipVersion(ctx contractapi.TransactionContextInterface, securityDomain string, version uint64) (*common.Membership, error) {
+	versionKey, err := getMembershipVersionKey(ctx, securityDomain, version)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := ctx.GetStub().GetState(versionKey)
+	if err != nil {
+		return nil, err
+	}
+	if bytes == nil {
+		return nil, nil
+	}
+	membership, err := decodeMembership(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	return membership, nil
+}
+
+// addMembershipVersion records a Membership as the version following the previous one for its security domain.
+// The new version takes effect at the transaction time unless its effectiveFrom is set, and the previous version
+// stops being effective at most overlapSecs after that. Earlier versions that would remain effective for longer
+// are cut short too, so that every version stops being effective no later than the version following it.
+func addMembershipVersion(ctx contractapi.TransactionContextInterface, membership *common.Membership, previousMembership *common.Membership, overlapSecs uint64) error {
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	if membership.EffectiveFrom == 0 {
+		membership.EffectiveFrom = uint64(txTimeSecs)
+	}
+	if membership.EffectiveFrom < previousMembership.EffectiveFrom {
+		return fmt.Errorf("Membership for %s cannot take effect before the current version, which is effective from %d", membership.SecurityDomain, previousMembership.EffectiveFrom)
+	}
+	err = validateMembership(membership)
+	if err != nil {
+		return err
+	}
+	overlapUntil := membership.EffectiveFrom + overlapSecs
+	if previousMembership.EffectiveUntil == 0 || previousMembership.EffectiveUntil > overlapUntil {
+		previousMembership.EffectiveUntil = overlapUntil
+	}
+	err = putMembershipVersion(ctx, previousMembership)
+	if err != nil {
+		return err
+	}
+	for version := previousMembership.Version; version > 0; version-- {
+		earlierMembership, err := getMembershipVersion(ctx, membership.SecurityDomain, version-1)
+		if err != nil {
+			return err
+		}
+		if earlierMembership == nil || earlierMembership.EffectiveUntil <= previousMembership.EffectiveUntil {
+			break
+		}
+		earlierMembership.EffectiveUntil = previousMembership.EffectiveUntil
+		err = putMembershipVersion(ctx, earlierMembership)
+		if err != nil {
+			return err
+		}
+	}
+	membership.Version = previousMembership.Version + 1
+	return putMembership(ctx, membership)
+}
+
+// isMembershipEffective checks if a version of a Membership is effective at a time (seconds since epoch)
+func isMembershipEffective(membership *common.Membership, timeSecs int64) bool {
+	return uint64(timeSecs) >= membership.EffectiveFrom && (membership.EffectiveUntil == 0 || uint64(timeSecs) < membership.EffectiveUntil)
+}
+
+// verifyEffectiveMembership looks up the Membership of a security domain and calls verify with each of its versions
+// that is effective at the transaction time, from the latest to the earliest, until one of them is accepted. The
+// latest version is returned, along with the error for the latest effective version if none is accepted.
+func verifyEffectiveMembership(s *SmartContract, ctx contractapi.TransactionContextInterface, securityDomain string, verify func(membership *common.Membership) error) (*common.Membership, error) {
+	membershipString, err := s.GetMembershipBySecurityDomain(ctx, securityDomain)
+	if err != nil {
+		return nil, err
+	}
+	latestMembership, err := decodeMembership([]byte(membershipString))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var verifyErr error
+	membership := latestMembership
+	for {
+		if isMembershipEffective(membership, txTimeSecs) {
+			err = verify(membership)
+			if err == nil {
+				return latestMembership, nil
+			}
+			if verifyErr == nil {
+				verifyErr = err
+			}
+		}
+		if membership.Version == 0 {
+			break
+		}
+		membership, err = getMembershipVersion(ctx, securityDomain, membership.Version-1)
+		if err != nil {
+			return nil, err
+		}
+		// Every version stops being effective no later than the version following it, so once a version is
+		// no longer effective none of the earlier ones are
+		if membership == nil || (membership.EffectiveUntil != 0 && int64(membership.EffectiveUntil) <= txTimeSecs) {
+			break
+		}
+	}
+	if verifyErr == nil {
+		verifyErr = fmt.Errorf("No version of the membership of %s is effective at %d", securityDomain, txTimeSecs)
+	}
+	return latestMembership, verifyErr
+}
+
+// putLocalMembership records the Membership of the local network in the ledger
+func putLocalMembership(ctx contractapi.TransactionContextInterface, membership *common.Membership) error {
+	membershipBytes, err := json.Marshal(membership)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	return ctx.GetStub().PutState(localMembershipKey, membershipBytes)
+}
+
+// validateMembership checks the effective times of a Membership and the revocation information of its members
+func validateMembership(membership *common.Membership) error {
+	if membership.EffectiveUntil != 0 && membership.EffectiveUntil <= membership.EffectiveFrom {
+		return fmt.Errorf("Invalid effective time for membership of %s: effectiveFrom must be earlier than effectiveUntil", membership.SecurityDomain)
+	}
+	return validateMembershipRevocations(membership)
+}
+
+// validateMembershipRevocations checks the revocation information of every member of a Membership
+func validateMembershipRevocations(membership *common.Membership) error {
+	for memberID, member := range membership.Members {
+		err := validateMemberRevocations(member)
+		if err != nil {
+			return fmt.Errorf("Invalid revocation information for member %s: %s", memberID, err.Error())
+		}
+	}
+	return nil
+}
+
+// verifyMemberInSecurityDomain function verifies the identity of the requester according to
+// the Membership for the external network the request originated from. Any version of the Membership that is
+// effective at the transaction time can vouch for the requester, while revocations recorded in the latest version
+// apply whichever version does.
+func verifyMemberInSecurityDomain(s *SmartContract, ctx contractapi.TransactionContextInterface, cert *x509.Certificate, securityDomain string, requestingOrg string) error {
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = isCertificateWithinExpiry(cert, txTime)
+	if err != nil {
+		return err
+	}
+	latestMembership, err := verifyEffectiveMembership(s, ctx, securityDomain, func(membership *common.Membership) error {
+		return verifyCertificateInMembership(cert, membership, requestingOrg, txTime)
+	})
+	if err != nil {
+		return err
+	}
+	if latestMember, ok := latestMembership.Members[requestingOrg]; ok {
+		return verifyCertificateNotRevoked(cert, latestMember)
+	}
+	return nil
+}
+
+// verifyCertificateInMembership verifies a certificate against the entry of an org in a version of a Membership
+// at the transaction time
+func verifyCertificateInMembership(cert *x509.Certificate, membership *common.Membership, requestingOrg string, txTime time.Time) error {
 	member, ok := membership.Members[requestingOrg]
 	if ok == false {
 		return fmt.Errorf("Member does not exist for org: %s", requestingOrg)
@@ -116,7 +602,7 @@ func verifyMemberInSecurityDomain(s *SmartContract, ctx contractapi.TransactionC
 	switch member.Type {
 	case "ca":
 		// TODO: Add check for if cert and member.Value are the same verifyCaCertificate(cert, member.Value, true)
-		err := verifyCaCertificate(cert, member.Value)
+		err := verifyCaCertificate(cert, member.Value, txTime)
 		if err != nil {
 			return err
 		}
@@ -125,12 +611,32 @@ func verifyMemberInSecurityDomain(s *SmartContract, ctx contractapi.TransactionC
 		if len(chain) == 0 {
 			chain = []string{member.Value}
 		}
-		err := verifyCertificateChain(cert, chain)
+		err := verifyCertificateChain(cert, chain, txTime)
 		if err != nil {
 			return err
 		}
 	default:
 		return fmt.Errorf("Certificate type not supported: %s", member.Type)
 	}
-	return nil
+	return verifyCertificateNotRevoked(cert, member)
+}
+
+// verifyValidatorInSecurityDomain function verifies that the Ethereum address recovered from a validator's
+// signature matches the validator's entry in a version of the Membership for the Besu network the view came from
+// that is effective at the transaction time.
+func verifyValidatorInSecurityDomain(s *SmartContract, ctx contractapi.TransactionContextInterface, validatorAddress string, securityDomain string, validatorID string) error {
+	_, err := verifyEffectiveMembership(s, ctx, securityDomain, func(membership *common.Membership) error {
+		member, ok := membership.Members[validatorID]
+		if ok == false {
+			return fmt.Errorf("Member does not exist for validator: %s", validatorID)
+		}
+		if member.Type != ethereumAddressMemberType {
+			return fmt.Errorf("Member type not supported for validator: %s", member.Type)
+		}
+		if normalizeEthereumAddress(member.Value) != normalizeEthereumAddress(validatorAddress) {
+			return fmt.Errorf("Validator address 0x%s does not match member address %s", normalizeEthereumAddress(validatorAddress), member.Value)
+		}
+		return nil
+	})
+	return err
 }
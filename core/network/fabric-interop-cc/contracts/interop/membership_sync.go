@@ -0,0 +1,180 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// membership_sync contains the chaincode function to update the Membership of a remote network from a view of
+// the membership that network publishes, following the identity syncing protocol
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+// membershipViewFunction is the interop chaincode function through which a network exposes its own membership
+const membershipViewFunction = "GetLocalMembership"
+
+// SyncMembership cc updates the stored Membership of a remote network with the membership in a view obtained from
+// that network through the GetLocalMembership function of its interop chaincode.
+//
+// The flow coordinates the following:
+// 1. Checks that the address refers to the membership exposed by the interop chaincode of the remote network, as
+//    recorded in the stored Membership, since any other chaincode could expose a function of the same name
+// 2. Verifies the proof in the view against the effective versions of the stored Membership and the verification
+//    policy for the address, so that the new membership is only accepted if it is attested by currently trusted members
+// 3. Checks that the new membership is later than the stored one, so that stale views cannot roll it back
+// 4. Keeps the revocations recorded locally for members that remain in the new membership, and makes sure the new
+//    membership does not take effect before the transaction time
+// 5. Records the new membership as the next version of the membership of the security domain of the address
+func (s *SmartContract) SyncMembership(ctx contractapi.TransactionContextInterface, address string, b64ViewProto string) error {
+	// 1. Checks that the address refers to the membership exposed by the interop chaincode of the remote network
+	addressStruct, err := parseAddress(address)
+	if err != nil {
+		return fmt.Errorf("Unable to parse address: %s", err.Error())
+	}
+	securityDomain := addressStruct.LedgerSegment
+	viewAddress, err := parseFabricViewAddress(addressStruct.ViewSegment)
+	if err != nil || viewAddress.CCFunc != membershipViewFunction || len(viewAddress.Args) != 0 {
+		errorMessage := fmt.Sprintf("Address %s does not refer to the membership of network %s", address, securityDomain)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	currentMembership, err := s.GetMembershipBySecurityDomain(ctx, securityDomain)
+	if err != nil {
+		return err
+	}
+	previousMembership, err := decodeMembership([]byte(currentMembership))
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	if previousMembership.InteropContract == "" {
+		errorMessage := fmt.Sprintf("Interop contract of network %s is not recorded in its membership", securityDomain)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	if viewAddress.Contract != previousMembership.InteropContract {
+		errorMessage := fmt.Sprintf("Address %s does not refer to the interop contract %s of network %s", address, previousMembership.InteropContract, securityDomain)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+
+	// 2. Verifies the proof in the view against the effective versions of the stored Membership
+	viewData, err := s.ParseAndValidateView(ctx, address, b64ViewProto)
+	if err != nil {
+		return err
+	}
+	membership, err := decodeMembership(viewData)
+	if err != nil {
+		return fmt.Errorf("Unable to decode membership in view: %s", err)
+	}
+
+	// 3. Checks that the new membership is later than the stored one
+	if membership.Sequence <= previousMembership.Sequence {
+		errorMessage := fmt.Sprintf("Membership sequence %d in view is not later than sequence %d of the membership of network %s", membership.Sequence, previousMembership.Sequence, securityDomain)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+
+	// 4. Keeps the local revocations and makes sure the new membership does not take effect before the transaction time
+	for memberID, member := range membership.Members {
+		previousMember, ok := previousMembership.Members[memberID]
+		if !ok {
+			continue
+		}
+		err = mergeMemberRevocations(member, previousMember)
+		if err != nil {
+			return err
+		}
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	if membership.EffectiveFrom < uint64(txTimeSecs) {
+		membership.EffectiveFrom = uint64(txTimeSecs)
+	}
+	if membership.EffectiveUntil != 0 && membership.EffectiveUntil <= membership.EffectiveFrom {
+		errorMessage := fmt.Sprintf("Membership in view for network %s is no longer effective at %d", securityDomain, membership.EffectiveFrom)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+
+	// 5. Records the new membership as the next version of the membership of the security domain of the address
+	membership.SecurityDomain = securityDomain
+	membership.InteropContract = previousMembership.InteropContract
+	config, err := getMembershipConfig(ctx)
+	if err != nil {
+		return err
+	}
+	err = addMembershipVersion(ctx, membership, previousMembership, config.VersionOverlapSecs)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "SyncMembership", securityDomain)
+}
+
+// mergeMemberRevocations adds the revocations recorded locally for a member to the member in a synchronized
+// membership. Local CRLs are kept if they are signed by one of the member's certificate authorities and are not
+// older than the CRL from the same issuer in the synchronized membership. Local revoked serials are always kept.
+func mergeMemberRevocations(member *common.Member, localMember *common.Member) error {
+	crls := []string{}
+	crlUpdates := map[string]time.Time{}
+	crlIndexes := map[string]int{}
+	for _, crlPEM := range member.Crls {
+		crl, err := parseCRL(crlPEM)
+		if err != nil {
+			return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+		}
+		issuer := getCRLIssuer(crl)
+		crlUpdates[issuer] = crl.TBSCertList.ThisUpdate
+		crlIndexes[issuer] = len(crls)
+		crls = append(crls, crlPEM)
+	}
+	for _, crlPEM := range localMember.Crls {
+		crl, err := parseCRL(crlPEM)
+		if err != nil {
+			return fmt.Errorf("Unable to parse CRL: %s", err.Error())
+		}
+		if verifyCRLIssuedByMember(crl, member) != nil {
+			continue
+		}
+		issuer := getCRLIssuer(crl)
+		index, ok := crlIndexes[issuer]
+		if !ok {
+			crlUpdates[issuer] = crl.TBSCertList.ThisUpdate
+			crlIndexes[issuer] = len(crls)
+			crls = append(crls, crlPEM)
+		} else if crl.TBSCertList.ThisUpdate.After(crlUpdates[issuer]) {
+			crlUpdates[issuer] = crl.TBSCertList.ThisUpdate
+			crls[index] = crlPEM
+		}
+	}
+	member.Crls = crls
+
+	revokedSerials := map[string]bool{}
+	for _, serial := range member.RevokedSerials {
+		serialNumber, err := parseCertificateSerial(serial)
+		if err != nil {
+			return err
+		}
+		revokedSerials[serialNumber.String()] = true
+	}
+	for _, serial := range localMember.RevokedSerials {
+		serialNumber, err := parseCertificateSerial(serial)
+		if err != nil {
+			return err
+		}
+		if !revokedSerials[serialNumber.String()] {
+			revokedSerials[serialNumber.String()] = true
+			member.RevokedSerials = append(member.RevokedSerials, serial)
+		}
+	}
+	return nil
+}
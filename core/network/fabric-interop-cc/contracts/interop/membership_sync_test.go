@@ -0,0 +1,268 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+type fabricTestEndorser struct {
+	mspID   string
+	key     *ecdsa.PrivateKey
+	certPEM string
+}
+
+func createFabricTestEndorser(t *testing.T, ca *revocationTestCA, mspID string) *fabricTestEndorser {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		Subject:      pkix.Name{CommonName: "peer0." + mspID},
+		NotBefore:    testCertNotBefore,
+		NotAfter:     testCertNotAfter,
+		SerialNumber: big.NewInt(0x1340),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, ca.caCert, &key.PublicKey, ca.caKey)
+	require.NoError(t, err)
+	return &fabricTestEndorser{mspID: mspID, key: key, certPEM: encodePEM("CERTIFICATE", certDER)}
+}
+
+// createFabricView creates a Fabric view with the payload endorsed by each of the endorsers
+func createFabricView(t *testing.T, address string, payload []byte, endorsers ...*fabricTestEndorser) string {
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: address, Payload: payload})
+	require.NoError(t, err)
+	chaincodeActionBytes, err := proto.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: interopPayloadBytes}})
+	require.NoError(t, err)
+	proposalResponsePayload := &peer.ProposalResponsePayload{ProposalHash: []byte("hash"), Extension: chaincodeActionBytes}
+	proposalResponsePayloadBytes, err := proto.Marshal(proposalResponsePayload)
+	require.NoError(t, err)
+	fabricView := &fabric.FabricView{
+		Response:                &peer.Response{Status: 200, Payload: interopPayloadBytes},
+		ProposalResponsePayload: proposalResponsePayload,
+	}
+	for _, endorser := range endorsers {
+		endorserBytes, err := proto.Marshal(&msp.SerializedIdentity{Mspid: endorser.mspID, IdBytes: []byte(endorser.certPEM)})
+		require.NoError(t, err)
+		hashed := sha256.Sum256(append(proposalResponsePayloadBytes, endorserBytes...))
+		signature, err := ecdsa.SignASN1(rand.Reader, endorser.key, hashed[:])
+		require.NoError(t, err)
+		fabricView.Endorsements = append(fabricView.Endorsements, &peer.Endorsement{Endorser: endorserBytes, Signature: signature})
+	}
+	fabricViewBytes, err := protoV2.Marshal(fabricView)
+	require.NoError(t, err)
+	viewBytes, err := protoV2.Marshal(&common.View{
+		Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: "Notarization", SerializationFormat: "STRING"},
+		Data: fabricViewBytes,
+	})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(viewBytes)
+}
+
+func TestSyncMembership(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	membershipAddress := "relay-network2:9080/network2/mychannel:interop:GetLocalMembership"
+	oldCA := createRevocationTestCA(t)
+	newCA := createRevocationTestCA(t)
+	oldEndorser := createFabricTestEndorser(t, oldCA, "Org1MSP")
+	newEndorser := createFabricTestEndorser(t, newCA, "Org1MSP")
+	org2CA := createRevocationTestCA(t)
+	org2CRL := org2CA.createCRL(t, time.Now(), 0x1)
+
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network2",
+		Members: map[string]*common.Member{
+			"Org1MSP": {Value: oldCA.caPEM, Type: "ca", Crls: []string{oldCA.createCRL(t, time.Now(), 0x1)}, RevokedSerials: []string{"0x2a"}},
+			"Org2MSP": {Value: org2CA.caPEM, Type: "ca", Crls: []string{org2CRL}},
+		},
+		InteropContract: "interop",
+	})
+	require.NoError(t, err)
+	worldState["membership:network2"] = membershipBytes
+	verificationPolicyBytes, err := json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: "network2",
+		Identifiers: []*common.Identifier{{
+			Pattern: "mychannel:interop:GetLocalMembership",
+			Policy:  &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP"}},
+		}},
+	})
+	require.NoError(t, err)
+	worldState["verificationPolicy:network2"] = verificationPolicyBytes
+	rotatedMembershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "local-network2",
+		Members: map[string]*common.Member{
+			"Org1MSP": {Value: newCA.caPEM, Type: "ca", RevokedSerials: []string{"2a", "0x2b"}},
+			"Org2MSP": {Value: org2CA.caPEM, Type: "ca"},
+		},
+		Sequence:        2,
+		InteropContract: "othercc",
+		EffectiveFrom:   10,
+	})
+	require.NoError(t, err)
+
+	// Test: Membership rotated by a view endorsed by the currently trusted members
+	err = interopcc.SyncMembership(ctx, membershipAddress, createFabricView(t, membershipAddress, rotatedMembershipBytes, oldEndorser))
+	require.NoError(t, err)
+	membershipString, err := interopcc.GetMembershipBySecurityDomain(ctx, "network2")
+	require.NoError(t, err)
+	membership, err := decodeMembership([]byte(membershipString))
+	require.NoError(t, err)
+	require.Equal(t, "network2", membership.SecurityDomain)
+	require.Equal(t, newCA.caPEM, membership.Members["Org1MSP"].Value)
+	require.Equal(t, uint64(2), membership.Sequence)
+	require.Equal(t, "interop", membership.InteropContract)
+	require.Equal(t, uint64(1), membership.Version)
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(txTimeSecs), membership.EffectiveFrom)
+
+	// Test: Local revocations are kept, except for CRLs of certificate authorities no longer in the membership
+	require.Empty(t, membership.Members["Org1MSP"].Crls)
+	require.Equal(t, []string{"2a", "0x2b"}, membership.Members["Org1MSP"].RevokedSerials)
+	require.Equal(t, []string{org2CRL}, membership.Members["Org2MSP"].Crls)
+
+	// Test: Stale membership cannot roll back the stored one, even if endorsed by members of the previous version
+	// during the overlap window
+	err = interopcc.SyncMembership(ctx, membershipAddress, createFabricView(t, membershipAddress, rotatedMembershipBytes, newEndorser))
+	require.EqualError(t, err, "Membership sequence 2 in view is not later than sequence 2 of the membership of network network2")
+	err = interopcc.SyncMembership(ctx, membershipAddress, createFabricView(t, membershipAddress, rotatedMembershipBytes, oldEndorser))
+	require.EqualError(t, err, "Membership sequence 2 in view is not later than sequence 2 of the membership of network network2")
+
+	// Test: Membership that is no longer effective is rejected
+	expiredMembershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "local-network2",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: newCA.caPEM, Type: "ca"}},
+		Sequence:       3,
+		EffectiveUntil: 20,
+	})
+	require.NoError(t, err)
+	err = interopcc.SyncMembership(ctx, membershipAddress, createFabricView(t, membershipAddress, expiredMembershipBytes, newEndorser))
+	require.EqualError(t, err, fmt.Sprintf("Membership in view for network network2 is no longer effective at %d", txTimeSecs))
+
+	// Test: Members that are no longer trusted cannot change the membership
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: time.Now().Unix() + int64(defaultMembershipVersionOverlapSecs) + 1}, nil)
+	err = interopcc.SyncMembership(ctx, membershipAddress, createFabricView(t, membershipAddress, rotatedMembershipBytes, oldEndorser))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Verify membership failed")
+
+	// Test: Address does not refer to the membership of the network
+	dataAddress := "relay-network2:9080/network2/mychannel:simplestate:Read:a"
+	err = interopcc.SyncMembership(ctx, dataAddress, createFabricView(t, dataAddress, rotatedMembershipBytes, newEndorser))
+	require.EqualError(t, err, "Address "+dataAddress+" does not refer to the membership of network network2")
+
+	// Test: Membership exposed by a chaincode other than the interop chaincode of the network is rejected, even if
+	// the view satisfies the verification policy
+	otherAddress := "relay-network2:9080/network2/mychannel:othercc:GetLocalMembership"
+	worldState["verificationPolicy:network2"], err = json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: "network2",
+		Identifiers: []*common.Identifier{{
+			Pattern: "mychannel:*:GetLocalMembership",
+			Policy:  &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP"}},
+		}},
+	})
+	require.NoError(t, err)
+	err = interopcc.SyncMembership(ctx, otherAddress, createFabricView(t, otherAddress, rotatedMembershipBytes, newEndorser))
+	require.EqualError(t, err, "Address "+otherAddress+" does not refer to the interop contract interop of network network2")
+
+	// Test: Membership cannot be synchronized until the interop contract of the network is recorded
+	membershipBytes, err = json.Marshal(&common.Membership{
+		SecurityDomain: "network4",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: newCA.caPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	worldState["membership:network4"] = membershipBytes
+	network4Address := "relay-network4:9080/network4/mychannel:interop:GetLocalMembership"
+	err = interopcc.SyncMembership(ctx, network4Address, createFabricView(t, network4Address, rotatedMembershipBytes, newEndorser))
+	require.EqualError(t, err, "Interop contract of network network4 is not recorded in its membership")
+
+	// Test: Initial membership must be recorded before it can be synchronized
+	unknownAddress := "relay-network3:9080/network3/mychannel:interop:GetLocalMembership"
+	err = interopcc.SyncMembership(ctx, unknownAddress, createFabricView(t, unknownAddress, rotatedMembershipBytes, newEndorser))
+	require.EqualError(t, err, "Membership with id: network3 does not exist")
+}
+
+func TestHandleExternalRequestLocalMembership(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+	chaincodeStub.GetChannelIDReturns("mychannel")
+
+	ca := createRevocationTestCA(t)
+	requester := createFabricTestEndorser(t, ca, "Org1MSP")
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: ca.caPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	worldState["membership:network1"] = membershipBytes
+	acpBytes, err := json.Marshal(&common.AccessControlPolicy{
+		SecurityDomain: "network1",
+		Rules:          []*common.Rule{{Principal: "Org1MSP", PrincipalType: "ca", Resource: "mychannel:interopcc:GetLocalMembership", Read: true}},
+	})
+	require.NoError(t, err)
+	worldState["accessControl:network1"] = acpBytes
+	err = interopcc.CreateLocalMembership(ctx, `{"securityDomain":"network2","members":{"Org2MSP":{"value":"cert","type":"ca"}}}`)
+	require.NoError(t, err)
+
+	createQuery := func(address string, nonce string) string {
+		query := common.Query{
+			Address:           address,
+			RequestingRelay:   "network1-relay",
+			RequestingNetwork: "network1",
+			RequestingOrg:     "Org1MSP",
+			Certificate:       requester.certPEM,
+			Nonce:             nonce,
+		}
+		hashed := sha256.Sum256([]byte(query.Address + query.Nonce))
+		signature, err := ecdsa.SignASN1(rand.Reader, requester.key, hashed[:])
+		require.NoError(t, err)
+		query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+		queryBytes, err := protoV2.Marshal(&query)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(queryBytes)
+	}
+
+	// Test: Remote networks fetch the local membership as a view
+	interopResponse, err := interopcc.HandleExternalRequest(ctx, createQuery("localhost:9080/network2/mychannel:interopcc:GetLocalMembership", "nonce1"))
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	require.NoError(t, protoV2.Unmarshal([]byte(interopResponse), &interopPayload))
+	localMembership, err := interopcc.GetLocalMembership(ctx)
+	require.NoError(t, err)
+	require.Equal(t, localMembership, string(interopPayload.Payload))
+
+	// Test: Arguments are not accepted
+	acpBytes, err = json.Marshal(&common.AccessControlPolicy{
+		SecurityDomain: "network1",
+		Rules:          []*common.Rule{{Principal: "Org1MSP", PrincipalType: "ca", Resource: "mychannel:interopcc:GetLocalMembership*", Read: true}},
+	})
+	require.NoError(t, err)
+	worldState["accessControl:network1"] = acpBytes
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery("localhost:9080/network2/mychannel:interopcc:GetLocalMembership:a", "nonce2"))
+	require.EqualError(t, err, "Recieved 1 arguments instead of the required 0 arguments.")
+}
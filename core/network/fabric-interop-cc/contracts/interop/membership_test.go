@@ -8,6 +8,9 @@ package main
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
@@ -17,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/require"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
@@ -83,6 +87,7 @@ func TestUpdateMembership(t *testing.T) {
 
 	// Membership already exists update the Membership
 	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, nil, nil)
 	err = interopcc.UpdateMembership(ctx, string(membershipBytes))
 	require.NoError(t, err)
 }
@@ -91,10 +96,13 @@ func TestDeleteMembership(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
 
-	// Case when a Membership exists
-	chaincodeStub.GetStateReturns([]byte{}, nil)
-	err := interopcc.DeleteMembership(ctx, "2343")
+	// Case when a Membership exists. Its earlier versions are deleted too.
+	membershipBytes, err := json.Marshal(&common.Membership{SecurityDomain: "2343", Version: 1})
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = interopcc.DeleteMembership(ctx, "2343")
 	require.NoError(t, err)
+	require.Equal(t, 3, chaincodeStub.DelStateCallCount())
 
 	// Case when no Membership is found
 	chaincodeStub.GetStateReturns(nil, nil)
@@ -107,6 +115,43 @@ func TestDeleteMembership(t *testing.T) {
 	require.EqualError(t, err, fmt.Sprintf("unable to retrieve asset"))
 }
 
+func TestLocalMembership(t *testing.T) {
+	ctx, _, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+
+	// Case when no local Membership is recorded
+	_, err := interopcc.GetLocalMembership(ctx)
+	require.EqualError(t, err, "Local membership does not exist")
+	membershipBytes, err := json.Marshal(&membershipAsset)
+	require.NoError(t, err)
+	err = interopcc.UpdateLocalMembership(ctx, string(membershipBytes))
+	require.EqualError(t, err, "Local membership does not exist")
+
+	// Sequence starts at 1 and is incremented on every update, whatever the value supplied
+	err = interopcc.CreateLocalMembership(ctx, string(membershipBytes))
+	require.NoError(t, err)
+	require.NotContains(t, worldState, "membership:2345")
+	membershipString, err := interopcc.GetLocalMembership(ctx)
+	require.NoError(t, err)
+	localMembership, err := decodeMembership([]byte(membershipString))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), localMembership.Sequence)
+	err = interopcc.CreateLocalMembership(ctx, string(membershipBytes))
+	require.EqualError(t, err, "Local membership already exists")
+
+	localMembership.Sequence = 10
+	membershipBytes, err = json.Marshal(localMembership)
+	require.NoError(t, err)
+	err = interopcc.UpdateLocalMembership(ctx, string(membershipBytes))
+	require.NoError(t, err)
+	membershipString, err = interopcc.GetLocalMembership(ctx)
+	require.NoError(t, err)
+	localMembership, err = decodeMembership([]byte(membershipString))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), localMembership.Sequence)
+	require.Equal(t, membershipAsset.Members["member1"].Value, localMembership.Members["member1"].Value)
+}
+
 func TestVerifyMembership(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
@@ -170,3 +215,307 @@ func TestVerifyMembership(t *testing.T) {
 	err = verifyMemberInSecurityDomain(&interopcc, ctx, x509Cert, "test", "member1")
 	require.EqualError(t, err, "Certificate type not supported: unknown")
 }
+
+// revocationTestCA holds a CA and a certificate it issued, for revocation tests
+type revocationTestCA struct {
+	caCert   *x509.Certificate
+	caKey    *ecdsa.PrivateKey
+	caPEM    string
+	leafCert *x509.Certificate
+}
+
+func encodePEM(blockType string, derBytes []byte) string {
+	out := &bytes.Buffer{}
+	pem.Encode(out, &pem.Block{Type: blockType, Bytes: derBytes})
+	return out.String()
+}
+
+// testCertNotBefore and testCertNotAfter bound the validity of the certificates created by the test helpers, which
+// is wide so that tests can move the transaction time
+var (
+	testCertNotBefore = time.Unix(0, 0)
+	testCertNotAfter  = time.Now().Add(30 * 24 * time.Hour)
+)
+
+func createRevocationTestCA(t *testing.T) *revocationTestCA {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca.org1.network1.com", Organization: []string{"org1.network1.com"}},
+		NotBefore:             testCertNotBefore,
+		NotAfter:              testCertNotAfter,
+		SerialNumber:          big.NewInt(1),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := x509.Certificate{
+		Subject:      pkix.Name{CommonName: "peer0.org1.network1.com"},
+		NotBefore:    testCertNotBefore,
+		NotAfter:     testCertNotAfter,
+		SerialNumber: big.NewInt(0x1337),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, &leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+	leafCert, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	return &revocationTestCA{caCert: caCert, caKey: caKey, caPEM: encodePEM("CERTIFICATE", caDER), leafCert: leafCert}
+}
+
+func (ca *revocationTestCA) createCRL(t *testing.T, thisUpdate time.Time, serials ...int64) string {
+	revoked := []pkix.RevokedCertificate{}
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: thisUpdate})
+	}
+	crlDER, err := ca.caCert.CreateCRL(rand.Reader, ca.caKey, revoked, thisUpdate, thisUpdate.Add(time.Hour))
+	require.NoError(t, err)
+	return encodePEM("X509 CRL", crlDER)
+}
+
+func TestMembershipVersions(t *testing.T) {
+	ctx, chaincodeStub, _, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	txTime := time.Now().Unix()
+	setTxTime := func(timeSecs int64) {
+		chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: timeSecs}, nil)
+	}
+	oldCA := createRevocationTestCA(t)
+	newCA := createRevocationTestCA(t)
+	futureCA := createRevocationTestCA(t)
+	newCert, err := parseCert(createFabricTestEndorser(t, newCA, "Org1MSP").certPEM)
+	require.NoError(t, err)
+	futureCert, err := parseCert(createFabricTestEndorser(t, futureCA, "Org1MSP").certPEM)
+	require.NoError(t, err)
+	updateMembership := func(ca *revocationTestCA, effectiveFrom uint64) error {
+		membershipBytes, err := json.Marshal(&common.Membership{
+			SecurityDomain: "network1",
+			Members:        map[string]*common.Member{"Org1MSP": {Value: ca.caPEM, Type: "ca"}},
+			EffectiveFrom:  effectiveFrom,
+		})
+		require.NoError(t, err)
+		return interopcc.UpdateMembership(ctx, string(membershipBytes))
+	}
+
+	setTxTime(txTime - 100)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: oldCA.caPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateMembership(ctx, string(membershipBytes)))
+
+	// Test: Certificates of the previous version are accepted during the overlap window after a rotation
+	setTxTime(txTime)
+	require.NoError(t, updateMembership(newCA, 0))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, oldCA.leafCert, "network1", "Org1MSP"))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, newCert, "network1", "Org1MSP"))
+	setTxTime(txTime + int64(defaultMembershipVersionOverlapSecs))
+	require.Error(t, verifyMemberInSecurityDomain(&interopcc, ctx, oldCA.leafCert, "network1", "Org1MSP"))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, newCert, "network1", "Org1MSP"))
+
+	// Test: Revocations are recorded in a new version that ends the overlap window of earlier versions right away
+	setTxTime(txTime + 10)
+	require.NoError(t, interopcc.UpdateMemberRevokedSerials(ctx, "network1", "Org1MSP", `["1337"]`))
+	require.Error(t, verifyMemberInSecurityDomain(&interopcc, ctx, oldCA.leafCert, "network1", "Org1MSP"))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, newCert, "network1", "Org1MSP"))
+
+	// Test: Versions that take effect in the future are not used until then
+	require.NoError(t, updateMembership(futureCA, uint64(txTime+1000)))
+	require.Error(t, verifyMemberInSecurityDomain(&interopcc, ctx, futureCert, "network1", "Org1MSP"))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, newCert, "network1", "Org1MSP"))
+	setTxTime(txTime + 1000)
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, futureCert, "network1", "Org1MSP"))
+	require.NoError(t, verifyMemberInSecurityDomain(&interopcc, ctx, newCert, "network1", "Org1MSP"))
+
+	// Test: Invalid effective times
+	err = updateMembership(newCA, uint64(txTime))
+	require.EqualError(t, err, fmt.Sprintf("Membership for network1 cannot take effect before the current version, which is effective from %d", txTime+1000))
+	membershipBytes, err = json.Marshal(&common.Membership{SecurityDomain: "network2", EffectiveFrom: 10, EffectiveUntil: 10})
+	require.NoError(t, err)
+	err = interopcc.CreateMembership(ctx, string(membershipBytes))
+	require.EqualError(t, err, "Invalid effective time for membership of network2: effectiveFrom must be earlier than effectiveUntil")
+
+	// Test: History lists every version with the times during which it is effective
+	historyJSON, err := interopcc.GetMembershipHistory(ctx, "network1")
+	require.NoError(t, err)
+	var history []*common.Membership
+	require.NoError(t, json.Unmarshal([]byte(historyJSON), &history))
+	require.Len(t, history, 4)
+	for i, version := range history {
+		require.Equal(t, uint64(i), version.Version)
+	}
+	require.Equal(t, uint64(0), history[0].EffectiveFrom)
+	require.Equal(t, uint64(txTime+10), history[0].EffectiveUntil)
+	require.Equal(t, uint64(txTime), history[1].EffectiveFrom)
+	require.Equal(t, uint64(txTime+10), history[1].EffectiveUntil)
+	require.Empty(t, history[1].Members["Org1MSP"].RevokedSerials)
+	require.Equal(t, uint64(txTime+10), history[2].EffectiveFrom)
+	require.Equal(t, uint64(txTime+1000)+defaultMembershipVersionOverlapSecs, history[2].EffectiveUntil)
+	require.Equal(t, []string{"1337"}, history[2].Members["Org1MSP"].RevokedSerials)
+	require.Equal(t, uint64(txTime+1000), history[3].EffectiveFrom)
+	require.Equal(t, uint64(0), history[3].EffectiveUntil)
+	_, err = interopcc.GetMembershipHistory(ctx, "network2")
+	require.EqualError(t, err, "Membership with id: network2 does not exist")
+}
+
+func TestSetMembershipConfig(t *testing.T) {
+	ctx, chaincodeStub, _, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	txTime := time.Now().Unix()
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: txTime}, nil)
+
+	// Defaults are returned when no config is recorded
+	configJSON, err := interopcc.GetMembershipConfig(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{"versionOverlapSecs":%d}`, defaultMembershipVersionOverlapSecs), configJSON)
+
+	err = interopcc.SetMembershipConfig(ctx, `{"versionOverlapSecs":60}`)
+	require.NoError(t, err)
+	configJSON, err = interopcc.GetMembershipConfig(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"versionOverlapSecs":60}`, configJSON)
+
+	// Test: The configured overlap is used when a new version is recorded
+	require.NoError(t, interopcc.CreateMembership(ctx, `{"securityDomain":"network1"}`))
+	require.NoError(t, interopcc.UpdateMembership(ctx, `{"securityDomain":"network1"}`))
+	historyJSON, err := interopcc.GetMembershipHistory(ctx, "network1")
+	require.NoError(t, err)
+	var history []*common.Membership
+	require.NoError(t, json.Unmarshal([]byte(historyJSON), &history))
+	require.Equal(t, uint64(txTime+60), history[0].EffectiveUntil)
+
+	err = interopcc.SetMembershipConfig(ctx, `{"overlap":60}`)
+	require.EqualError(t, err, `Unmarshal error: json: unknown field "overlap"`)
+}
+
+func TestVerifyMembershipRevocation(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	ca := createRevocationTestCA(t)
+	revocationMember := common.Member{Value: ca.caPEM, Type: "ca"}
+	revocationMembership := common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": &revocationMember},
+	}
+
+	// Test: Happy case. Nothing revoked
+	membershipBytes, err := json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = verifyMemberInSecurityDomain(&interopcc, ctx, ca.leafCert, "network1", "Org1MSP")
+	require.NoError(t, err)
+
+	// Test: CRL revoking other certificates
+	revocationMember.Crls = []string{ca.createCRL(t, time.Now(), 0x1, 0x2)}
+	membershipBytes, err = json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = verifyMemberInSecurityDomain(&interopcc, ctx, ca.leafCert, "network1", "Org1MSP")
+	require.NoError(t, err)
+
+	// Test: CRL revoking the certificate
+	revocationMember.Crls = []string{ca.createCRL(t, time.Now(), 0x1337)}
+	membershipBytes, err = json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = verifyMemberInSecurityDomain(&interopcc, ctx, ca.leafCert, "network1", "Org1MSP")
+	require.EqualError(t, err, "Certificate with serial number 1337 has been revoked by CN=ca.org1.network1.com,O=org1.network1.com")
+
+	// Test: Revoked serial list
+	revocationMember.Crls = []string{}
+	revocationMember.RevokedSerials = []string{"0x01", "13:37"}
+	membershipBytes, err = json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = verifyMemberInSecurityDomain(&interopcc, ctx, ca.leafCert, "network1", "Org1MSP")
+	require.EqualError(t, err, "Certificate with serial number 1337 has been revoked")
+}
+
+func TestPublishMemberCRL(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	ca := createRevocationTestCA(t)
+	otherCA := createRevocationTestCA(t)
+	revocationMembership := common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: ca.caPEM, Type: "ca"}},
+	}
+	membershipBytes, err := json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	now := time.Now()
+
+	// Test: Happy case
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	crl := ca.createCRL(t, now, 0x1337)
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org1MSP", crl)
+	require.NoError(t, err)
+	_, publishedBytes := chaincodeStub.PutStateArgsForCall(1)
+	publishedMembership, err := decodeMembership(publishedBytes)
+	require.NoError(t, err)
+	require.Equal(t, []string{crl}, publishedMembership.Members["Org1MSP"].Crls)
+	require.Equal(t, uint64(1), publishedMembership.Version)
+
+	// Test: Newer CRL from the same issuer replaces the published one
+	chaincodeStub.GetStateReturns(publishedBytes, nil)
+	newerCRL := ca.createCRL(t, now.Add(time.Minute), 0x1337, 0x1338)
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org1MSP", newerCRL)
+	require.NoError(t, err)
+	_, publishedBytes = chaincodeStub.PutStateArgsForCall(5)
+	publishedMembership, err = decodeMembership(publishedBytes)
+	require.NoError(t, err)
+	require.Equal(t, []string{newerCRL}, publishedMembership.Members["Org1MSP"].Crls)
+
+	// Test: Older CRL from the same issuer is rejected
+	chaincodeStub.GetStateReturns(publishedBytes, nil)
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org1MSP", crl)
+	require.EqualError(t, err, "CRL issued by CN=ca.org1.network1.com,O=org1.network1.com is older than the published CRL")
+
+	// Test: CRL not signed by the member's CA
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org1MSP", otherCA.createCRL(t, now, 0x1337))
+	require.EqualError(t, err, "CRL issued by CN=ca.org1.network1.com,O=org1.network1.com is not signed by a certificate authority of the member")
+
+	// Test: Invalid CRL and unknown member
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org1MSP", "crl")
+	require.EqualError(t, err, "Unable to parse CRL: CRL not in a known PEM format")
+	err = interopcc.PublishMemberCRL(ctx, "network1", "Org2MSP", crl)
+	require.EqualError(t, err, "Member does not exist for org: Org2MSP")
+
+	// Test: Memberships with CRLs not signed by the member's CA are rejected
+	revocationMembership.Members["Org1MSP"].Crls = []string{otherCA.createCRL(t, now, 0x1337)}
+	invalidMembershipBytes, err := json.Marshal(&revocationMembership)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(nil, nil)
+	err = interopcc.CreateMembership(ctx, string(invalidMembershipBytes))
+	require.EqualError(t, err, "Invalid revocation information for member Org1MSP: CRL issued by CN=ca.org1.network1.com,O=org1.network1.com is not signed by a certificate authority of the member")
+}
+
+func TestUpdateMemberRevokedSerials(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	membershipBytes, err := json.Marshal(&membershipAsset)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(membershipBytes, nil)
+
+	// Test: Happy case
+	err = interopcc.UpdateMemberRevokedSerials(ctx, "2345", "member1", `["0x1337", "13:38"]`)
+	require.NoError(t, err)
+	_, publishedBytes := chaincodeStub.PutStateArgsForCall(1)
+	publishedMembership, err := decodeMembership(publishedBytes)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0x1337", "13:38"}, publishedMembership.Members["member1"].RevokedSerials)
+
+	// Test: Invalid serial numbers
+	err = interopcc.UpdateMemberRevokedSerials(ctx, "2345", "member1", `["xyz"]`)
+	require.EqualError(t, err, "Invalid certificate serial number: xyz")
+	err = interopcc.UpdateMemberRevokedSerials(ctx, "2345", "member1", `"0x1337"`)
+	require.EqualError(t, err, "Unmarshal error: json: cannot unmarshal string into Go value of type []string")
+}
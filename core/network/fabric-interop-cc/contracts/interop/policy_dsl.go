@@ -0,0 +1,322 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// policydsl contains the parser and evaluator for the verification policy DSL described in
+// rfcs/formats/policies/dsl.md
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const countKeyword = "count"
+
+type policyTokenType int
+
+const (
+	tokenID policyTokenType = iota
+	tokenInt
+	tokenCount
+	tokenAnd
+	tokenOr
+	tokenComparator
+	tokenLParen
+	tokenRParen
+	tokenEOF
+)
+
+type policyToken struct {
+	kind  policyTokenType
+	value string
+	pos   int
+}
+
+// policyExpression is a node in the parsed policy AST
+type policyExpression interface {
+	// evaluate checks if the expression is satisfied by the given set of distinct signers
+	evaluate(signers map[string]bool) bool
+	String() string
+}
+
+type policyAndExpression struct {
+	left, right policyExpression
+}
+
+type policyOrExpression struct {
+	left, right policyExpression
+}
+
+type policyIDExpression struct {
+	id string
+}
+
+type policyCountExpression struct {
+	comparator string
+	value      int
+}
+
+func (e *policyAndExpression) evaluate(signers map[string]bool) bool {
+	return e.left.evaluate(signers) && e.right.evaluate(signers)
+}
+
+func (e *policyAndExpression) String() string {
+	return fmt.Sprintf("(%s && %s)", e.left, e.right)
+}
+
+func (e *policyOrExpression) evaluate(signers map[string]bool) bool {
+	return e.left.evaluate(signers) || e.right.evaluate(signers)
+}
+
+func (e *policyOrExpression) String() string {
+	return fmt.Sprintf("(%s || %s)", e.left, e.right)
+}
+
+func (e *policyIDExpression) evaluate(signers map[string]bool) bool {
+	return signers[e.id]
+}
+
+func (e *policyIDExpression) String() string {
+	return e.id
+}
+
+func (e *policyCountExpression) evaluate(signers map[string]bool) bool {
+	count := len(signers)
+	switch e.comparator {
+	case ">":
+		return count > e.value
+	case ">=":
+		return count >= e.value
+	case "<":
+		return count < e.value
+	case "<=":
+		return count <= e.value
+	case "==":
+		return count == e.value
+	}
+	return false
+}
+
+func (e *policyCountExpression) String() string {
+	return fmt.Sprintf("%s %s %d", countKeyword, e.comparator, e.value)
+}
+
+func isPolicyIDChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
+
+// tokenizePolicyExpression splits a policy expression into tokens
+func tokenizePolicyExpression(expression string) ([]policyToken, error) {
+	tokens := []policyToken{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, policyToken{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, policyToken{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("Unexpected character '%c' at position %d", r, i)
+			}
+			kind := tokenAnd
+			if r == '|' {
+				kind = tokenOr
+			}
+			tokens = append(tokens, policyToken{kind: kind, value: string([]rune{r, r}), pos: i})
+			i += 2
+		case r == '>' || r == '<' || r == '=':
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			comparator := string(runes[start:i])
+			if comparator == "=" {
+				return nil, fmt.Errorf("Unexpected character '=' at position %d", start)
+			}
+			tokens = append(tokens, policyToken{kind: tokenComparator, value: comparator, pos: start})
+		case isPolicyIDChar(r):
+			start := i
+			for i < len(runes) && isPolicyIDChar(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if _, err := strconv.Atoi(word); err == nil {
+				tokens = append(tokens, policyToken{kind: tokenInt, value: word, pos: start})
+			} else if word == countKeyword {
+				tokens = append(tokens, policyToken{kind: tokenCount, value: word, pos: start})
+			} else {
+				tokens = append(tokens, policyToken{kind: tokenID, value: word, pos: start})
+			}
+		default:
+			return nil, fmt.Errorf("Unexpected character '%c' at position %d", r, i)
+		}
+	}
+	tokens = append(tokens, policyToken{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+func (p *policyParser) peek() policyToken {
+	return p.tokens[p.pos]
+}
+
+func (p *policyParser) next() policyToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *policyParser) unexpected(token policyToken) error {
+	if token.kind == tokenEOF {
+		return fmt.Errorf("Unexpected end of expression")
+	}
+	return fmt.Errorf("Unexpected token '%s' at position %d", token.value, token.pos)
+}
+
+// parseOr: expression := andExpression ( '||' andExpression )*
+func (p *policyParser) parseOr() (policyExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyOrExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd: andExpression := primary ( '&&' primary )*
+func (p *policyParser) parseAnd() (policyExpression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyAndExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parsePrimary: primary := '(' expression ')' | ID | countExpression
+func (p *policyParser) parsePrimary() (policyExpression, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenLParen:
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.unexpected(closing)
+		}
+		return expression, nil
+	case tokenID:
+		return &policyIDExpression{id: token.value}, nil
+	case tokenCount:
+		// count <comparator> INT
+		comparator := p.next()
+		if comparator.kind != tokenComparator {
+			return nil, p.unexpected(comparator)
+		}
+		value := p.next()
+		if value.kind != tokenInt {
+			return nil, p.unexpected(value)
+		}
+		intValue, _ := strconv.Atoi(value.value)
+		return &policyCountExpression{comparator: comparator.value, value: intValue}, nil
+	case tokenInt:
+		// INT <comparator> count, which is normalised to count <flipped comparator> INT
+		comparator := p.next()
+		if comparator.kind != tokenComparator {
+			return nil, p.unexpected(comparator)
+		}
+		if count := p.next(); count.kind != tokenCount {
+			return nil, p.unexpected(count)
+		}
+		intValue, _ := strconv.Atoi(token.value)
+		flipped := map[string]string{">": "<", ">=": "<=", "<": ">", "<=": ">=", "==": "=="}
+		return &policyCountExpression{comparator: flipped[comparator.value], value: intValue}, nil
+	default:
+		return nil, p.unexpected(token)
+	}
+}
+
+// parsePolicyExpression parses a single verification policy DSL expression (grammar in
+// common/policy-dsl/parser/Policy.g4).
+//
+// An expression is composed of org identifiers and count expressions, combined with the boolean
+// operators '&&' and '||'. '&&' binds tighter than '||', and parentheses may be used for grouping, e.g.:
+//
+//	Org1MSP && Org2MSP || count >= 3
+//
+// Identifiers are matched against the distinct set of orgs whose signatures were validated in a proof.
+// Unlike the antlr grammar, identifiers may start with an uppercase letter (as Fabric MSP IDs usually do)
+// and may contain '.' and '-'.
+func parsePolicyExpression(expression string) (policyExpression, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("Empty policy expression")
+	}
+	tokens, err := tokenizePolicyExpression(expression)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid policy expression '%s': %s", expression, err.Error())
+	}
+	parser := &policyParser{tokens: tokens}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid policy expression '%s': %s", expression, err.Error())
+	}
+	if token := parser.peek(); token.kind != tokenEOF {
+		return nil, fmt.Errorf("Invalid policy expression '%s': %s", expression, parser.unexpected(token).Error())
+	}
+	return parsed, nil
+}
+
+// parsePolicyCriteria parses the criteria list of a verification policy into a single expression.
+// Each element of the list is an expression in its own right, and all of them must be satisfied,
+// so a plain list of org identifiers retains its original meaning of "all of these orgs must sign".
+func parsePolicyCriteria(criteria []string) (policyExpression, error) {
+	if len(criteria) == 0 {
+		return nil, fmt.Errorf("Policy has no criteria")
+	}
+	var combined policyExpression
+	for _, criterion := range criteria {
+		expression, err := parsePolicyExpression(criterion)
+		if err != nil {
+			return nil, err
+		}
+		if combined == nil {
+			combined = expression
+		} else {
+			combined = &policyAndExpression{left: combined, right: expression}
+		}
+	}
+	return combined, nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signerSet(signers ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, signer := range signers {
+		set[signer] = true
+	}
+	return set
+}
+
+func TestParsePolicyExpression(t *testing.T) {
+	// Single identifier
+	expression, err := parsePolicyExpression("Org1MSP")
+	require.NoError(t, err)
+	require.Equal(t, "Org1MSP", expression.String())
+
+	// '&&' binds tighter than '||'
+	expression, err = parsePolicyExpression("Org1 && Org2 || count >= 3")
+	require.NoError(t, err)
+	require.Equal(t, "((Org1 && Org2) || count >= 3)", expression.String())
+
+	// Parentheses override precedence
+	expression, err = parsePolicyExpression("Org1 && (Org2 || Org3)")
+	require.NoError(t, err)
+	require.Equal(t, "(Org1 && (Org2 || Org3))", expression.String())
+
+	// Count expression with the integer on the left is normalised
+	expression, err = parsePolicyExpression("2 < count")
+	require.NoError(t, err)
+	require.Equal(t, "count > 2", expression.String())
+
+	// Identifiers with dots and dashes
+	expression, err = parsePolicyExpression("org1.network1.com&&org-2")
+	require.NoError(t, err)
+	require.Equal(t, "(org1.network1.com && org-2)", expression.String())
+
+	// Error cases
+	_, err = parsePolicyExpression("")
+	require.EqualError(t, err, "Empty policy expression")
+	_, err = parsePolicyExpression("org1 && count")
+	require.EqualError(t, err, "Invalid policy expression 'org1 && count': Unexpected end of expression")
+	_, err = parsePolicyExpression("org1 & org2")
+	require.EqualError(t, err, "Invalid policy expression 'org1 & org2': Unexpected character '&' at position 5")
+	_, err = parsePolicyExpression("count = 2")
+	require.EqualError(t, err, "Invalid policy expression 'count = 2': Unexpected character '=' at position 6")
+	_, err = parsePolicyExpression("org1 org2")
+	require.EqualError(t, err, "Invalid policy expression 'org1 org2': Unexpected token 'org2' at position 5")
+	_, err = parsePolicyExpression("(org1 || org2")
+	require.EqualError(t, err, "Invalid policy expression '(org1 || org2': Unexpected end of expression")
+	_, err = parsePolicyExpression("count >= org1")
+	require.EqualError(t, err, "Invalid policy expression 'count >= org1': Unexpected token 'org1' at position 9")
+}
+
+func TestEvaluatePolicyExpression(t *testing.T) {
+	expression, err := parsePolicyExpression("Org1 && Org2 || count >= 3")
+	require.NoError(t, err)
+	require.True(t, expression.evaluate(signerSet("Org1", "Org2")))
+	require.True(t, expression.evaluate(signerSet("Org3", "Org4", "Org5")))
+	require.False(t, expression.evaluate(signerSet("Org1", "Org3")))
+	require.False(t, expression.evaluate(signerSet()))
+
+	expression, err = parsePolicyExpression("count == 1")
+	require.NoError(t, err)
+	require.True(t, expression.evaluate(signerSet("Org1")))
+	require.False(t, expression.evaluate(signerSet("Org1", "Org2")))
+
+	expression, err = parsePolicyExpression("count <= 1 && Org1")
+	require.NoError(t, err)
+	require.True(t, expression.evaluate(signerSet("Org1")))
+	require.False(t, expression.evaluate(signerSet("Org2")))
+}
+
+func TestParsePolicyCriteria(t *testing.T) {
+	// List-style criteria are an implicit AND
+	expression, err := parsePolicyCriteria([]string{"Org1", "Org2"})
+	require.NoError(t, err)
+	require.Equal(t, "(Org1 && Org2)", expression.String())
+	require.True(t, expression.evaluate(signerSet("Org1", "Org2", "Org3")))
+	require.False(t, expression.evaluate(signerSet("Org1")))
+
+	// Expressions and identifiers can be mixed
+	expression, err = parsePolicyCriteria([]string{"Org1", "Org2 || count > 2"})
+	require.NoError(t, err)
+	require.True(t, expression.evaluate(signerSet("Org1", "Org3", "Org4")))
+	require.False(t, expression.evaluate(signerSet("Org2", "Org3", "Org4")))
+
+	_, err = parsePolicyCriteria([]string{})
+	require.EqualError(t, err, "Policy has no criteria")
+	_, err = parsePolicyCriteria([]string{"Org1", "||"})
+	require.EqualError(t, err, "Invalid policy expression '||': Unexpected token '||' at position 0")
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// private_data contains the checks on views whose address targets a private data collection of an application
+// chaincode. The response to such views is a PrivateDataPayload holding the private value along with the hash of
+// the value recorded on the ledger, against which the value is checked.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// privateDataCollectionSeparator separates the contract from the collection in the view segment of an address
+const privateDataCollectionSeparator = "#"
+
+// verifyPrivateDataPayload decodes the response to a view of a private data collection, and checks that it holds a
+// value of the collection that matches the hash recorded on the ledger
+func verifyPrivateDataPayload(payload []byte, collection string) (*common.PrivateDataPayload, error) {
+	var privateDataPayload common.PrivateDataPayload
+	err := protoV2.Unmarshal(payload, &privateDataPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal private data payload: %s", err.Error())
+	}
+	if privateDataPayload.Collection != collection {
+		return nil, fmt.Errorf("Private data is from collection %s instead of collection %s", privateDataPayload.Collection, collection)
+	}
+	valueHash := sha256.Sum256(privateDataPayload.Value)
+	if len(privateDataPayload.Hash) == 0 || !bytes.Equal(valueHash[:], privateDataPayload.Hash) {
+		return nil, fmt.Errorf("Value of key %s in collection %s does not match the hash on the ledger", privateDataPayload.Key, collection)
+	}
+	return &privateDataPayload, nil
+}
+
+// getPrivateDataCollection returns the private data collection targeted by an address, or an empty string if the
+// address does not refer to a Fabric view of a collection
+func getPrivateDataCollection(address string) string {
+	addressStruct, err := parseAddress(address)
+	if err != nil {
+		return ""
+	}
+	viewAddress, err := parseFabricViewAddress(addressStruct.ViewSegment)
+	if err != nil {
+		return ""
+	}
+	return viewAddress.Collection
+}
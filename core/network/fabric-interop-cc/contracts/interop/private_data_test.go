@@ -0,0 +1,171 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// createPrivateDataPayload returns the serialized response of an application chaincode to a view of a key in a
+// private data collection
+func createPrivateDataPayload(t *testing.T, collection string, value []byte, hashedValue []byte) []byte {
+	hash := sha256.Sum256(hashedValue)
+	privateDataPayloadBytes, err := protoV2.Marshal(&common.PrivateDataPayload{Collection: collection, Key: "a", Value: value, Hash: hash[:]})
+	require.NoError(t, err)
+	return privateDataPayloadBytes
+}
+
+func TestHandleExternalRequestPrivateData(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	wtest.SetMockStubCCId(chaincodeStub, "interopcc")
+
+	template := x509.Certificate{
+		Subject:      pkix.Name{CommonName: "example-a.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+	certDER, key, err := createECDSACertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	certPEM := encodePEM("CERTIFICATE", certDER)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "network1",
+		Members:        map[string]*common.Member{"Org1MSP": {Value: certPEM, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	worldState["membership:network1"] = membershipBytes
+	setRules := func(rules ...*common.Rule) {
+		acpBytes, err := json.Marshal(&common.AccessControlPolicy{SecurityDomain: "network1", Rules: rules})
+		require.NoError(t, err)
+		worldState["accessControl:network1"] = acpBytes
+	}
+	// createQuery returns a signed query, or invocation, with a unique nonce for an address
+	nonceCount := 0
+	createQuery := func(address string, invoke bool) string {
+		nonceCount++
+		query := common.Query{
+			Address:           address,
+			RequestingRelay:   "network1-relay",
+			RequestingNetwork: "network1",
+			RequestingOrg:     "Org1MSP",
+			Certificate:       certPEM,
+			Nonce:             fmt.Sprintf("nonce%d", nonceCount),
+			Invoke:            invoke,
+		}
+		if invoke {
+			query.Timestamp = uint64(time.Now().Unix())
+		}
+		hashed, err := computeSHA2Hash([]byte(getQuerySignedMessage(&query)), key.PublicKey.Params().BitSize)
+		require.NoError(t, err)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, hashed)
+		require.NoError(t, err)
+		query.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+		queryBytes, err := protoV2.Marshal(&query)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(queryBytes)
+	}
+	address := "localhost:9080/network1/mychannel:pricecc#prices:GetPrice:a"
+	err = interopcc.CreateApplicationChaincode(ctx, `{"chaincodeId":"pricecc","channel":"mychannel","enabled":true}`)
+	require.NoError(t, err)
+
+	// Test: Rules for the public state of the application chaincode do not permit views of its collections
+	setRules(&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:pricecc:*", Read: true})
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery(address, false))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "DOES NOT PERMIT")
+
+	// Test: Happy case
+	setRules(&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:pricecc#prices:*", Read: true})
+	privateDataPayloadBytes := createPrivateDataPayload(t, "prices", []byte("42"), []byte("42"))
+	chaincodeStub.InvokeChaincodeReturns(pb.Response{Status: shim.OK, Payload: privateDataPayloadBytes})
+	interopResponse, err := interopcc.HandleExternalRequest(ctx, createQuery(address, false))
+	require.NoError(t, err)
+	var interopPayload common.InteropPayload
+	require.NoError(t, protoV2.Unmarshal([]byte(interopResponse), &interopPayload))
+	require.Equal(t, privateDataPayloadBytes, interopPayload.Payload)
+	contract, args, channel := chaincodeStub.InvokeChaincodeArgsForCall(chaincodeStub.InvokeChaincodeCallCount() - 1)
+	require.Equal(t, "pricecc", contract)
+	require.Equal(t, [][]byte{[]byte("GetPrice"), []byte("a")}, args)
+	require.Equal(t, "mychannel", channel)
+
+	// Test: Responses that do not match the hash or the collection are rejected
+	chaincodeStub.InvokeChaincodeReturns(pb.Response{Status: shim.OK, Payload: createPrivateDataPayload(t, "prices", []byte("42"), []byte("41"))})
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery(address, false))
+	require.EqualError(t, err, "Invalid private data response: Value of key a in collection prices does not match the hash on the ledger")
+	chaincodeStub.InvokeChaincodeReturns(pb.Response{Status: shim.OK, Payload: createPrivateDataPayload(t, "costs", []byte("42"), []byte("42"))})
+	_, err = interopcc.HandleExternalRequest(ctx, createQuery(address, false))
+	require.EqualError(t, err, "Invalid private data response: Private data is from collection costs instead of collection prices")
+
+	// Test: Private data collections cannot be invoked
+	setRules(&common.Rule{Principal: certPEM, PrincipalType: "certificate", Resource: "mychannel:pricecc#prices:*", Write: true})
+	_, err = interopcc.HandleExternalInvocation(ctx, createQuery(address, true))
+	require.EqualError(t, err, "Private data collections can only be queried by remote networks.")
+}
+
+func TestVerifyViewPrivateData(t *testing.T) {
+	ctx, _, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	endorser := newCommitProofSigner(t, "Org1MSP", 1)
+	orderer := newCommitProofSigner(t, "OrdererMSP", 2)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: fabricNetwork,
+		Members: map[string]*common.Member{
+			"Org1MSP":    {Value: endorser.certPEM, Type: "ca"},
+			"OrdererMSP": {Value: orderer.certPEM, Type: "ca"},
+		},
+	})
+	require.NoError(t, err)
+	worldState["membership:"+fabricNetwork] = membershipBytes
+	verificationPolicyBytes, err := json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: fabricNetwork,
+		Identifiers:    []*common.Identifier{{Pattern: "mychannel:pricecc#prices:*", Policy: &common.Policy{Criteria: []string{"Org1MSP"}, Type: "signature"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+	address := fabricRelayEndpoint + "/" + fabricNetwork + "/mychannel:pricecc#prices:GetPrice:a"
+	// createView returns a base64 encoded view of the address holding a private data response
+	createView := func(privateDataPayloadBytes []byte) string {
+		interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: address, Payload: privateDataPayloadBytes})
+		require.NoError(t, err)
+		commitViewBytes, err := protoV2.Marshal(createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer))
+		require.NoError(t, err)
+		viewBytes, err := protoV2.Marshal(&common.View{
+			Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: fabricCommitmentProofType},
+			Data: commitViewBytes,
+		})
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(viewBytes)
+	}
+
+	// Test: The private value is extracted from the view after checking it against the hash
+	viewData, err := interopcc.ParseAndValidateView(ctx, address, createView(createPrivateDataPayload(t, "prices", []byte("42"), []byte("42"))))
+	require.NoError(t, err)
+	require.Equal(t, []byte("42"), viewData)
+
+	// Test: Values that do not match the hash are rejected, even if the view is endorsed
+	err = interopcc.VerifyView(ctx, createView(createPrivateDataPayload(t, "prices", []byte("42"), []byte("41"))), address)
+	require.EqualError(t, err, "Value of key a in collection prices does not match the hash on the ledger")
+}
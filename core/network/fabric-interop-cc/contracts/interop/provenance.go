@@ -0,0 +1,327 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// provenance contains the records of where the views imported through WriteExternalState came from, and the
+// chaincode functions used to query and re-verify them
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/besu"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/corda"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+const (
+	viewProvenanceObjectType = "viewProvenance"
+	importedViewObjectType   = "importedView"
+)
+
+// ViewProvenance records where the data of a view imported into the ledger came from.
+//
+// Entries are keyed by the ID of the transaction that imported the view and the address it was requested
+// from. ViewHash is the hex encoded SHA-256 hash of the payload signed by the remote network (see computeViewHash),
+// and Signers are the members of the remote network whose proofs were accepted. The view itself is not kept, as it
+// may carry decrypted confidential contents or private data collection values, so the proof can only be verified
+// again by presenting the view.
+type ViewProvenance struct {
+	TxID                 string   `json:"txId"`
+	Address              string   `json:"address"`
+	SecurityDomain       string   `json:"securityDomain"`
+	Protocol             string   `json:"protocol"`
+	ProofType            string   `json:"proofType"`
+	ViewHash             string   `json:"viewHash"`
+	Signers              []string `json:"signers"`
+	RequestorCertificate string   `json:"requestorCertificate"`
+	ApplicationID        string   `json:"applicationId"`
+	ApplicationChannel   string   `json:"applicationChannel"`
+	ApplicationFunction  string   `json:"applicationFunction"`
+	Timestamp            int64    `json:"timestamp"`
+	Client               string   `json:"client"`
+}
+
+// GetViewProvenance cc returns the provenance of the view imported from an address by a transaction
+func (s *SmartContract) GetViewProvenance(ctx contractapi.TransactionContextInterface, txID string, address string) (string, error) {
+	provenance, err := getViewProvenance(ctx, txID, address)
+	if err != nil {
+		return "", err
+	}
+	provenanceBytes, err := json.Marshal(provenance)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(provenanceBytes), nil
+}
+
+// GetViewProvenancesByTxID cc returns the provenance of all the views imported by a transaction
+func (s *SmartContract) GetViewProvenancesByTxID(ctx contractapi.TransactionContextInterface, txID string) (string, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(viewProvenanceObjectType, []string{txID})
+	if err != nil {
+		return "", err
+	}
+	defer iterator.Close()
+	provenances := []*ViewProvenance{}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return "", err
+		}
+		var provenance ViewProvenance
+		err = json.Unmarshal(result.Value, &provenance)
+		if err != nil {
+			return "", fmt.Errorf("Failed to unmarshal view provenance: %s", err.Error())
+		}
+		provenances = append(provenances, &provenance)
+	}
+	provenancesBytes, err := json.Marshal(provenances)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(provenancesBytes), nil
+}
+
+// GetViewProvenanceByHash cc returns the provenance of the transaction that first imported a view, given the
+// hex encoded view hash recorded in its provenance
+func (s *SmartContract) GetViewProvenanceByHash(ctx contractapi.TransactionContextInterface, viewHash string) (string, error) {
+	provenanceKey, err := getImportedViewKey(ctx, viewHash)
+	if err != nil {
+		return "", err
+	}
+	if provenanceKey == "" {
+		return "", fmt.Errorf("View with hash %s has not been imported", viewHash)
+	}
+	provenanceBytes, err := ctx.GetStub().GetState(provenanceKey)
+	if err != nil {
+		return "", err
+	}
+	if provenanceBytes == nil {
+		return "", fmt.Errorf("Provenance of view with hash %s does not exist", viewHash)
+	}
+	return string(provenanceBytes), nil
+}
+
+// VerifyViewProvenance cc verifies the proof of a view imported from an address by a transaction again, against
+// the Membership and verification policy currently recorded for the remote network. The view must match the hash
+// recorded in the provenance.
+func (s *SmartContract) VerifyViewProvenance(ctx contractapi.TransactionContextInterface, txID string, address string, b64ViewProto string) error {
+	provenance, err := getViewProvenance(ctx, txID, address)
+	if err != nil {
+		return err
+	}
+	viewBytes, err := base64.StdEncoding.DecodeString(b64ViewProto)
+	if err != nil {
+		return fmt.Errorf("Unable to base64 decode data: %s", err.Error())
+	}
+	var view common.View
+	err = protoV2.Unmarshal(viewBytes, &view)
+	if err != nil {
+		return fmt.Errorf("View Unmarshal error: %s", err)
+	}
+	viewHash, err := computeViewHash(&view, address)
+	if err != nil {
+		return err
+	}
+	if viewHash != provenance.ViewHash {
+		return fmt.Errorf("View does not match the hash %s of the view from address %s imported by transaction %s", provenance.ViewHash, address, txID)
+	}
+	_, err = verifyView(s, ctx, &view, address)
+	return err
+}
+
+// newViewProvenance creates the provenance of a verified view, to be completed with the details of the transaction
+// importing it
+func newViewProvenance(view *common.View, address string, securityDomain string, signers []string) (*ViewProvenance, error) {
+	viewHash, err := computeViewHash(view, address)
+	if err != nil {
+		return nil, err
+	}
+	interopPayload, err := extractInteropPayload(view)
+	if err != nil {
+		return nil, err
+	}
+	return &ViewProvenance{
+		Address:              address,
+		SecurityDomain:       securityDomain,
+		Protocol:             view.Meta.Protocol.String(),
+		ProofType:            view.Meta.ProofType,
+		ViewHash:             viewHash,
+		Signers:              signers,
+		RequestorCertificate: interopPayload.RequestorCertificate,
+	}, nil
+}
+
+// computeViewHash returns the hex encoded SHA-256 hash of the payload signed by the remote network in a verified
+// view, i.e., the ProposalResponsePayload endorsed by the peers of a Fabric network, or the payload notarized by a
+// Corda or Besu network. Unlike the serialized view, the signed payload cannot be re-encoded without invalidating
+// the proof, and it is the same for a Fabric transaction whether its view carries a Notarization or Commitment proof.
+func computeViewHash(view *common.View, address string) (string, error) {
+	var signedPayload []byte
+	if view.Meta.Protocol == common.Meta_FABRIC {
+		if view.Meta.ProofType == fabricCommitmentProofType {
+			var fabricCommitView fabric.FabricCommitView
+			err := protoV2.Unmarshal(view.Data, &fabricCommitView)
+			if err != nil {
+				return "", fmt.Errorf("FabricCommitView Unmarshal error: %s", err)
+			}
+			if int(fabricCommitView.TransactionIndex) >= len(fabricCommitView.BlockData.GetData()) {
+				return "", fmt.Errorf("Transaction index %d is out of range for the block in the view", fabricCommitView.TransactionIndex)
+			}
+			endorsedAction, err := getEndorsedAction(fabricCommitView.BlockData.Data[fabricCommitView.TransactionIndex], address)
+			if err != nil {
+				return "", err
+			}
+			signedPayload = endorsedAction.ProposalResponsePayload
+		} else {
+			var fabricViewData fabric.FabricView
+			err := protoV2.Unmarshal(view.Data, &fabricViewData)
+			if err != nil {
+				return "", fmt.Errorf("FabricView Unmarshal error: %s", err)
+			}
+			// The endorsements are verified over this serialization of the ProposalResponsePayload
+			signedPayload, err = proto.Marshal(fabricViewData.ProposalResponsePayload)
+			if err != nil {
+				return "", fmt.Errorf("Unable to marshal proposal response payload: %s", err.Error())
+			}
+		}
+	} else if view.Meta.Protocol == common.Meta_CORDA {
+		var cordaViewData corda.ViewData
+		err := protoV2.Unmarshal(view.Data, &cordaViewData)
+		if err != nil {
+			return "", fmt.Errorf("CordaView Unmarshal error: %s", err)
+		}
+		signedPayload = cordaViewData.Payload
+	} else if view.Meta.Protocol == common.Meta_BESU || view.Meta.Protocol == common.Meta_ETHEREUM {
+		var besuViewData besu.ViewData
+		err := protoV2.Unmarshal(view.Data, &besuViewData)
+		if err != nil {
+			return "", fmt.Errorf("BesuView Unmarshal error: %s", err)
+		}
+		signedPayload = besuViewData.Payload
+	} else {
+		return "", fmt.Errorf("Cannot compute hash of view; unsupported DLT type: %+v", view.Meta.Protocol)
+	}
+	viewHash := sha256.Sum256(signedPayload)
+	return hex.EncodeToString(viewHash[:]), nil
+}
+
+// verifyViewsNotImported checks that none of the views have been imported by an earlier transaction, if replay
+// protection is configured to reject re-imported views
+func verifyViewsNotImported(ctx contractapi.TransactionContextInterface, provenances []*ViewProvenance) error {
+	config, err := getReplayProtectionConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.RejectReimportedViews {
+		return nil
+	}
+	for _, provenance := range provenances {
+		provenanceKey, err := getImportedViewKey(ctx, provenance.ViewHash)
+		if err != nil {
+			return err
+		}
+		if provenanceKey != "" {
+			errorMessage := fmt.Sprintf("View with hash %s from address %s has already been imported", provenance.ViewHash, provenance.Address)
+			log.Error(errorMessage)
+			return errors.New(errorMessage)
+		}
+	}
+	return nil
+}
+
+// recordViewProvenances records the provenance of the views imported by a WriteExternalState transaction, and
+// indexes them by view hash unless an earlier transaction imported the same view
+func recordViewProvenances(ctx contractapi.TransactionContextInterface, applicationID string, applicationChannel string, applicationFunction string, provenances []*ViewProvenance) error {
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("Unable to get client MSP ID: %s", err)
+	}
+	txID := ctx.GetStub().GetTxID()
+	for _, provenance := range provenances {
+		provenance.TxID = txID
+		provenance.ApplicationID = applicationID
+		provenance.ApplicationChannel = applicationChannel
+		provenance.ApplicationFunction = applicationFunction
+		provenance.Timestamp = txTimeSecs
+		provenance.Client = client
+		provenanceKey, err := ctx.GetStub().CreateCompositeKey(viewProvenanceObjectType, []string{txID, provenance.Address})
+		if err != nil {
+			return err
+		}
+		provenanceBytes, err := json.Marshal(provenance)
+		if err != nil {
+			return fmt.Errorf("Marshal error: %s", err)
+		}
+		err = ctx.GetStub().PutState(provenanceKey, provenanceBytes)
+		if err != nil {
+			return err
+		}
+		existingKey, err := getImportedViewKey(ctx, provenance.ViewHash)
+		if err != nil {
+			return err
+		}
+		if existingKey != "" {
+			continue
+		}
+		importedViewKey, err := ctx.GetStub().CreateCompositeKey(importedViewObjectType, []string{provenance.ViewHash})
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(importedViewKey, []byte(provenanceKey))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getImportedViewKey returns the key of the provenance of the transaction that first imported a view, or an
+// empty string if the view has not been imported
+func getImportedViewKey(ctx contractapi.TransactionContextInterface, viewHash string) (string, error) {
+	importedViewKey, err := ctx.GetStub().CreateCompositeKey(importedViewObjectType, []string{viewHash})
+	if err != nil {
+		return "", err
+	}
+	provenanceKey, err := ctx.GetStub().GetState(importedViewKey)
+	if err != nil {
+		return "", err
+	}
+	return string(provenanceKey), nil
+}
+
+func getViewProvenance(ctx contractapi.TransactionContextInterface, txID string, address string) (*ViewProvenance, error) {
+	provenanceKey, err := ctx.GetStub().CreateCompositeKey(viewProvenanceObjectType, []string{txID, address})
+	if err != nil {
+		return nil, err
+	}
+	provenanceBytes, err := ctx.GetStub().GetState(provenanceKey)
+	if err != nil {
+		return nil, err
+	}
+	if provenanceBytes == nil {
+		return nil, fmt.Errorf("Provenance of view from address %s imported by transaction %s does not exist", address, txID)
+	}
+	var provenance ViewProvenance
+	err = json.Unmarshal(provenanceBytes, &provenance)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal view provenance: %s", err.Error())
+	}
+	return &provenance, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+func TestViewProvenance(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	wtest.SetMockStubTxTime(chaincodeStub, viewTxTime)
+	chaincodeStub.GetStateByPartialCompositeKeyCalls(func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		iterator := &mocks.StateQueryIterator{}
+		prefix := objectType + ":" + strings.Join(attributes, ":") + ":"
+		i := 0
+		for key, value := range worldState {
+			if strings.HasPrefix(key, prefix) {
+				iterator.HasNextReturnsOnCall(i, true)
+				iterator.NextReturnsOnCall(i, &queryresult.KV{Key: key, Value: value}, nil)
+				i++
+			}
+		}
+		return iterator, nil
+	})
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200})
+	interopcc := SmartContract{}
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: fabricNetwork,
+		Members:        map[string]*common.Member{"Org1MSP": {Value: fabricCert, Type: "ca"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateMembership(ctx, string(membershipBytes)))
+	verificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy)
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+	// The view hash covers the ProposalResponsePayload signed by the endorsers
+	viewBytes, err := base64.StdEncoding.DecodeString(b64View)
+	require.NoError(t, err)
+	var view common.View
+	require.NoError(t, protoV2.Unmarshal(viewBytes, &view))
+	var fabricViewData fabric.FabricView
+	require.NoError(t, protoV2.Unmarshal(view.Data, &fabricViewData))
+	proposalResponsePayloadBytes, err := proto.Marshal(fabricViewData.ProposalResponsePayload)
+	require.NoError(t, err)
+	viewHash := sha256.Sum256(proposalResponsePayloadBytes)
+	viewHashHex := hex.EncodeToString(viewHash[:])
+	// The same view re-encoded with an unknown field (number 15, empty) has different bytes but the same signed payload
+	b64ReencodedView := base64.StdEncoding.EncodeToString(append(append([]byte{}, viewBytes...), 0x7a, 0x00))
+	require.NotEqual(t, b64View, b64ReencodedView)
+
+	// Test: The provenance of an imported view is recorded
+	chaincodeStub.GetTxIDReturns("tx1")
+	err = interopcc.WriteExternalState(ctx, "simplestate", "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{b64View})
+	require.NoError(t, err)
+	provenanceJSON, err := interopcc.GetViewProvenance(ctx, "tx1", fabricViewAddress)
+	require.NoError(t, err)
+	var provenance ViewProvenance
+	require.NoError(t, json.Unmarshal([]byte(provenanceJSON), &provenance))
+	require.Equal(t, "tx1", provenance.TxID)
+	require.Equal(t, fabricViewAddress, provenance.Address)
+	require.Equal(t, fabricNetwork, provenance.SecurityDomain)
+	require.Equal(t, "FABRIC", provenance.Protocol)
+	require.Equal(t, "Notarization", provenance.ProofType)
+	require.Equal(t, viewHashHex, provenance.ViewHash)
+	require.Equal(t, []string{"Org1MSP"}, provenance.Signers)
+	require.Equal(t, "simplestate", provenance.ApplicationID)
+	require.Equal(t, "mychannel", provenance.ApplicationChannel)
+	require.Equal(t, "Write", provenance.ApplicationFunction)
+	require.Equal(t, viewTxTime.Unix(), provenance.Timestamp)
+	// The view itself, which may carry decrypted or private contents, is not recorded
+	require.NotContains(t, provenanceJSON, b64View)
+
+	// Test: Lookup by transaction and by view hash
+	provenancesJSON, err := interopcc.GetViewProvenancesByTxID(ctx, "tx1")
+	require.NoError(t, err)
+	require.JSONEq(t, "["+provenanceJSON+"]", provenancesJSON)
+	provenancesJSON, err = interopcc.GetViewProvenancesByTxID(ctx, "tx2")
+	require.NoError(t, err)
+	require.Equal(t, "[]", provenancesJSON)
+	hashProvenanceJSON, err := interopcc.GetViewProvenanceByHash(ctx, viewHashHex)
+	require.NoError(t, err)
+	require.JSONEq(t, provenanceJSON, hashProvenanceJSON)
+	_, err = interopcc.GetViewProvenanceByHash(ctx, "abcd")
+	require.EqualError(t, err, "View with hash abcd has not been imported")
+	_, err = interopcc.GetViewProvenance(ctx, "tx2", fabricViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("Provenance of view from address %s imported by transaction tx2 does not exist", fabricViewAddress))
+
+	// Test: Re-verification uses the verification policy currently recorded
+	require.NoError(t, interopcc.VerifyViewProvenance(ctx, "tx1", fabricViewAddress, b64View))
+	require.NoError(t, interopcc.VerifyViewProvenance(ctx, "tx1", fabricViewAddress, b64ReencodedView))
+	otherView := &common.View{Meta: &common.Meta{Protocol: common.Meta_CORDA, ProofType: "Notarization"}, Data: []byte{}}
+	otherViewBytes, err := protoV2.Marshal(otherView)
+	require.NoError(t, err)
+	err = interopcc.VerifyViewProvenance(ctx, "tx1", fabricViewAddress, base64.StdEncoding.EncodeToString(otherViewBytes))
+	require.EqualError(t, err, fmt.Sprintf("View does not match the hash %s of the view from address %s imported by transaction tx1", viewHashHex, fabricViewAddress))
+	verificationPolicyBytes, err = json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: fabricNetwork,
+		Identifiers:    []*common.Identifier{{Pattern: fabricPattern, Policy: &common.Policy{Criteria: []string{"Org2MSP"}, Type: "signature"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, interopcc.UpdateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+	require.Error(t, interopcc.VerifyViewProvenance(ctx, "tx1", fabricViewAddress, b64View))
+	verificationPolicyBytes, err = json.Marshal(&network1VerificationPolicy)
+	require.NoError(t, err)
+	require.NoError(t, interopcc.UpdateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+
+	// Test: Views can be re-imported by default, and the hash index keeps the first import
+	chaincodeStub.GetTxIDReturns("tx2")
+	err = interopcc.WriteExternalState(ctx, "simplestate", "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{b64View})
+	require.NoError(t, err)
+	_, err = interopcc.GetViewProvenance(ctx, "tx2", fabricViewAddress)
+	require.NoError(t, err)
+	hashProvenanceJSON, err = interopcc.GetViewProvenanceByHash(ctx, viewHashHex)
+	require.NoError(t, err)
+	require.JSONEq(t, provenanceJSON, hashProvenanceJSON)
+
+	// Test: Re-imported views are rejected when configured, including when re-encoded
+	setMockClient(ctx, "Org1MSP", true)
+	require.NoError(t, interopcc.SetReplayProtectionConfig(ctx, `{"nonceWindowSecs":60,"maxTimestampSkewSecs":10,"requireTimestamp":false,"rejectReimportedViews":true}`))
+	chaincodeStub.GetTxIDReturns("tx3")
+	invokeCount := chaincodeStub.InvokeChaincodeCallCount()
+	err = interopcc.WriteExternalState(ctx, "simplestate", "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{b64View})
+	require.EqualError(t, err, fmt.Sprintf("View with hash %s from address %s has already been imported", viewHashHex, fabricViewAddress))
+	err = interopcc.WriteExternalState(ctx, "simplestate", "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{b64ReencodedView})
+	require.EqualError(t, err, fmt.Sprintf("View with hash %s from address %s has already been imported", viewHashHex, fabricViewAddress))
+	require.Equal(t, invokeCount, chaincodeStub.InvokeChaincodeCallCount())
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// redaction contains the functions used to filter fields out of JSON responses to remote networks,
+// according to the includePaths and excludePaths of the access control rule that permits the request
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+)
+
+// jsonPathTree holds the JSON paths to include in a response, indexed by object key.
+// A nil subtree includes the whole value at the key.
+type jsonPathTree map[string]jsonPathTree
+
+// getRuleRedaction returns the redaction specified by an access control rule, or nil if the rule does not redact responses
+func getRuleRedaction(rule *common.Rule) *common.Redaction {
+	if rule == nil || (len(rule.IncludePaths) == 0 && len(rule.ExcludePaths) == 0) {
+		return nil
+	}
+	return &common.Redaction{IncludePaths: rule.IncludePaths, ExcludePaths: rule.ExcludePaths}
+}
+
+// validateJSONPath checks that a path is a dot-separated list of non-empty object keys
+func validateJSONPath(path string) error {
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return fmt.Errorf("Invalid JSON path %s", path)
+		}
+	}
+	return nil
+}
+
+// redactJSONPayload applies a redaction to a JSON response. The include paths are applied before the exclude paths.
+func redactJSONPayload(payload []byte, redaction *common.Redaction) ([]byte, error) {
+	if redaction == nil {
+		return payload, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	// Preserve numbers as they appear in the response
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to redact response that is not JSON: %s", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("Unable to redact response that is not JSON: unexpected data after JSON value")
+	}
+	if len(redaction.IncludePaths) > 0 {
+		tree := jsonPathTree{}
+		for _, path := range redaction.IncludePaths {
+			tree.add(strings.Split(path, "."))
+		}
+		value = includeJSONPaths(value, tree)
+	}
+	for _, path := range redaction.ExcludePaths {
+		excludeJSONPath(value, strings.Split(path, "."))
+	}
+	return json.Marshal(value)
+}
+
+// add adds a path to the tree. Paths to fields whose parent is already included are ignored.
+func (tree jsonPathTree) add(keys []string) {
+	subtree, ok := tree[keys[0]]
+	if ok && subtree == nil {
+		return
+	}
+	if len(keys) == 1 {
+		tree[keys[0]] = nil
+		return
+	}
+	if !ok {
+		subtree = jsonPathTree{}
+		tree[keys[0]] = subtree
+	}
+	subtree.add(keys[1:])
+}
+
+// includeJSONPaths returns the fields of a JSON value that are in the tree. Scalars have no fields, so nothing
+// is included from them.
+func includeJSONPaths(value interface{}, tree jsonPathTree) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, subtree := range tree {
+			field, ok := v[key]
+			if !ok {
+				continue
+			}
+			if subtree == nil {
+				result[key] = field
+			} else if included := includeJSONPaths(field, subtree); included != nil {
+				result[key] = included
+			}
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, element := range v {
+			if included := includeJSONPaths(element, tree); included != nil {
+				result = append(result, included)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// excludeJSONPath removes the field at a path from a JSON value
+func excludeJSONPath(value interface{}, keys []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(keys) == 1 {
+			delete(v, keys[0])
+		} else if field, ok := v[keys[0]]; ok {
+			excludeJSONPath(field, keys[1:])
+		}
+	case []interface{}:
+		for _, element := range v {
+			excludeJSONPath(element, keys)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactJSONPayload(t *testing.T) {
+	payload := []byte(`{"id":"a1","owner":{"name":"alice","account":"123"},"price":17.120,"lines":[{"sku":"x","cost":1},{"sku":"y","cost":2}]}`)
+
+	// Test: No redaction
+	redacted, err := redactJSONPayload(payload, nil)
+	require.NoError(t, err)
+	require.Equal(t, payload, redacted)
+
+	// Test: Include paths, including fields of array elements and overlapping paths
+	redacted, err = redactJSONPayload(payload, &common.Redaction{IncludePaths: []string{"id", "owner.name", "lines.sku", "owner", "missing.field"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"a1","owner":{"name":"alice","account":"123"},"lines":[{"sku":"x"},{"sku":"y"}]}`, string(redacted))
+	redacted, err = redactJSONPayload(payload, &common.Redaction{IncludePaths: []string{"id.value"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(redacted))
+
+	// Test: Exclude paths, with numbers preserved as they appear in the response
+	redacted, err = redactJSONPayload(payload, &common.Redaction{ExcludePaths: []string{"owner.account", "lines.cost", "missing.field"}})
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"a1","lines":[{"sku":"x"},{"sku":"y"}],"owner":{"name":"alice"},"price":17.120}`, string(redacted))
+
+	// Test: Include paths are applied before exclude paths
+	redacted, err = redactJSONPayload(payload, &common.Redaction{IncludePaths: []string{"owner"}, ExcludePaths: []string{"owner.account"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"owner":{"name":"alice"}}`, string(redacted))
+
+	// Test: Responses that are not JSON cannot be redacted
+	_, err = redactJSONPayload([]byte("17.12 apples"), &common.Redaction{ExcludePaths: []string{"price"}})
+	require.EqualError(t, err, "Unable to redact response that is not JSON: unexpected data after JSON value")
+	_, err = redactJSONPayload([]byte("apples"), &common.Redaction{ExcludePaths: []string{"price"}})
+	require.Error(t, err)
+}
+
+func TestGetRuleRedaction(t *testing.T) {
+	require.Nil(t, getRuleRedaction(nil))
+	require.Nil(t, getRuleRedaction(&common.Rule{Read: true}))
+	redaction := getRuleRedaction(&common.Rule{ExcludePaths: []string{"price"}})
+	require.Equal(t, []string{"price"}, redaction.ExcludePaths)
+	require.Empty(t, redaction.IncludePaths)
+
+	require.NoError(t, validateJSONPath("owner.name"))
+	require.EqualError(t, validateJSONPath("owner..name"), "Invalid JSON path owner..name")
+}
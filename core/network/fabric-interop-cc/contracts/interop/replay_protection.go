@@ -0,0 +1,261 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// replayprotection contains the checks that prevent a query from an external network from being
+// processed more than once, and the chaincode functions used to configure them
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	replayProtectionConfigKey   = "replayProtectionConfig"
+	queryNonceObjectType        = "queryNonce"
+	queryNonceExpiryObjectType  = "queryNonceExpiry"
+	defaultNonceWindowSecs      = uint64(24 * 60 * 60)
+	defaultMaxTimestampSkewSecs = uint64(5 * 60)
+	queryNoncePruneBatchSize    = 1000
+	queryNonceExpiryTimeFormat  = "%020d"
+)
+
+// ReplayProtectionConfig holds the parameters used to detect replayed queries.
+//
+// NonceWindowSecs is the period during which a nonce may not be reused by the same requestor. A value
+// of 0 means a nonce can never be reused, unless the query carries a signed timestamp.
+// MaxTimestampSkewSecs is the maximum difference allowed between the signed timestamp of a query
+// and the timestamp of the transaction processing it.
+// RequireTimestamp rejects queries that do not carry a signed timestamp.
+// RejectReimportedViews rejects WriteExternalState transactions importing a view that an earlier
+// transaction already imported, so that stale views cannot be replayed.
+type ReplayProtectionConfig struct {
+	NonceWindowSecs       uint64 `json:"nonceWindowSecs"`
+	MaxTimestampSkewSecs  uint64 `json:"maxTimestampSkewSecs"`
+	RequireTimestamp      bool   `json:"requireTimestamp"`
+	RejectReimportedViews bool   `json:"rejectReimportedViews"`
+}
+
+// SetReplayProtectionConfig cc is used to store the replay protection parameters in the ledger.
+// The client must be an admin of a local org.
+func (s *SmartContract) SetReplayProtectionConfig(ctx contractapi.TransactionContextInterface, configJSON string) error {
+	err := verifyClientIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	config, err := decodeReplayProtectionConfig([]byte(configJSON))
+	if err != nil {
+		return fmt.Errorf("Unmarshal error: %s", err)
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Marshal error: %s", err)
+	}
+	err = ctx.GetStub().PutState(replayProtectionConfigKey, configBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "SetReplayProtectionConfig", localSecurityDomain)
+}
+
+// GetReplayProtectionConfig cc returns the replay protection parameters in effect, which are
+// the defaults if none have been recorded in the ledger
+func (s *SmartContract) GetReplayProtectionConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := getReplayProtectionConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return string(configBytes), nil
+}
+
+func getReplayProtectionConfig(ctx contractapi.TransactionContextInterface) (*ReplayProtectionConfig, error) {
+	configBytes, err := ctx.GetStub().GetState(replayProtectionConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if configBytes == nil {
+		return &ReplayProtectionConfig{
+			NonceWindowSecs:      defaultNonceWindowSecs,
+			MaxTimestampSkewSecs: defaultMaxTimestampSkewSecs,
+		}, nil
+	}
+	config, err := decodeReplayProtectionConfig(configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshal error: %s", err)
+	}
+	return config, nil
+}
+
+// getQuerySignedMessage returns the message a requestor signs when submitting a query.
+// The timestamp is only part of the message when set, so queries from older clients still validate.
+// Invocations always contain a timestamp, and their message ends with a suffix that the message of a
+// query, which ends with the digits of the timestamp, cannot end with.
+func getQuerySignedMessage(query *common.Query) string {
+	if query.Timestamp == 0 {
+		return query.Address + query.Nonce
+	}
+	message := query.Address + query.Nonce + strconv.FormatUint(query.Timestamp, 10)
+	if query.Invoke {
+		message += invocationSignedMessageSuffix
+	}
+	return message
+}
+
+// verifyQueryNotReplayed checks that the signed timestamp of a query is within the allowed skew and that
+// its nonce has not been used by the requestor within the configured window. The nonce is then recorded
+// so later submissions of the same query are rejected.
+//
+// Nonces are recorded per requestor certificate, which is bound to the query by the signature, rather than
+// per requesting network or request ID, which are not signed. Each recorded nonce is also indexed by the time
+// after which it no longer needs to be remembered, so that PruneQueryNonces can remove it.
+func verifyQueryNotReplayed(ctx contractapi.TransactionContextInterface, query *common.Query, x509Cert *x509.Certificate) error {
+	config, err := getReplayProtectionConfig(ctx)
+	if err != nil {
+		return err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("Unable to get transaction timestamp: %s", err)
+	}
+	if txTimestamp == nil {
+		return fmt.Errorf("Transaction timestamp is not set")
+	}
+	txTimeSecs := uint64(txTimestamp.GetSeconds())
+
+	if query.Timestamp == 0 {
+		if config.RequireTimestamp {
+			return fmt.Errorf("Query does not contain a timestamp")
+		}
+	} else if absDiff(query.Timestamp, txTimeSecs) > config.MaxTimestampSkewSecs {
+		return fmt.Errorf("Query timestamp %d is outside the allowed skew of %d seconds from transaction time %d", query.Timestamp, config.MaxTimestampSkewSecs, txTimeSecs)
+	}
+
+	if query.Nonce == "" {
+		return fmt.Errorf("Query does not contain a nonce")
+	}
+	requestorHash := sha256.Sum256(x509Cert.Raw)
+	requestorID := hex.EncodeToString(requestorHash[:])
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(queryNonceObjectType, []string{requestorID, query.Nonce})
+	if err != nil {
+		return err
+	}
+	err = checkAndRecordQueryKey(ctx, nonceKey, txTimeSecs, config.NonceWindowSecs)
+	if err != nil {
+		return fmt.Errorf("Nonce %s has already been used by the requestor: %s", query.Nonce, err)
+	}
+
+	// A nonce is needed until it may be reused, or until the signed timestamp of the query is too old to be accepted
+	expirySecs := uint64(0)
+	if config.NonceWindowSecs != 0 {
+		expirySecs = txTimeSecs + config.NonceWindowSecs
+	}
+	if query.Timestamp != 0 && (expirySecs == 0 || query.Timestamp+config.MaxTimestampSkewSecs < expirySecs) {
+		expirySecs = query.Timestamp + config.MaxTimestampSkewSecs
+	}
+	if expirySecs == 0 {
+		return nil
+	}
+	expiryKey, err := ctx.GetStub().CreateCompositeKey(queryNonceExpiryObjectType, []string{fmt.Sprintf(queryNonceExpiryTimeFormat, expirySecs), requestorID, query.Nonce})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(expiryKey, []byte(strconv.FormatUint(txTimeSecs, 10)))
+}
+
+// PruneQueryNonces cc removes up to 1000 recorded nonces that are no longer needed to detect replayed queries,
+// and returns the number removed. Any client may prune nonces, as only expired ones are removed; nonces pruned
+// under a shorter window or timestamp skew than the one later configured are not restored.
+func (s *SmartContract) PruneQueryNonces(ctx contractapi.TransactionContextInterface) (int, error) {
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(queryNonceExpiryObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer iterator.Close()
+
+	pruned := 0
+	for iterator.HasNext() && pruned < queryNoncePruneBatchSize {
+		entry, err := iterator.Next()
+		if err != nil {
+			return pruned, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(entry.Key)
+		if err != nil {
+			return pruned, err
+		}
+		if len(attributes) != 3 {
+			return pruned, fmt.Errorf("Invalid nonce expiry key: %s", entry.Key)
+		}
+		expirySecs, err := strconv.ParseUint(attributes[0], 10, 64)
+		if err != nil {
+			return pruned, fmt.Errorf("Invalid nonce expiry time: %s", err)
+		}
+		// Entries are sorted by expiry time, so the remaining ones have not expired either
+		if expirySecs > uint64(txTimeSecs) {
+			break
+		}
+		nonceKey, err := ctx.GetStub().CreateCompositeKey(queryNonceObjectType, attributes[1:])
+		if err != nil {
+			return pruned, err
+		}
+		recordedBytes, err := ctx.GetStub().GetState(nonceKey)
+		if err != nil {
+			return pruned, err
+		}
+		// The nonce may have been recorded again after this entry, in which case a later entry removes it
+		if string(recordedBytes) == string(entry.Value) {
+			err = ctx.GetStub().DelState(nonceKey)
+			if err != nil {
+				return pruned, err
+			}
+		}
+		err = ctx.GetStub().DelState(entry.Key)
+		if err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// checkAndRecordQueryKey fails if the key was recorded within the window, else records it with the given time
+func checkAndRecordQueryKey(ctx contractapi.TransactionContextInterface, key string, txTimeSecs uint64, windowSecs uint64) error {
+	recordedBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return err
+	}
+	if recordedBytes != nil {
+		recordedTimeSecs, err := strconv.ParseUint(string(recordedBytes), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid recorded time: %s", err)
+		}
+		if windowSecs == 0 || txTimeSecs < recordedTimeSecs+windowSecs {
+			return fmt.Errorf("Previously seen at time %d", recordedTimeSecs)
+		}
+	}
+	return ctx.GetStub().PutState(key, []byte(strconv.FormatUint(txTimeSecs, 10)))
+}
+
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+)
+
+const replayTestTxTime = int64(1628500000)
+
+func getReplayTestQuery() *common.Query {
+	return &common.Query{
+		Address:           "localhost:9080/network1/mychannel:interop:Read:a",
+		RequestingNetwork: "network1",
+		Nonce:             "nonce",
+		RequestId:         "1234",
+	}
+}
+
+func TestSetReplayProtectionConfig(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	setMockClient(ctx, "Org1MSP", false)
+	interopcc := SmartContract{}
+
+	// Defaults are returned when no config is recorded
+	configJSON, err := interopcc.GetReplayProtectionConfig(ctx)
+	require.NoError(t, err)
+	var config ReplayProtectionConfig
+	require.NoError(t, json.Unmarshal([]byte(configJSON), &config))
+	require.Equal(t, defaultNonceWindowSecs, config.NonceWindowSecs)
+	require.Equal(t, defaultMaxTimestampSkewSecs, config.MaxTimestampSkewSecs)
+	require.False(t, config.RequireTimestamp)
+
+	// Only admins can change the config
+	err = interopcc.SetReplayProtectionConfig(ctx, `{"nonceWindowSecs":60,"maxTimestampSkewSecs":10,"requireTimestamp":true}`)
+	require.EqualError(t, err, "Client is not an admin of org Org1MSP")
+	require.Equal(t, 0, chaincodeStub.PutStateCallCount())
+
+	setMockClient(ctx, "Org1MSP", true)
+	err = interopcc.SetReplayProtectionConfig(ctx, `{"nonceWindowSecs":60,"maxTimestampSkewSecs":10,"requireTimestamp":true}`)
+	require.NoError(t, err)
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, replayProtectionConfigKey, key)
+
+	chaincodeStub.GetStateReturns(value, nil)
+	configJSON, err = interopcc.GetReplayProtectionConfig(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"nonceWindowSecs":60,"maxTimestampSkewSecs":10,"requireTimestamp":true,"rejectReimportedViews":false}`, configJSON)
+
+	err = interopcc.SetReplayProtectionConfig(ctx, `{"nonceWindow":60}`)
+	require.EqualError(t, err, `Unmarshal error: json: unknown field "nonceWindow"`)
+}
+
+func TestGetQuerySignedMessage(t *testing.T) {
+	query := getReplayTestQuery()
+	require.Equal(t, query.Address+query.Nonce, getQuerySignedMessage(query))
+	query.Timestamp = uint64(replayTestTxTime)
+	require.Equal(t, query.Address+query.Nonce+"1628500000", getQuerySignedMessage(query))
+}
+
+func TestVerifyQueryNotReplayed(t *testing.T) {
+	txTimestamp := &timestamp.Timestamp{Seconds: replayTestTxTime}
+	txTimeString := strconv.FormatInt(replayTestTxTime, 10)
+	requestorCert := &x509.Certificate{Raw: []byte("requestor")}
+	requestorHash := sha256.Sum256(requestorCert.Raw)
+	requestorID := hex.EncodeToString(requestorHash[:])
+
+	// Happy case. The nonce is recorded for the requestor and indexed by the time it expires at
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+	query := getReplayTestQuery()
+	query.Timestamp = uint64(replayTestTxTime - 30)
+	err := verifyQueryNotReplayed(ctx, query, requestorCert)
+	require.NoError(t, err)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, queryNonceObjectType+":"+requestorID+":nonce", key)
+	require.Equal(t, txTimeString, string(value))
+	key, value = chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, queryNonceExpiryObjectType+":00000000001628500270:"+requestorID+":nonce", key)
+	require.Equal(t, txTimeString, string(value))
+
+	// Untimestamped queries expire at the end of the window
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.NoError(t, err)
+	key, _ = chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, queryNonceExpiryObjectType+":00000000001628586400:"+requestorID+":nonce", key)
+
+	// Nonce already used within the window
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, []byte(strconv.FormatInt(replayTestTxTime-60, 10)), nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.EqualError(t, err, "Nonce nonce has already been used by the requestor: Previously seen at time 1628499940")
+	require.Equal(t, 0, chaincodeStub.PutStateCallCount())
+
+	// Nonce used before the window started can be reused
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, []byte(strconv.FormatInt(replayTestTxTime-int64(defaultNonceWindowSecs), 10)), nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.NoError(t, err)
+
+	// A window of 0 never allows reuse, and untimestamped nonces are then never pruned
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetStateReturnsOnCall(0, []byte(`{"nonceWindowSecs":0,"maxTimestampSkewSecs":300,"requireTimestamp":false}`), nil)
+	chaincodeStub.GetStateReturnsOnCall(1, []byte("0"), nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.EqualError(t, err, "Nonce nonce has already been used by the requestor: Previously seen at time 0")
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetStateReturnsOnCall(0, []byte(`{"nonceWindowSecs":0,"maxTimestampSkewSecs":300,"requireTimestamp":false}`), nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.NoError(t, err)
+	require.Equal(t, 1, chaincodeStub.PutStateCallCount())
+
+	// Timestamp outside the allowed skew
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	query = getReplayTestQuery()
+	query.Timestamp = uint64(replayTestTxTime + 301)
+	err = verifyQueryNotReplayed(ctx, query, requestorCert)
+	require.EqualError(t, err, "Query timestamp 1628500301 is outside the allowed skew of 300 seconds from transaction time 1628500000")
+
+	// Timestamp required but missing
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetStateReturnsOnCall(0, []byte(`{"nonceWindowSecs":60,"maxTimestampSkewSecs":300,"requireTimestamp":true}`), nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.EqualError(t, err, "Query does not contain a timestamp")
+
+	// Missing nonce
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	query = getReplayTestQuery()
+	query.Nonce = ""
+	err = verifyQueryNotReplayed(ctx, query, requestorCert)
+	require.EqualError(t, err, "Query does not contain a nonce")
+
+	// Transaction timestamp unavailable
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetTxTimestampReturns(nil, nil)
+	err = verifyQueryNotReplayed(ctx, getReplayTestQuery(), requestorCert)
+	require.EqualError(t, err, "Transaction timestamp is not set")
+}
+
+func TestPruneQueryNonces(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: replayTestTxTime}, nil)
+	chaincodeStub.SplitCompositeKeyCalls(func(key string) (string, []string, error) {
+		parts := strings.Split(key, ":")
+		return parts[0], parts[1:], nil
+	})
+	interopcc := SmartContract{}
+
+	// The first nonce has expired, the second was recorded again after its first use, the third has not expired
+	expiryEntries := []*queryresult.KV{
+		{Key: queryNonceExpiryObjectType + ":00000000001628499000:req:n1", Value: []byte("1628400000")},
+		{Key: queryNonceExpiryObjectType + ":00000000001628499500:req:n2", Value: []byte("1628400000")},
+		{Key: queryNonceExpiryObjectType + ":00000000001628500100:req:n3", Value: []byte("1628400000")},
+	}
+	worldState[queryNonceObjectType+":req:n1"] = []byte("1628400000")
+	worldState[queryNonceObjectType+":req:n2"] = []byte("1628499000")
+	worldState[queryNonceObjectType+":req:n3"] = []byte("1628400000")
+	iterator := &mocks.StateQueryIterator{}
+	for i, entry := range expiryEntries {
+		iterator.HasNextReturnsOnCall(i, true)
+		iterator.NextReturnsOnCall(i, entry, nil)
+	}
+	chaincodeStub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+	deleted := []string{}
+	chaincodeStub.DelStateCalls(func(key string) error {
+		deleted = append(deleted, key)
+		return nil
+	})
+
+	pruned, err := interopcc.PruneQueryNonces(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	require.Equal(t, []string{
+		queryNonceObjectType + ":req:n1",
+		expiryEntries[0].Key,
+		expiryEntries[1].Key,
+	}, deleted)
+}
@@ -11,19 +11,29 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern"
 )
 
 const verificationPolicyObjectType = "verificationPolicy"
 
+// signaturePolicyType is the only policy type currently supported. Policies with an empty type are treated as
+// signature policies for backward compatibility.
+const signaturePolicyType = "signature"
+
 // CreateVerificationPolicy cc is used to store a VerificationPolicy in the ledger
 func (s *SmartContract) CreateVerificationPolicy(ctx contractapi.TransactionContextInterface, verificationPolicyJSON string) error {
 	verificationPolicy, err := decodeVerificationPolicy([]byte(verificationPolicyJSON))
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
+	err = validateVerificationPolicyCriteria(verificationPolicy)
+	if err != nil {
+		return err
+	}
 	verificationPolicyKey, err := ctx.GetStub().CreateCompositeKey(verificationPolicyObjectType, []string{verificationPolicy.SecurityDomain})
 	acp, getErr := ctx.GetStub().GetState(verificationPolicyKey)
 	if getErr != nil {
@@ -37,7 +47,11 @@ func (s *SmartContract) CreateVerificationPolicy(ctx contractapi.TransactionCont
 	if err != nil {
 		return fmt.Errorf("Marshal error: %s", err)
 	}
-	return ctx.GetStub().PutState(verificationPolicyKey, verificationPolicyBytes)
+	err = ctx.GetStub().PutState(verificationPolicyKey, verificationPolicyBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "CreateVerificationPolicy", verificationPolicy.SecurityDomain)
 }
 
 // UpdateVerificationPolicy cc is used to update an existing VerificationPolicy in the ledger
@@ -46,6 +60,10 @@ func (s *SmartContract) UpdateVerificationPolicy(ctx contractapi.TransactionCont
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
+	err = validateVerificationPolicyCriteria(verificationPolicy)
+	if err != nil {
+		return err
+	}
 	verificationPolicyKey, err := ctx.GetStub().CreateCompositeKey(verificationPolicyObjectType, []string{verificationPolicy.SecurityDomain})
 	_, err = s.GetVerificationPolicyBySecurityDomain(ctx, verificationPolicy.SecurityDomain)
 	if err != nil {
@@ -56,7 +74,11 @@ func (s *SmartContract) UpdateVerificationPolicy(ctx contractapi.TransactionCont
 	if err != nil {
 		return fmt.Errorf("Marshal error: %s", err)
 	}
-	return ctx.GetStub().PutState(verificationPolicyKey, verificationPolicyBytes)
+	err = ctx.GetStub().PutState(verificationPolicyKey, verificationPolicyBytes)
+	if err != nil {
+		return err
+	}
+	return recordConfigChange(ctx, "UpdateVerificationPolicy", verificationPolicy.SecurityDomain)
 }
 
 // DeleteVerificationPolicy cc is used to delete an existing VerificationPolicy in the ledger
@@ -74,7 +96,7 @@ func (s *SmartContract) DeleteVerificationPolicy(ctx contractapi.TransactionCont
 		return fmt.Errorf("failed to delete asset %s: %v", verificationPolicyKey, err)
 	}
 
-	return nil
+	return recordConfigChange(ctx, "DeleteVerificationPolicy", verificationPolicyID)
 }
 
 // GetVerificationPolicyBySecurityDomain cc gets the VerificationPolicy for the provided id
@@ -99,6 +121,16 @@ func (s *SmartContract) GetVerificationPolicyBySecurityDomain(ctx contractapi.Tr
 // a Corda client wishes to receive the state for and looks up the corresponding endorsement policy
 // for the external network that needs to be satisfied in order for the response to be accepted.
 func resolvePolicy(s *SmartContract, ctx contractapi.TransactionContextInterface, securityDomain string, viewAddress string) (*common.Policy, error) {
+	identifier, err := resolveIdentifier(s, ctx, securityDomain, viewAddress)
+	if err != nil {
+		return nil, err
+	}
+	return identifier.Policy, nil
+}
+
+// resolveIdentifier looks up the identifier of the verification policy of the external network whose pattern
+// matches the viewAddress, which holds the endorsement policy and the maximum age of the view
+func resolveIdentifier(s *SmartContract, ctx contractapi.TransactionContextInterface, securityDomain string, viewAddress string) (*common.Identifier, error) {
 	// Find verification policy for the network
 	verificationPolicyString, err := s.GetVerificationPolicyBySecurityDomain(ctx, securityDomain)
 	if err != nil {
@@ -108,23 +140,54 @@ func resolvePolicy(s *SmartContract, ctx contractapi.TransactionContextInterface
 	if err != nil {
 		return nil, fmt.Errorf("Failed to unmarshal verification policy: %s", err.Error())
 	}
-	currentBestMatch := &common.Identifier{}
+	// pick the most specific pattern that matches the address, so that the same policy is resolved whatever the
+	// order of the identifiers
+	patterns := make([]string, len(verificationPolicy.Identifiers))
+	for i, identifier := range verificationPolicy.Identifiers {
+		patterns[i] = identifier.Pattern
+	}
+	bestMatch := addresspattern.MostSpecific(patterns, viewAddress)
+	if bestMatch != -1 {
+		return verificationPolicy.Identifiers[bestMatch], nil
+	}
+
+	return nil, fmt.Errorf("Verification Policy Error: Failed to find verification policy matching view address: %s", viewAddress)
+}
+
+// validateVerificationPolicyCriteria checks that the criteria of every identifier in the policy are
+// well-formed policy DSL expressions that cannot be satisfied by an empty set of signers
+func validateVerificationPolicyCriteria(verificationPolicy *common.VerificationPolicy) error {
 	for _, identifier := range verificationPolicy.Identifiers {
-		// short circuit if there is an exact match
-		if identifier.Pattern == viewAddress {
-			return identifier.Policy, nil
+		if identifier.Policy == nil {
+			return fmt.Errorf("Policy missing for identifier with pattern: %s", identifier.Pattern)
 		}
-
-		// check if the identifier pattern is valid, that it matches the address and it's longer (i.e. more specific) than the currentBestMatch
-		if validPatternString(identifier.Pattern) && isPatternAndAddressMatch(identifier.Pattern, viewAddress) && len(identifier.Pattern) > len(currentBestMatch.Pattern) {
-			currentBestMatch = identifier
+		expression, err := parsePolicyCriteria(identifier.Policy.Criteria)
+		if err != nil {
+			return fmt.Errorf("Invalid criteria for identifier with pattern %s: %s", identifier.Pattern, err.Error())
+		}
+		if expression.evaluate(map[string]bool{}) {
+			return fmt.Errorf("Invalid criteria for identifier with pattern %s: %s is satisfied without any signers", identifier.Pattern, expression)
 		}
 	}
+	return nil
+}
 
-	// return the bestMatch if there was one
-	if currentBestMatch.Pattern != "" {
-		return currentBestMatch.Policy, nil
+// verifyPolicySatisfied evaluates the policy criteria against the distinct set of orgs whose
+// signatures have been validated in a proof
+func verifyPolicySatisfied(verificationPolicy *common.Policy, signers []string) error {
+	if verificationPolicy.Type != "" && strings.ToLower(verificationPolicy.Type) != signaturePolicyType {
+		return fmt.Errorf("Verification policy type not supported: %s", verificationPolicy.Type)
 	}
-
-	return nil, fmt.Errorf("Verification Policy Error: Failed to find verification policy matching view address: %s", viewAddress)
+	expression, err := parsePolicyCriteria(verificationPolicy.Criteria)
+	if err != nil {
+		return err
+	}
+	signerSet := map[string]bool{}
+	for _, signer := range signers {
+		signerSet[signer] = true
+	}
+	if !expression.evaluate(signerSet) {
+		return fmt.Errorf("Notarizations from signers %v do not satisfy verification policy: %s", signers, expression)
+	}
+	return nil
 }
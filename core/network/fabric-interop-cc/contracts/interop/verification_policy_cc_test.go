@@ -105,3 +105,85 @@ func TestDeleteVerificationPolicy(t *testing.T) {
 	err = interopcc.DeleteVerificationPolicy(ctx, "2343")
 	require.EqualError(t, err, fmt.Sprintf("unable to retrieve asset"))
 }
+
+func TestVerifyPolicySatisfied(t *testing.T) {
+	// List-style criteria require every org to sign
+	listPolicy := &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP", "Org2MSP"}}
+	require.NoError(t, verifyPolicySatisfied(listPolicy, []string{"Org1MSP", "Org2MSP"}))
+	err := verifyPolicySatisfied(listPolicy, []string{"Org1MSP", "Org1MSP"})
+	require.EqualError(t, err, "Notarizations from signers [Org1MSP Org1MSP] do not satisfy verification policy: (Org1MSP && Org2MSP)")
+
+	// k-of-n policy counts distinct signers only
+	countPolicy := &common.Policy{Type: "signature", Criteria: []string{"count >= 2"}}
+	require.NoError(t, verifyPolicySatisfied(countPolicy, []string{"Org1MSP", "Org3MSP"}))
+	err = verifyPolicySatisfied(countPolicy, []string{"Org1MSP", "Org1MSP"})
+	require.EqualError(t, err, "Notarizations from signers [Org1MSP Org1MSP] do not satisfy verification policy: count >= 2")
+
+	// Policies without a type are treated as signature policies
+	require.NoError(t, verifyPolicySatisfied(&common.Policy{Criteria: []string{"Org1MSP || Org2MSP"}}, []string{"Org2MSP"}))
+
+	// Unsupported policy type
+	err = verifyPolicySatisfied(&common.Policy{Type: "ZKP", Criteria: []string{"Org1MSP"}}, []string{"Org1MSP"})
+	require.EqualError(t, err, "Verification policy type not supported: ZKP")
+}
+
+func TestCreateVerificationPolicyInvalidCriteria(t *testing.T) {
+	ctx, _ := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	invalidPolicy := common.VerificationPolicy{
+		SecurityDomain: "2345",
+		Identifiers: []*common.Identifier{{
+			Pattern: "mychannel:simplestate:Read:*",
+			Policy:  &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP &&"}},
+		}},
+	}
+	invalidPolicyBytes, err := json.Marshal(&invalidPolicy)
+	require.NoError(t, err)
+	err = interopcc.CreateVerificationPolicy(ctx, string(invalidPolicyBytes))
+	require.EqualError(t, err, "Invalid criteria for identifier with pattern mychannel:simplestate:Read:*: Invalid policy expression 'Org1MSP &&': Unexpected end of expression")
+
+	// Test: Criteria satisfied by an empty set of signers
+	invalidPolicy.Identifiers[0].Policy.Criteria = []string{"count < 2 || Org1MSP"}
+	invalidPolicyBytes, err = json.Marshal(&invalidPolicy)
+	require.NoError(t, err)
+	err = interopcc.CreateVerificationPolicy(ctx, string(invalidPolicyBytes))
+	require.EqualError(t, err, "Invalid criteria for identifier with pattern mychannel:simplestate:Read:*: (count < 2 || Org1MSP) is satisfied without any signers")
+}
+
+func TestResolvePolicy(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	identifiers := []*common.Identifier{
+		{Pattern: "mychannel:*", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP"}}},
+		{Pattern: "mychannel:simplestate:Read:*", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org2MSP"}}},
+		{Pattern: "mychannel:simplestate:Read:a", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org3MSP"}}},
+		{Pattern: "mychannel:simplestate:Re*", Policy: &common.Policy{Type: "Signature", Criteria: []string{"Org4MSP"}}},
+	}
+	// The most specific pattern is picked whatever the order of the identifiers
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}} {
+		verificationPolicy := common.VerificationPolicy{SecurityDomain: "2345"}
+		for _, i := range order {
+			verificationPolicy.Identifiers = append(verificationPolicy.Identifiers, identifiers[i])
+		}
+		verificationPolicyBytes, err := json.Marshal(&verificationPolicy)
+		require.NoError(t, err)
+		chaincodeStub.GetStateReturns(verificationPolicyBytes, nil)
+
+		resolvedPolicy, err := resolvePolicy(&interopcc, ctx, "2345", "mychannel:simplestate:Read:a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Org3MSP"}, resolvedPolicy.Criteria)
+		resolvedPolicy, err = resolvePolicy(&interopcc, ctx, "2345", "mychannel:simplestate:Read:b")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Org2MSP"}, resolvedPolicy.Criteria)
+		resolvedPolicy, err = resolvePolicy(&interopcc, ctx, "2345", "mychannel:simplestate:Remove:a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Org4MSP"}, resolvedPolicy.Criteria)
+		resolvedPolicy, err = resolvePolicy(&interopcc, ctx, "2345", "mychannel:otherstate:Read:a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Org1MSP"}, resolvedPolicy.Criteria)
+		_, err = resolvePolicy(&interopcc, ctx, "2345", "otherchannel:simplestate:Read:a")
+		require.EqualError(t, err, "Verification Policy Error: Failed to find verification policy matching view address: otherchannel:simplestate:Read:a")
+	}
+}
@@ -8,6 +8,7 @@ package main
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
@@ -16,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	log "github.com/sirupsen/logrus"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/besu"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/corda"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
@@ -26,16 +28,27 @@ type interop interface {
 	WriteExternalState(state string) error
 }
 
-// Extract data (i.e., query response) from view
-func ExtractDataFromView(view *common.View) ([]byte, error) {
+// extractInteropPayload extracts the interop payload signed by the remote network from view
+func extractInteropPayload(view *common.View) (*common.InteropPayload, error) {
 	var interopPayload common.InteropPayload
 	if view.Meta.Protocol == common.Meta_FABRIC {
-		var fabricViewData fabric.FabricView
-		err := protoV2.Unmarshal(view.Data, &fabricViewData)
-		if err != nil {
-			return nil, fmt.Errorf("FabricView Unmarshal error: %s", err)
+		var response *peer.Response
+		if view.Meta.ProofType == fabricCommitmentProofType {
+			var fabricCommitView fabric.FabricCommitView
+			err := protoV2.Unmarshal(view.Data, &fabricCommitView)
+			if err != nil {
+				return nil, fmt.Errorf("FabricCommitView Unmarshal error: %s", err)
+			}
+			response = fabricCommitView.Response
+		} else {
+			var fabricViewData fabric.FabricView
+			err := protoV2.Unmarshal(view.Data, &fabricViewData)
+			if err != nil {
+				return nil, fmt.Errorf("FabricView Unmarshal error: %s", err)
+			}
+			response = fabricViewData.Response
 		}
-		err = protoV2.Unmarshal(fabricViewData.Response.Payload, &interopPayload)
+		err := protoV2.Unmarshal(response.GetPayload(), &interopPayload)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
 		}
@@ -49,44 +62,107 @@ func ExtractDataFromView(view *common.View) ([]byte, error) {
 		if err != nil {
 			return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
 		}
+	} else if view.Meta.Protocol == common.Meta_BESU || view.Meta.Protocol == common.Meta_ETHEREUM {
+		var besuViewData besu.ViewData
+		err := protoV2.Unmarshal(view.Data, &besuViewData)
+		if err != nil {
+			return nil, fmt.Errorf("BesuView Unmarshal error: %s", err)
+		}
+		err = protoV2.Unmarshal(besuViewData.Payload, &interopPayload)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+		}
 	} else {
 		return nil, fmt.Errorf("Cannot extract data from view; unsupported DLT type: %+v", view.Meta.Protocol)
 	}
-	return interopPayload.Payload, nil
+	return &interopPayload, nil
+}
+
+// Extract data (i.e., query response) from view
+func ExtractDataFromView(view *common.View) ([]byte, error) {
+	interopPayload, err := extractInteropPayload(view)
+	if err != nil {
+		return nil, err
+	}
+	if interopPayload.Confidential {
+		var confidentialPayload common.ConfidentialPayload
+		err := protoV2.Unmarshal(interopPayload.Payload, &confidentialPayload)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to Unmarshal confidential payload: %s", err.Error())
+		}
+		contents, err := extractDecryptedContents(&confidentialPayload)
+		if err != nil {
+			return nil, err
+		}
+		return extractPrivateDataValue(view, interopPayload.Address, contents.Payload)
+	}
+	return extractPrivateDataValue(view, interopPayload.Address, interopPayload.Payload)
+}
+
+// extractPrivateDataValue returns the private value in the response to a Fabric view of a private data collection,
+// after checking it against the hash on the ledger. Responses to other views are returned as is.
+func extractPrivateDataValue(view *common.View, address string, payload []byte) ([]byte, error) {
+	if view.Meta.Protocol != common.Meta_FABRIC {
+		return payload, nil
+	}
+	collection := getPrivateDataCollection(address)
+	if collection == "" {
+		return payload, nil
+	}
+	privateDataPayload, err := verifyPrivateDataPayload(payload, collection)
+	if err != nil {
+		return nil, err
+	}
+	return privateDataPayload.Value, nil
 }
 
 // Validate view against address, and extract data (i.e., query response) from view
 func (s *SmartContract) ParseAndValidateView(ctx contractapi.TransactionContextInterface, address string, b64ViewProto string) ([]byte, error) {
+	viewData, _, err := parseAndValidateView(s, ctx, address, b64ViewProto)
+	return viewData, err
+}
+
+// parseAndValidateView validates view against address, and extracts data (i.e., query response) from view along
+// with the provenance of the view
+func parseAndValidateView(s *SmartContract, ctx contractapi.TransactionContextInterface, address string, b64ViewProto string) ([]byte, *ViewProvenance, error) {
 	viewB64Bytes, err := base64.StdEncoding.DecodeString(b64ViewProto)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to base64 decode data: %s", err.Error())
+		return nil, nil, fmt.Errorf("Unable to base64 decode data: %s", err.Error())
 	}
 	var view common.View
 	err = protoV2.Unmarshal(viewB64Bytes, &view)
 	if err != nil {
-		return nil, fmt.Errorf("View Unmarshal error: %s", err)
+		return nil, nil, fmt.Errorf("View Unmarshal error: %s", err)
 	}
 
 	// 1. Verify proof
-	err = s.VerifyView(ctx, b64ViewProto, address)
+	signers, err := verifyView(s, ctx, &view, address)
 	if err != nil {
 		log.Errorf("Proof obtained from foreign network for query '%s' is INVALID", address)
-		return nil, fmt.Errorf("VerifyView error: %s", err)
+		return nil, nil, fmt.Errorf("VerifyView error: %s", err)
 	}
 
 	// 2. Extract response data for consumption by application chaincode
 	viewData, err := ExtractDataFromView(&view)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	fmt.Printf("View data: %s\n", string(viewData))
 
-	return viewData, nil
+	addressStruct, err := parseAddress(address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to parse address: %s", err.Error())
+	}
+	provenance, err := newViewProvenance(&view, address, addressStruct.LedgerSegment, signers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return viewData, provenance, nil
 }
 
 // WriteExternalState flow is used to process a response from a foreign network for state.
 // 1. Verify Proofs that are returned
 // 2. Call application chaincode
+// 3. Record the provenance of the imported views, record them in the audit log and emit an event
 func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInterface, applicationID string, applicationChannel string, applicationFunction string, applicationArgs []string, argIndicesForSubstitution []int, addresses []string, b64ViewProtos []string) error {
 	if len(argIndicesForSubstitution) != len(addresses) {
 		return fmt.Errorf("Number of argument indices for substitution (%d) does not match number of addresses (%d)", len(argIndicesForSubstitution), len(addresses))
@@ -98,19 +174,26 @@ func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInt
 	arr := append([]string{applicationFunction}, applicationArgs...)
 
 	// 1. Verify proofs that are returned
+	provenances := []*ViewProvenance{}
 	for i, argIndex := range argIndicesForSubstitution {
 		// Validate argument index
 		if argIndex >= len(applicationArgs) {
 			return fmt.Errorf("Index %d out of bounds of array (length %d)", argIndex, len(applicationArgs))
 		}
 		// Validate proof and extract view data
-		viewData, err := s.ParseAndValidateView(ctx, addresses[i], b64ViewProtos[i])
+		viewData, provenance, err := parseAndValidateView(s, ctx, addresses[i], b64ViewProtos[i])
 		if err != nil {
 			return err
 		}
+		provenances = append(provenances, provenance)
 		// Substitute argument in list with view data
 		arr[argIndex + 1] = string(viewData)        // First argument is the CC function name
 	}
+	// Reject views that have already been imported, if configured to
+	err := verifyViewsNotImported(ctx, provenances)
+	if err != nil {
+		return err
+	}
 
 	// 2. Call application chaincode with created state as the argument
 	byteArgs := strArrToBytesArr(arr)
@@ -119,7 +202,49 @@ func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInt
 	if pbResp.Status != shim.OK {
 		return fmt.Errorf("Application chaincode invoke error: %s", string(pbResp.GetMessage()))
 	}
-	return nil
+
+	// 3. Record the provenance of each imported view, and record the views in the audit log with one entry per
+	// remote security domain
+	err = recordViewProvenances(ctx, applicationID, applicationChannel, applicationFunction, provenances)
+	if err != nil {
+		return err
+	}
+	return recordWriteExternalState(ctx, applicationID, applicationChannel, applicationFunction, addresses)
+}
+
+// recordWriteExternalState records the views imported by a WriteExternalState transaction in the audit log and
+// emits the corresponding event
+func recordWriteExternalState(ctx contractapi.TransactionContextInterface, applicationID string, applicationChannel string, applicationFunction string, addresses []string) error {
+	securityDomains := []string{}
+	addressesBySecurityDomain := map[string][]string{}
+	for _, address := range addresses {
+		addressStruct, err := parseAddress(address)
+		if err != nil {
+			return fmt.Errorf("Unable to parse address: %s", err.Error())
+		}
+		if _, ok := addressesBySecurityDomain[addressStruct.LedgerSegment]; !ok {
+			securityDomains = append(securityDomains, addressStruct.LedgerSegment)
+		}
+		addressesBySecurityDomain[addressStruct.LedgerSegment] = append(addressesBySecurityDomain[addressStruct.LedgerSegment], address)
+	}
+	entries := []*AuditLogEntry{}
+	for _, securityDomain := range securityDomains {
+		addressesJSON, err := json.Marshal(addressesBySecurityDomain[securityDomain])
+		if err != nil {
+			return fmt.Errorf("Marshal error: %s", err)
+		}
+		entry, err := newAuditLogEntry(ctx, writeExternalStateEventName, securityDomain, "", map[string]string{
+			"applicationId":       applicationID,
+			"applicationChannel":  applicationChannel,
+			"applicationFunction": applicationFunction,
+			"addresses":           string(addressesJSON),
+		})
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	return recordInteropEvent(ctx, writeExternalStateEventName, entries...)
 }
 
 // VerifyView takes a view that is returned from an external network and verifies
@@ -137,22 +262,81 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return fmt.Errorf("View Unmarshal error: %s", err)
 	}
+	_, err = verifyView(s, ctx, &view, address)
+	return err
+}
+
+// verifyView verifies the proof in view against address and returns the members of the remote network whose
+// proofs were accepted
+func verifyView(s *SmartContract, ctx contractapi.TransactionContextInterface, view *common.View, address string) ([]string, error) {
 	addressStruct, err := parseAddress(address)
 	if err != nil {
-		return fmt.Errorf("Unable to parse address: %s", err.Error())
+		return nil, fmt.Errorf("Unable to parse address: %s", err.Error())
 	}
 	// Find the verification policy for the network and view.
-	verificationPolicy, err := resolvePolicy(s, ctx, addressStruct.LedgerSegment, addressStruct.ViewSegment)
+	identifier, err := resolveIdentifier(s, ctx, addressStruct.LedgerSegment, addressStruct.ViewSegment)
 	if err != nil {
-		return fmt.Errorf("Unable to resolve verification policy: %s", err.Error())
+		return nil, fmt.Errorf("Unable to resolve verification policy: %s", err.Error())
 	}
+	signerList, err := verifyViewProof(s, ctx, view, identifier.Policy, addressStruct.LedgerSegment, address)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyViewFreshness(ctx, view, address, identifier.MaxAgeSecs)
+	if err != nil {
+		return nil, err
+	}
+	// Check the value of a private data collection against its hash on the ledger
+	if view.Meta.Protocol == common.Meta_FABRIC && getPrivateDataCollection(address) != "" {
+		_, err = ExtractDataFromView(view)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return signerList, nil
+}
+
+// verifyViewFreshness rejects views whose timestamp, which is covered by the proof, is older than the maximum age
+// set in the verification policy. Views without a timestamp are rejected if a maximum age is set.
+func verifyViewFreshness(ctx contractapi.TransactionContextInterface, view *common.View, address string, maxAgeSecs uint64) error {
+	if maxAgeSecs == 0 {
+		return nil
+	}
+	interopPayload, err := extractInteropPayload(view)
+	if err != nil {
+		return err
+	}
+	if interopPayload.Timestamp == 0 {
+		return fmt.Errorf("View from address %s does not carry a timestamp", address)
+	}
+	txTimeSecs, err := getTxTimeSecs(ctx)
+	if err != nil {
+		return err
+	}
+	config, err := getReplayProtectionConfig(ctx)
+	if err != nil {
+		return err
+	}
+	viewTimeSecs := int64(interopPayload.Timestamp)
+	if viewTimeSecs > txTimeSecs+int64(config.MaxTimestampSkewSecs) {
+		return fmt.Errorf("View from address %s has a timestamp in the future", address)
+	}
+	if txTimeSecs-viewTimeSecs > int64(maxAgeSecs) {
+		return fmt.Errorf("View from address %s is %d seconds old, exceeding the maximum age of %d seconds", address, txTimeSecs-viewTimeSecs, maxAgeSecs)
+	}
+	return nil
+}
+
+// verifyViewProof verifies the proof in view against the verification policy according to its protocol and proof
+// type, and returns the members of the remote network whose proofs were accepted
+func verifyViewProof(s *SmartContract, ctx contractapi.TransactionContextInterface, view *common.View, verificationPolicy *common.Policy, securityDomain string, address string) ([]string, error) {
 	switch view.Meta.Protocol {
 	case common.Meta_CORDA:
 		switch view.Meta.ProofType {
 		case "Notarization":
-			return verifyCordaNotarization(s, ctx, view.Data, verificationPolicy, addressStruct.LedgerSegment, address)
+			return verifyCordaNotarization(s, ctx, view.Data, verificationPolicy, securityDomain, address)
 		default:
-			return fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
 		}
 	case common.Meta_FABRIC:
 		switch view.Meta.ProofType {
@@ -162,13 +346,22 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 				ctx,
 				view.Data,
 				verificationPolicy,
-				addressStruct.LedgerSegment,
+				securityDomain,
 				address)
+		case fabricCommitmentProofType:
+			return verifyFabricCommitment(s, ctx, view.Data, verificationPolicy, securityDomain, address)
+		default:
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+		}
+	case common.Meta_BESU, common.Meta_ETHEREUM:
+		switch view.Meta.ProofType {
+		case "Notarization":
+			return verifyBesuNotarization(s, ctx, view.Data, verificationPolicy, securityDomain, address)
 		default:
-			return fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
 		}
 	default:
-		return fmt.Errorf("Verification Error: Unrecognised protocol %s", view.Meta.Protocol)
+		return nil, fmt.Errorf("Verification Error: Unrecognised protocol %s", view.Meta.Protocol)
 	}
 }
 
@@ -181,11 +374,14 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 // 3. Verify each of the signatures in the Notarization array according to the data bytes and certificate.
 // 4. Check the certificates are valid according to the Membership.
 // 5. Check the notarizations fulfill the verification policy of the request.
-func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain, address string) error {
+func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain, address string) ([]string, error) {
 	var cordaViewData corda.ViewData
 	err := protoV2.Unmarshal(data, &cordaViewData)
 	if err != nil {
-		return fmt.Errorf("Unable to decode corda view data: %s", err.Error())
+		return nil, fmt.Errorf("Unable to decode corda view data: %s", err.Error())
+	}
+	if len(cordaViewData.Notarizations) == 0 {
+		return nil, fmt.Errorf("Corda view has no notarizations")
 	}
 
 	signerList := []string{}
@@ -193,38 +389,39 @@ func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContex
 	for _, value := range cordaViewData.Notarizations {
 		x509Cert, err := parseCert(value.Certificate)
 		if err != nil {
-			return fmt.Errorf("Unable to parse certificate: %s", err.Error())
+			return nil, fmt.Errorf("Unable to parse certificate: %s", err.Error())
 		}
 		var interopPayload common.InteropPayload
 		err = protoV2.Unmarshal(cordaViewData.Payload, &interopPayload)
 		if err != nil {
-			return fmt.Errorf("Unable to decode corda view data: %s", err.Error())
+			return nil, fmt.Errorf("Unable to decode corda view data: %s", err.Error())
+		}
+		if interopPayload.Confidential {
+			return nil, fmt.Errorf("Confidential payloads are not supported in Corda views")
 		}
 		decodedSignature, err := base64.StdEncoding.DecodeString(value.Signature)
 		if err != nil {
-			return fmt.Errorf("Corda signature could not be decoded from base64: %s", err.Error())
+			return nil, fmt.Errorf("Corda signature could not be decoded from base64: %s", err.Error())
 		}
 		err = validateSignature(string(cordaViewData.Payload), x509Cert, string(decodedSignature))
 		if err != nil {
-			return fmt.Errorf("Unable to Validate Signature: %s", err.Error())
+			return nil, fmt.Errorf("Unable to Validate Signature: %s", err.Error())
 		}
 		signerList = append(signerList, value.Id)
 		// 4. Check the certificates are valid according to the Membership.
 		err = verifyMemberInSecurityDomain(s, ctx, x509Cert, securityDomain, value.Id)
 		if err != nil {
-			return fmt.Errorf("Verify membership failed. Certificate not valid: %s", err.Error())
+			return nil, fmt.Errorf("Verify membership failed. Certificate not valid: %s", err.Error())
 		}
 	}
 
 	// 5. Check the notarizations fulfill the verification policy of the request.
-	requiredSigners := verificationPolicy.Criteria
-	for _, signer := range requiredSigners {
-		if !Contains(signerList, signer) {
-			return fmt.Errorf("Notarizations missing signer: %s", signer)
-		}
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return nil, err
 	}
 	log.Infof("Proof associated with response '%s' from Corda network for query '%s' is VALID", string(cordaViewData.Payload), address)
-	return nil
+	return signerList, nil
 }
 
 // The verifyFabricNotarization function is used to verify views that come from a Fabric network
@@ -236,66 +433,153 @@ func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContex
 // 2. Verify address in payload is the same as original address
 // 3. Verify each of the endorser signatures in the ProposalResponse according to the response payload and certificate.
 // 4. Check each of the endorser certificates matches the member's entry in the network's Membership.
-// 5. Verify the response matches the response inside the ProposalResponsePayload chaincodeaction
+// 5. Verify the response matches the response inside the ProposalResponsePayload chaincodeaction. For confidential
+// responses, the decrypted contents must instead match the commitment in the signed response.
 // 6. Check the notarizations fulfill the verification policy of the request.
-func verifyFabricNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain string, address string) error {
+func verifyFabricNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain string, address string) ([]string, error) {
 	// 1. Ensure the response is in a valid format
 	var fabricViewData fabric.FabricView
 	err := protoV2.Unmarshal(data, &fabricViewData)
 	if err != nil {
-		return fmt.Errorf("Unable to decode fabric view data: %s", err.Error())
+		return nil, fmt.Errorf("Unable to decode fabric view data: %s", err.Error())
 	}
 	// 2. Verify address in payload is the same as original address
 	var interopPayload common.InteropPayload
 	err = protoV2.Unmarshal(fabricViewData.Response.Payload, &interopPayload)
 	if err != nil {
-		return fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+		return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
 	}
 	if address != interopPayload.Address {
-		return fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+		return nil, fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+	}
+	proposalResponsePayloadBytes, err := proto.Marshal(fabricViewData.ProposalResponsePayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal proposal response payload: %s", err.Error())
+	}
+	// 3. Verify each of the endorser signatures in the ProposalResponse according to the response payload and certificate.
+	// 4. Check each of the endorser certificates matches the member's entry in the network's Membership.
+	signerList, err := verifyFabricEndorsements(s, ctx, proposalResponsePayloadBytes, fabricViewData.Endorsements, securityDomain)
+	if err != nil {
+		return nil, err
+	}
+	// 5. Verify the response matches the response inside the ProposalResponsePayload chaincodeaction
+	err = verifyFabricChaincodeResponse(fabricViewData.ProposalResponsePayload.Extension, fabricViewData.Response.Payload, &interopPayload)
+	if err != nil {
+		return nil, err
+	}
+	// 6. Check the notarizations fulfill the verification policy of the request.
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Proof associated with response '%s' from Fabric network for query '%s' is VALID", string(fabricViewData.Response.Payload), address)
+	return signerList, nil
+}
+
+// verifyFabricEndorsements verifies the endorser signatures over a serialized ProposalResponsePayload and checks
+// the endorser certificates against the network's Membership. The orgs of the endorsers are returned.
+func verifyFabricEndorsements(s *SmartContract, ctx contractapi.TransactionContextInterface, proposalResponsePayloadBytes []byte, endorsements []*peer.Endorsement, securityDomain string) ([]string, error) {
+	if len(endorsements) == 0 {
+		return nil, fmt.Errorf("Fabric view has no endorsements")
 	}
 	signerList := []string{}
-	for _, endorsment := range fabricViewData.Endorsements {
+	for _, endorsment := range endorsements {
 		var serialisedIdentity msp.SerializedIdentity
 		err := proto.Unmarshal(endorsment.Endorser, &serialisedIdentity)
-		x509Cert, err := parseCert(string(serialisedIdentity.IdBytes))
 		if err != nil {
-			return fmt.Errorf("Unable to parse certificate: %s", err.Error())
+			return nil, fmt.Errorf("Unable to Unmarshal endorser identity: %s", err.Error())
 		}
-		proposalResponsePayloadBytes, err := proto.Marshal(fabricViewData.ProposalResponsePayload)
+		x509Cert, err := parseCert(string(serialisedIdentity.IdBytes))
 		if err != nil {
-			return fmt.Errorf("Unable to marshal proposal response payload: %s", err.Error())
+			return nil, fmt.Errorf("Unable to parse certificate: %s", err.Error())
 		}
-
-		// 3. Verify each of the endorser signatures in the ProposalResponse according to the response payload and certificate.
 		err = validateSignature(string(append(proposalResponsePayloadBytes, endorsment.Endorser...)), x509Cert, string(endorsment.Signature))
 		if err != nil {
-			return fmt.Errorf("Unable to Validate Signature: %s", err.Error())
+			return nil, fmt.Errorf("Unable to Validate Signature: %s", err.Error())
 		}
 		org := serialisedIdentity.Mspid
-		// 4. Check each of the endorser certificates matches the member's entry in the network's Membership.
 		err = verifyMemberInSecurityDomain(s, ctx, x509Cert, securityDomain, org)
 		if err != nil {
-			return fmt.Errorf("Verify membership failed. Certificate not valid: %s", err.Error())
+			return nil, fmt.Errorf("Verify membership failed. Certificate not valid: %s", err.Error())
 		}
 		signerList = append(signerList, org)
 	}
-	// 5. Verify the response matches the response inside the ProposalResponsePayload chaincodeaction
+	return signerList, nil
+}
+
+// verifyFabricChaincodeResponse checks that the response in a view matches the response of the ChaincodeAction in
+// the extension of a ProposalResponsePayload. For confidential responses, the decrypted contents must instead match
+// the commitment in the signed response.
+func verifyFabricChaincodeResponse(extension []byte, responsePayload []byte, interopPayload *common.InteropPayload) error {
 	var chaincodeAction peer.ChaincodeAction
-	err = proto.Unmarshal(fabricViewData.ProposalResponsePayload.Extension, &chaincodeAction)
+	err := proto.Unmarshal(extension, &chaincodeAction)
 	if err != nil {
 		return fmt.Errorf("Unable to Unmarshal ChaincodeAction: %s", err.Error())
 	}
-	if string(chaincodeAction.Response.Payload) != string(fabricViewData.Response.Payload) {
+	if interopPayload.Confidential {
+		// The view carries the decrypted contents, which are checked against the signed commitment
+		err = verifyConfidentialInteropPayload(chaincodeAction.Response.Payload, interopPayload)
+		if err != nil {
+			return fmt.Errorf("Confidential payload verification failed: %s", err.Error())
+		}
+	} else if string(chaincodeAction.Response.Payload) != string(responsePayload) {
 		return fmt.Errorf("Response in fabric view does not match response in proposal response")
 	}
-	// 6. Check the notarizations fulfill the verification policy of the request.
-	requiredSigners := verificationPolicy.Criteria
-	for _, signer := range requiredSigners {
-		if !Contains(signerList, signer) {
-			return fmt.Errorf("Notarizations missing signer: %s", signer)
+	return nil
+}
+
+// The verifyBesuNotarization function is used to verify views that come from a Besu network
+// that were generated with Notarization proofs.
+//
+// Verification requires the following steps:
+// 1. Create [BesuViewData] from the view.
+// 2. Verify address in payload is the same as original address
+// 3. Recover the validator address from each of the signatures over the payload.
+// 4. Check the recovered addresses match the validators' entries in the network's Membership.
+// 5. Check the notarizations fulfill the verification policy of the request.
+func verifyBesuNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain, address string) ([]string, error) {
+	// 1. Create [BesuViewData] from the view.
+	var besuViewData besu.ViewData
+	err := protoV2.Unmarshal(data, &besuViewData)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode besu view data: %s", err.Error())
+	}
+	// 2. Verify address in payload is the same as original address
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal(besuViewData.Payload, &interopPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+	}
+	if address != interopPayload.Address {
+		return nil, fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+	}
+	if interopPayload.Confidential {
+		return nil, fmt.Errorf("Confidential payloads are not supported in Besu views")
+	}
+	if len(besuViewData.Notarizations) == 0 {
+		return nil, fmt.Errorf("Besu view has no notarizations")
+	}
+
+	signerList := []string{}
+	for _, notarization := range besuViewData.Notarizations {
+		// 3. Recover the validator address from each of the signatures over the payload.
+		validatorAddress, err := recoverEthereumAddress(besuViewData.Payload, notarization.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to Validate Signature: %s", err.Error())
 		}
+		// 4. Check the recovered addresses match the validators' entries in the network's Membership.
+		err = verifyValidatorInSecurityDomain(s, ctx, validatorAddress, securityDomain, notarization.Id)
+		if err != nil {
+			return nil, fmt.Errorf("Verify membership failed. Validator not valid: %s", err.Error())
+		}
+		signerList = append(signerList, notarization.Id)
 	}
-	log.Infof("Proof associated with response '%s' from Fabric network for query '%s' is VALID", string(fabricViewData.Response.Payload), address)
-	return nil
+
+	// 5. Check the notarizations fulfill the verification policy of the request.
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Proof associated with response '%s' from Besu network for query '%s' is VALID", string(besuViewData.Payload), address)
+	return signerList, nil
 }
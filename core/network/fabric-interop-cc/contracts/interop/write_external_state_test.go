@@ -9,13 +9,19 @@
 package main
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/besu"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	protoV2 "google.golang.org/protobuf/proto"
 )
 
 var cordaB64View = `CjQIBBIcVHVlIE5vdiAxNyAwMDoxMzo0NiBHTVQgMjAyMBoMTm90YXJpemF0aW9uIgRKU09OEtYHCoQGClhhMjZHVW9WYythenlIMENUYjN2K2pTdmp3Y255M0hFd3AyMlJrdDkvZC9GcXN4WVVvYXhVWTdUOWNKRk9TVTZiVW42UFIwNmFVckxxdjZLbzZ1NG5CUT09Ep8FLS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUJ3akNDQVYrZ0F3SUJBZ0lJVUprUXZtS20zNVl3RkFZSUtvWkl6ajBFQXdJR0NDcUdTTTQ5QXdFSE1DOHgKQ3pBSkJnTlZCQVlUQWtkQ01ROHdEUVlEVlFRSERBWk1iMjVrYjI0eER6QU5CZ05WQkFvTUJsQmhjblI1UVRBZQpGdzB5TURBM01qUXdNREF3TURCYUZ3MHlOekExTWpBd01EQXdNREJhTUM4eEN6QUpCZ05WQkFZVEFrZENNUTh3CkRRWURWUVFIREFaTWIyNWtiMjR4RHpBTkJnTlZCQW9NQmxCaGNuUjVRVEFxTUFVR0F5dGxjQU1oQU1NS2FSRUsKaGNUZ1NCTU16Szgxb1BVU1BvVm1HL2ZKTUxYcS91alNtc2U5bzRHSk1JR0dNQjBHQTFVZERnUVdCQlJNWHREcwpLRlp6VUxkUTNjMkRDVUV4M1QxQ1VEQVBCZ05WSFJNQkFmOEVCVEFEQVFIL01Bc0dBMVVkRHdRRUF3SUNoREFUCkJnTlZIU1VFRERBS0JnZ3JCZ0VGQlFjREFqQWZCZ05WSFNNRUdEQVdnQlI0aHdMdUxnZklaTUVXekc0bjNBeHcKZmdQYmV6QVJCZ29yQmdFRUFZT0tZZ0VCQkFNQ0FRWXdGQVlJS29aSXpqMEVBd0lHQ0NxR1NNNDlBd0VIQTBjQQpNRVFDSUM3SjQ2U3hERHozTGpETnJFUGpqd1AycHJnTUVNaDdyL2dKcG91UUhCaytBaUErS3pYRDBkNW1pSTg2CkQybVlLNEMzdFJsaTNYM1ZnbkNlOENPcWZZeXVRZz09Ci0tLS0tRU5EIENFUlRJRklDQVRFLS0tLS0aBlBhcnR5QRLMAQpsW1NpbXBsZVN0YXRlKGtleT1ILCB2YWx1ZT0xLCBvd25lcj1PPVBhcnR5QSwgTD1Mb25kb24sIEM9R0IsIGxpbmVhcklkPTIzMTRkNmI3LTFlY2EtNDg5Mi04OGY4LTc2ZDg1YjhhODVjZCldElxsb2NhbGhvc3Q6OTA4MC9Db3JkYV9OZXR3b3JrL2xvY2FsaG9zdDoxMDAwNiNjb20uY29yZGFTaW1wbGVBcHBsaWNhdGlvbi5mbG93LkdldFN0YXRlQnlLZXk6SA==`
@@ -74,9 +80,14 @@ var network1VerificationPolicy = common.VerificationPolicy{
 	}},
 }
 
+// viewTxTime is a transaction time at which the certificates of the members that endorsed b64View and
+// cordaB64View are valid
+var viewTxTime = time.Date(2021, time.August, 9, 11, 20, 0, 0, time.UTC)
+
 func TestWriteExternalState(t *testing.T) {
 	// Happy case: Fabric
 	ctx, chaincodeStub := wtest.PrepMockStub()
+	wtest.SetMockStubTxTime(chaincodeStub, viewTxTime)
 	interopcc := SmartContract{}
 	// mock all the calls to the chaincode stub
 	network1VerificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy)
@@ -106,6 +117,7 @@ func TestWriteExternalState(t *testing.T) {
 
 	// Happy case: Corda
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	wtest.SetMockStubTxTime(chaincodeStub, viewTxTime)
 	interopcc = SmartContract{}
 	// mock all the calls to the chaincode stub
 	cordaVerificationPolicyBytes, err := json.Marshal(&cordaVerificationPolicy)
@@ -124,6 +136,7 @@ func TestWriteExternalState(t *testing.T) {
 
 	// Test case: Invalid cert in Membership
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	wtest.SetMockStubTxTime(chaincodeStub, viewTxTime)
 	interopcc = SmartContract{}
 	network1Membership.Members["Org1MSP"].Value = "invalid cert"
 	invalidMembershipBytes, err := json.Marshal(&network1Membership)
@@ -143,3 +156,169 @@ func TestWriteExternalState(t *testing.T) {
 	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{b64View})
 	require.EqualError(t, err, "VerifyView error: Unable to resolve verification policy: Verification Policy Error: Failed to find verification policy matching view address: " + fabricPattern)
 }
+
+// createBesuView creates a Besu view with the payload notarized by each of the validators
+func createBesuView(t *testing.T, address string, payload []byte, validators map[string]*secp256k1.PrivateKey) string {
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: address, Payload: payload})
+	require.NoError(t, err)
+	besuViewData := besu.ViewData{Payload: interopPayloadBytes}
+	for id, privateKey := range validators {
+		besuViewData.Notarizations = append(besuViewData.Notarizations, &besu.ViewData_Notarization{
+			Signature: signEthereumMessage(privateKey, interopPayloadBytes),
+			Id:        id,
+		})
+	}
+	besuViewDataBytes, err := protoV2.Marshal(&besuViewData)
+	require.NoError(t, err)
+	viewBytes, err := protoV2.Marshal(&common.View{
+		Meta: &common.Meta{Protocol: common.Meta_BESU, ProofType: "Notarization", SerializationFormat: "STRING"},
+		Data: besuViewDataBytes,
+	})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(viewBytes)
+}
+
+func TestVerifyBesuView(t *testing.T) {
+	besuNetwork := "besu_network"
+	besuPattern := "0x1234:getBalance:alice"
+	besuViewAddress := "relay-besu:9085/" + besuNetwork + "/" + besuPattern
+	validator1, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	validator2, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	besuMembership := common.Membership{
+		SecurityDomain: besuNetwork,
+		Members: map[string]*common.Member{
+			"validator1": {Value: getEthereumAddress(validator1), Type: ethereumAddressMemberType},
+			"validator2": {Value: getEthereumAddress(validator2), Type: ethereumAddressMemberType},
+		},
+	}
+	besuMembershipBytes, err := json.Marshal(&besuMembership)
+	require.NoError(t, err)
+	besuVerificationPolicyBytes, err := json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: besuNetwork,
+		Identifiers: []*common.Identifier{{
+			Pattern: besuPattern,
+			Policy:  &common.Policy{Criteria: []string{"count >= 2"}, Type: "signature"},
+		}},
+	})
+	require.NoError(t, err)
+	interopcc := SmartContract{}
+
+	// Happy case
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	b64BesuView := createBesuView(t, besuViewAddress, []byte("100"), map[string]*secp256k1.PrivateKey{"validator1": validator1, "validator2": validator2})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.NoError(t, err)
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	viewData, err := interopcc.ParseAndValidateView(ctx, besuViewAddress, b64BesuView)
+	require.NoError(t, err)
+	require.Equal(t, []byte("100"), viewData)
+
+	// Policy not satisfied by a single validator
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	b64BesuView = createBesuView(t, besuViewAddress, []byte("100"), map[string]*secp256k1.PrivateKey{"validator1": validator1})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.EqualError(t, err, "Notarizations from signers [validator1] do not satisfy verification policy: count >= 2")
+
+	// View without notarizations
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	b64BesuView = createBesuView(t, besuViewAddress, []byte("100"), map[string]*secp256k1.PrivateKey{})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.EqualError(t, err, "Besu view has no notarizations")
+
+	// Signature by a key that does not belong to the claimed validator
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	b64BesuView = createBesuView(t, besuViewAddress, []byte("100"), map[string]*secp256k1.PrivateKey{"validator1": validator2})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("Verify membership failed. Validator not valid: Validator address %s does not match member address %s", getEthereumAddress(validator2), getEthereumAddress(validator1)))
+
+	// Validator missing from the Membership
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturns(besuMembershipBytes, nil)
+	b64BesuView = createBesuView(t, besuViewAddress, []byte("100"), map[string]*secp256k1.PrivateKey{"validator3": validator1})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.EqualError(t, err, "Verify membership failed. Validator not valid: Member does not exist for validator: validator3")
+
+	// Address in the payload does not match the requested address
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	b64BesuView = createBesuView(t, besuViewAddress+"x", []byte("100"), map[string]*secp256k1.PrivateKey{"validator1": validator1})
+	err = interopcc.VerifyView(ctx, b64BesuView, besuViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("Address in response does not match original address: Original: %s Response: %sx", besuViewAddress, besuViewAddress))
+}
+
+func TestVerifyViewFreshness(t *testing.T) {
+	ctx, chaincodeStub, worldState, _ := prepGovernanceMockStub()
+	interopcc := SmartContract{}
+	endorser := newCommitProofSigner(t, "Org1MSP", 1)
+	orderer := newCommitProofSigner(t, "OrdererMSP", 2)
+	membershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: fabricNetwork,
+		Members: map[string]*common.Member{
+			"Org1MSP":    {Value: endorser.certPEM, Type: "ca"},
+			"OrdererMSP": {Value: orderer.certPEM, Type: "ca"},
+		},
+	})
+	require.NoError(t, err)
+	worldState["membership:"+fabricNetwork] = membershipBytes
+	verificationPolicyBytes, err := json.Marshal(&common.VerificationPolicy{
+		SecurityDomain: fabricNetwork,
+		Identifiers: []*common.Identifier{
+			{Pattern: fabricPattern, Policy: &common.Policy{Criteria: []string{"Org1MSP"}, Type: "signature"}, MaxAgeSecs: 60},
+			{Pattern: "mychannel:simplestate:*", Policy: &common.Policy{Criteria: []string{"Org1MSP"}, Type: "signature"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, interopcc.CreateVerificationPolicy(ctx, string(verificationPolicyBytes)))
+	// createView returns a base64 encoded view for an address, stamped with a timestamp
+	createView := func(address string, timestamp time.Time) string {
+		var unixTimestamp uint64
+		if !timestamp.IsZero() {
+			unixTimestamp = uint64(timestamp.Unix())
+		}
+		interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Address: address, Payload: []byte("Arcturus"), Timestamp: unixTimestamp})
+		require.NoError(t, err)
+		commitViewBytes, err := protoV2.Marshal(createFabricCommitView(t, "mychannel", interopPayloadBytes, endorser, orderer))
+		require.NoError(t, err)
+		viewBytes, err := protoV2.Marshal(&common.View{
+			Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: fabricCommitmentProofType},
+			Data: commitViewBytes,
+		})
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(viewBytes)
+	}
+	viewTime := time.Now().Truncate(time.Second)
+
+	// Test: Views within the maximum age are accepted
+	wtest.SetMockStubTxTime(chaincodeStub, viewTime.Add(60*time.Second))
+	require.NoError(t, interopcc.VerifyView(ctx, createView(fabricViewAddress, viewTime), fabricViewAddress))
+
+	// Test: Stale views are rejected
+	wtest.SetMockStubTxTime(chaincodeStub, viewTime.Add(61*time.Second))
+	err = interopcc.VerifyView(ctx, createView(fabricViewAddress, viewTime), fabricViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("View from address %s is 61 seconds old, exceeding the maximum age of 60 seconds", fabricViewAddress))
+
+	// Test: Views without a timestamp or with a timestamp in the future are rejected
+	err = interopcc.VerifyView(ctx, createView(fabricViewAddress, time.Time{}), fabricViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("View from address %s does not carry a timestamp", fabricViewAddress))
+	wtest.SetMockStubTxTime(chaincodeStub, viewTime)
+	err = interopcc.VerifyView(ctx, createView(fabricViewAddress, viewTime.Add(10*time.Minute)), fabricViewAddress)
+	require.EqualError(t, err, fmt.Sprintf("View from address %s has a timestamp in the future", fabricViewAddress))
+
+	// Test: Views of patterns without a maximum age are accepted whatever their age
+	otherAddress := fabricRelayEndpoint + "/" + fabricNetwork + "/mychannel:simplestate:Read:b"
+	wtest.SetMockStubTxTime(chaincodeStub, viewTime.Add(30*time.Minute))
+	require.NoError(t, interopcc.VerifyView(ctx, createView(otherAddress, time.Time{}), otherAddress))
+}
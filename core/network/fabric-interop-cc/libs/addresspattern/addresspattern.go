@@ -0,0 +1,145 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// addresspattern matches view segments of addresses (see rfcs/formats/views/addressing.md) against the patterns
+// used in access control and verification policies. It is shared by the interop chaincode and the SDKs, so that
+// both resolve the same policy for an address.
+//
+// A pattern is a view segment whose ':'-separated segments are matched in order against those of an address:
+//   - a literal segment matches the same segment, e.g., "mychannel:simpleasset:Read:a"
+//   - a "*" segment matches any single segment, e.g., "mychannel:*:Read:a"
+//   - a segment ending in "*" matches a segment with that prefix, e.g., "mychannel:simpleasset:Read*:a"
+//
+// If the last segment of a pattern ends in "*", it also matches any further segments of the address, so that
+// "mychannel:simpleasset:Read:*" matches calls to Read with any (non-empty) arguments and
+// "mychannel:simpleasset:Read*" matches calls to any function whose name starts with Read.
+// '*' may only appear at the end of a segment.
+package addresspattern
+
+import (
+	"strings"
+)
+
+const (
+	separator = ":"
+	wildcard  = "*"
+)
+
+// segment kinds, in increasing order of specificity
+const (
+	anySegment = iota
+	prefixSegment
+	literalSegment
+)
+
+type patternSegment struct {
+	kind  int
+	value string
+}
+
+// parse splits a pattern into segments, returning false if the pattern is invalid
+func parse(pattern string) ([]patternSegment, bool) {
+	segments := []patternSegment{}
+	for _, segment := range strings.Split(pattern, separator) {
+		numStars := strings.Count(segment, wildcard)
+		switch {
+		case numStars == 0:
+			segments = append(segments, patternSegment{kind: literalSegment, value: segment})
+		case numStars > 1 || !strings.HasSuffix(segment, wildcard):
+			return nil, false
+		case segment == wildcard:
+			segments = append(segments, patternSegment{kind: anySegment})
+		default:
+			segments = append(segments, patternSegment{kind: prefixSegment, value: strings.TrimSuffix(segment, wildcard)})
+		}
+	}
+	return segments, true
+}
+
+// IsValid checks if a pattern is well formed, i.e., '*' only appears at the end of segments
+func IsValid(pattern string) bool {
+	_, ok := parse(pattern)
+	return ok
+}
+
+// Match checks if the view segment of an address matches a pattern
+func Match(pattern string, address string) bool {
+	segments, ok := parse(pattern)
+	if !ok {
+		return false
+	}
+	addressSegments := strings.Split(address, separator)
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if i >= len(addressSegments) {
+			return false
+		}
+		switch segment.kind {
+		case literalSegment:
+			if addressSegments[i] != segment.value {
+				return false
+			}
+		case prefixSegment:
+			if !strings.HasPrefix(addressSegments[i], segment.value) {
+				return false
+			}
+		}
+		if last && segment.kind != literalSegment {
+			// A trailing wildcard matches any further segments
+			return true
+		}
+	}
+	return len(addressSegments) == len(segments)
+}
+
+// Compare orders patterns by specificity. It returns a positive number if pattern1 is more specific than
+// pattern2, a negative number if it is less specific and 0 if they are the same.
+//
+// Segments are compared from left to right: a literal segment is more specific than a prefix, a longer prefix
+// is more specific than a shorter one, and a prefix is more specific than "*". If all segments compare equal,
+// the pattern with more segments is more specific. Remaining ties are broken by comparing the patterns as
+// strings, so that the ordering is deterministic.
+func Compare(pattern1 string, pattern2 string) int {
+	segments1, ok1 := parse(pattern1)
+	segments2, ok2 := parse(pattern2)
+	if !ok1 || !ok2 {
+		// Invalid patterns never match, so they are the least specific
+		if ok1 != ok2 {
+			if ok1 {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(pattern2, pattern1)
+	}
+	for i := 0; i < len(segments1) && i < len(segments2); i++ {
+		if segments1[i].kind != segments2[i].kind {
+			return segments1[i].kind - segments2[i].kind
+		}
+		if segments1[i].kind == prefixSegment && len(segments1[i].value) != len(segments2[i].value) {
+			return len(segments1[i].value) - len(segments2[i].value)
+		}
+	}
+	if len(segments1) != len(segments2) {
+		return len(segments1) - len(segments2)
+	}
+	// Prefer the lexicographically smaller pattern
+	return strings.Compare(pattern2, pattern1)
+}
+
+// MostSpecific returns the index of the most specific of the patterns that match an address, or -1 if none match
+func MostSpecific(patterns []string, address string) int {
+	best := -1
+	for i, pattern := range patterns {
+		if !Match(pattern, address) {
+			continue
+		}
+		if best == -1 || Compare(pattern, patterns[best]) > 0 {
+			best = i
+		}
+	}
+	return best
+}
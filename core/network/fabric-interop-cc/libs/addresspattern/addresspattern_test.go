@@ -0,0 +1,86 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package addresspattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValid(t *testing.T) {
+	// Patterns without '*', or with '*' only at the end of segments, are valid
+	require.True(t, IsValid("abcd"))
+	require.True(t, IsValid("abcd*"))
+	require.True(t, IsValid("*"))
+	require.True(t, IsValid("mychannel:*:Read*:a"))
+
+	// '*' may appear only once per segment, at its end
+	require.False(t, IsValid("ab*cd*"))
+	require.False(t, IsValid("ab*cd"))
+	require.False(t, IsValid("mychannel:**:Read:a"))
+	require.False(t, IsValid("mychannel:*cc:Read:a"))
+}
+
+func TestMatch(t *testing.T) {
+	address := "mychannel:simpleasset:Read:a"
+
+	// Literal segments match the same segments
+	require.True(t, Match("mychannel:simpleasset:Read:a", address))
+	require.False(t, Match("mychannel:simpleasset:Read:b", address))
+	require.False(t, Match("mychannel:simpleasset:Read", address))
+	require.False(t, Match("mychannel:simpleasset:Read:a:b", address))
+
+	// "*" matches any single segment, and a trailing "*" any further segments
+	require.True(t, Match("mychannel:*:Read:a", address))
+	require.False(t, Match("otherchannel:*:Read:a", address))
+	require.True(t, Match("mychannel:simpleasset:Read:*", address))
+	require.True(t, Match("mychannel:simpleasset:*", address))
+	require.True(t, Match("mychannel:simpleasset:Read:*", "mychannel:simpleasset:Read:a:b"))
+	require.False(t, Match("mychannel:simpleasset:Read:*", "mychannel:simpleasset:Read"))
+
+	// Segments ending in "*" match prefixes
+	require.True(t, Match("mychannel:simpleasset:Re*:a", address))
+	require.False(t, Match("mychannel:simpleasset:Wr*:a", address))
+	require.True(t, Match("mychannel:simpleasset:Read*", "mychannel:simpleasset:ReadAsset:a:b"))
+
+	// Invalid patterns never match
+	require.False(t, Match("mychannel:simple*asset:Read:a", address))
+}
+
+func TestCompare(t *testing.T) {
+	// Literal segments are more specific than prefixes, longer prefixes than shorter ones, and prefixes than "*"
+	require.Positive(t, Compare("mychannel:simpleasset:Read:a", "mychannel:simpleasset:Read:*"))
+	require.Positive(t, Compare("mychannel:simpleasset:Rea*", "mychannel:simpleasset:R*"))
+	require.Positive(t, Compare("mychannel:simpleasset:R*", "mychannel:simpleasset:*"))
+	require.Negative(t, Compare("mychannel:*:Read:a", "mychannel:simpleasset:*"))
+
+	// Segments are compared before the number of segments
+	require.Positive(t, Compare("mychannel:simpleasset:Read:*", "mychannel:simpleasset:*"))
+	require.Positive(t, Compare("mychannel:simpleasset:*", "mychannel:*:Read:a"))
+
+	// Valid patterns are more specific than invalid ones, and ties are broken deterministically
+	require.Positive(t, Compare("mychannel:*", "mychannel:a*b"))
+	require.Equal(t, 0, Compare("mychannel:*", "mychannel:*"))
+	require.Positive(t, Compare("mychannel:a:*", "mychannel:b:*"))
+	require.Negative(t, Compare("mychannel:b:*", "mychannel:a:*"))
+}
+
+func TestMostSpecific(t *testing.T) {
+	patterns := []string{
+		"mychannel:*",
+		"mychannel:simpleasset:Read:*",
+		"mychannel:simpleasset:*",
+		"mychannel:simpleasset:Read:b",
+	}
+	require.Equal(t, 1, MostSpecific(patterns, "mychannel:simpleasset:Read:a"))
+	require.Equal(t, 3, MostSpecific(patterns, "mychannel:simpleasset:Read:b"))
+	require.Equal(t, 2, MostSpecific(patterns, "mychannel:simpleasset:Write:a"))
+	require.Equal(t, 0, MostSpecific(patterns, "mychannel:other:Read:a"))
+	require.Equal(t, -1, MostSpecific(patterns, "otherchannel:simpleasset:Read:a"))
+	require.Equal(t, -1, MostSpecific(nil, "mychannel:simpleasset:Read:a"))
+}
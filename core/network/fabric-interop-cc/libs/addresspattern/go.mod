@@ -0,0 +1,9 @@
+module github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern
+
+go 1.16
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776 // indirect
+)
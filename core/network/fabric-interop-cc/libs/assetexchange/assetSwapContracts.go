@@ -10,6 +10,7 @@ package assetexchange
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -23,11 +24,14 @@ import (
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	mspProtobuf "github.com/hyperledger/fabric-protos-go/msp"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/sha3"
 )
 
 // Object used to capture the HashLock details used in Asset Locking
+// (locks recorded without a hash mechanism use SHA256, the zero value of common.HashMechanism)
 type HashLock struct {
-	HashBase64 string `json:"hashBase64"`
+	HashBase64    string               `json:"hashBase64"`
+	HashMechanism common.HashMechanism `json:"hashMechanism"`
 }
 
 // Object used in the map, <asset-type, asset-id> --> <contractId, locker, recipient, ...> (for non-fungible assets)
@@ -56,6 +60,36 @@ type FungibleAssetLockValue struct {
 	ExpiryTimeSecs uint64      `json:"expiryTimeSecs"`
 }
 
+// Names of the events emitted when assets are locked, claimed or unlocked
+const (
+	LockAssetEventName           = "LockAsset"
+	LockSharedAssetEventName     = "LockSharedAsset"
+	LockFungibleAssetEventName   = "LockFungibleAsset"
+	ClaimAssetEventName          = "ClaimAsset"
+	ClaimSharedAssetEventName    = "ClaimSharedAsset"
+	ClaimFungibleAssetEventName  = "ClaimFungibleAsset"
+	UnlockAssetEventName         = "UnlockAsset"
+	UnlockSharedAssetEventName   = "UnlockSharedAsset"
+	UnlockFungibleAssetEventName = "UnlockFungibleAsset"
+)
+
+// Object used as the payload of the events emitted when assets are locked, claimed or unlocked.
+// Only the fields known to the operation are set; e.g., the asset type and ID are not known when
+// an asset is claimed or unlocked using its contractId.
+type AssetExchangeEvent struct {
+	ContractId         string   `json:"contractId"`
+	Type               string   `json:"type,omitempty"`
+	Id                 string   `json:"id,omitempty"`
+	NumUnits           uint64   `json:"numUnits,omitempty"`
+	Locker             string   `json:"locker,omitempty"`
+	Recipient          string   `json:"recipient,omitempty"`
+	Lockers            []string `json:"lockers,omitempty"`
+	Recipients         []string `json:"recipients,omitempty"`
+	HashBase64         string   `json:"hashBase64,omitempty"`
+	HashPreimageBase64 string   `json:"hashPreimageBase64,omitempty"`
+	ExpiryTimeSecs     uint64   `json:"expiryTimeSecs,omitempty"`
+}
+
 const (
 	assetKeyPrefix    = "AssetKey_"   // prefix for the map, asset-key --> asset-object
 	assetKeyDelimiter = "_"           // delimiter for the asset-key
@@ -71,6 +105,72 @@ func logThenErrorf(format string, args ...interface{}) error {
 	return errors.New(errorMsg)
 }
 
+// txTimeSkewToleranceSecs is the largest difference allowed between the timestamp of a transaction and the
+// clock of the endorsing peer, or 0 if it is not checked
+var txTimeSkewToleranceSecs uint64
+
+// SetTxTimeSkewTolerance sets the largest difference (in seconds) allowed between the timestamp of a transaction
+// and the clock of the endorsing peer. The timestamp is set by the submitting client, so a tolerance prevents
+// clients from moving it to get around lock expiry times; peers whose clock differs from the timestamp by more
+// than the tolerance refuse to endorse the transaction. A tolerance of 0 (the default) disables the check.
+func SetTxTimeSkewTolerance(toleranceSecs uint64) {
+	txTimeSkewToleranceSecs = toleranceSecs
+}
+
+// GetTxTimeSecs returns the timestamp of the transaction in seconds since the epoch. Unlike the clock of the
+// endorsing peer it is the same on all peers, so expiry decisions based on it are deterministic.
+func GetTxTimeSecs(ctx contractapi.TransactionContextInterface) (uint64, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get transaction timestamp: %+v", err)
+	}
+	if txTimestamp == nil || txTimestamp.GetSeconds() < 0 {
+		return 0, fmt.Errorf("transaction timestamp is not set")
+	}
+	txTimeSecs := uint64(txTimestamp.GetSeconds())
+	if txTimeSkewToleranceSecs > 0 {
+		peerTimeSecs := uint64(time.Now().Unix())
+		if txTimeSecs > peerTimeSecs+txTimeSkewToleranceSecs || peerTimeSecs > txTimeSecs+txTimeSkewToleranceSecs {
+			return 0, fmt.Errorf("transaction timestamp %d differs from the peer time %d by more than %d seconds", txTimeSecs, peerTimeSecs, txTimeSkewToleranceSecs)
+		}
+	}
+	return txTimeSecs, nil
+}
+
+// function to emit an event recording an asset exchange operation
+func emitAssetExchangeEvent(ctx contractapi.TransactionContextInterface, eventName string, event *AssetExchangeEvent) error {
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return logThenErrorf("marshal error: %+v", err)
+	}
+	err = ctx.GetStub().SetEvent(eventName, eventBytes)
+	if err != nil {
+		return logThenErrorf("failed to set event %s: %+v", eventName, err)
+	}
+	return nil
+}
+
+// function to get the hash from the lock information of an HTLC lock
+func getHashFromLockInfo(lockInfo interface{}) string {
+	if hashLock, ok := lockInfo.(HashLock); ok {
+		return hashLock.HashBase64
+	}
+	return ""
+}
+
+// function to get the hash preimage revealed by the claim information of an HTLC claim
+func getHashPreimageFromClaimInfo(claimInfo *common.AssetClaim) string {
+	if claimInfo.LockMechanism != common.LockMechanism_HTLC {
+		return ""
+	}
+	claimInfoHTLC := &common.AssetClaimHTLC{}
+	err := proto.Unmarshal(claimInfo.ClaimInfo, claimInfoHTLC)
+	if err != nil {
+		return ""
+	}
+	return string(claimInfoHTLC.HashPreimageBase64)
+}
+
 // function to generate a "SHA256" hash in base64 format for a given preimage
 func GenerateSHA256HashInBase64Form(preimage string) string {
 	hasher := sha256.New()
@@ -80,6 +180,28 @@ func GenerateSHA256HashInBase64Form(preimage string) string {
 	return shaHashBase64
 }
 
+// function to generate a hash in base64 format for a given preimage using the hash function of an HTLC lock
+func GenerateHashInBase64Form(hashMechanism common.HashMechanism, preimage string) (string, error) {
+	var hash []byte
+	switch hashMechanism {
+	case common.HashMechanism_SHA256:
+		return GenerateSHA256HashInBase64Form(preimage), nil
+	case common.HashMechanism_SHA512:
+		shaHash := sha512.Sum512([]byte(preimage))
+		hash = shaHash[:]
+	case common.HashMechanism_SHA3_256:
+		shaHash := sha3.Sum256([]byte(preimage))
+		hash = shaHash[:]
+	case common.HashMechanism_KECCAK256:
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write([]byte(preimage))
+		hash = hasher.Sum(nil)
+	default:
+		return "", fmt.Errorf("hash mechanism %d is not supported", hashMechanism)
+	}
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
+
 // function to return the key to fetch an element from the map using contractId
 func generateContractIdMapKey(contractId string) string {
 	return contractIdPrefix + contractId
@@ -288,7 +410,10 @@ func getLockInfoAndExpiryTimeSecs(lockInfoBytesBase64 string) (interface{}, uint
 		}
 		//display the passed hash lock information
 		log.Infof("lockInfoHTLC: %+v", lockInfoHTLC)
-		lockInfoVal = HashLock{HashBase64: string(lockInfoHTLC.HashBase64)}
+		if _, ok := common.HashMechanism_name[int32(lockInfoHTLC.HashMechanism)]; !ok {
+			return lockInfoVal, 0, logThenErrorf("hash mechanism %d is not supported", lockInfoHTLC.HashMechanism)
+		}
+		lockInfoVal = HashLock{HashBase64: string(lockInfoHTLC.HashBase64), HashMechanism: lockInfoHTLC.HashMechanism}
 		// process time lock details here
 		if lockInfoHTLC.TimeSpec != common.AssetLockHTLC_EPOCH {
 			return lockInfoVal, 0, logThenErrorf("only EPOCH time is supported at present")
@@ -361,6 +486,12 @@ func LockAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID, a
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
+
+	err = emitAssetExchangeEvent(ctx, LockAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+		Locker: assetAgreement.Locker, Recipient: assetAgreement.Recipient, HashBase64: getHashFromLockInfo(lockInfo), ExpiryTimeSecs: expiryTimeSecs})
+	if err != nil {
+		return "", err
+	}
 	return contractId, nil
 }
 
@@ -431,6 +562,12 @@ func LockSharedAsset(ctx contractapi.TransactionContextInterface, callerChaincod
         if err != nil {
                 return "", logThenErrorf(err.Error())
         }
+
+        err = emitAssetExchangeEvent(ctx, LockSharedAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+                Lockers: lockers, Recipients: recipients, HashBase64: getHashFromLockInfo(lockInfo), ExpiryTimeSecs: expiryTimeSecs})
+        if err != nil {
+                return "", err
+        }
         return contractId, nil
 }
 
@@ -480,7 +617,10 @@ func UnlockAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
 	if currentTimeSecs < assetLockVal.ExpiryTimeSecs {
 		return "", logThenErrorf("cannot unlock asset of type %s and ID %s as the expiry time is not yet elapsed", assetAgreement.Type, assetAgreement.Id)
 	}
@@ -494,6 +634,12 @@ func UnlockAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 		return "", logThenErrorf("failed to delete the contractId %s as part of asset unlock: %v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, UnlockAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+		Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient})
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -549,7 +695,10 @@ func UnlockSharedAsset(ctx contractapi.TransactionContextInterface, callerChainc
         }
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return "", logThenErrorf(err.Error())
+        }
         if currentTimeSecs < assetLockVal.ExpiryTimeSecs {
                 return "", logThenErrorf("cannot unlock asset of type %s and ID %s as the expiry time is not yet elapsed", assetAgreement.Type, assetAgreement.Id)
         }
@@ -563,6 +712,12 @@ func UnlockSharedAsset(ctx contractapi.TransactionContextInterface, callerChainc
                 return "", logThenErrorf("failed to delete the contractId %s as part of asset unlock: %v", contractId, err)
         }
 
+        err = emitAssetExchangeEvent(ctx, UnlockSharedAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+                Lockers: assetLockVal.Lockers, Recipients: assetLockVal.Recipients})
+        if err != nil {
+                return "", err
+        }
+
         return contractId, nil
 }
 
@@ -604,7 +759,10 @@ func IsAssetLocked(ctx contractapi.TransactionContextInterface, callerChaincodeI
 	log.Infof("assetLockVal: %+v", assetLockVal)
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return false, logThenErrorf("expiry time for asset of type %s and ID %s is already elapsed", assetAgreement.Type, assetAgreement.Id)
 	}
@@ -661,7 +819,10 @@ func IsSharedAssetLocked(ctx contractapi.TransactionContextInterface, callerChai
         log.Infof("assetLockVal: %+v", assetLockVal)
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return false, logThenErrorf(err.Error())
+        }
         if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
                 return false, logThenErrorf("expiry time for asset of type %s and ID %s is already elapsed", assetAgreement.Type, assetAgreement.Id)
         }
@@ -681,17 +842,21 @@ func IsSharedAssetLocked(ctx contractapi.TransactionContextInterface, callerChai
 }
 
 /*
- * Function to check if hashBase64 is the hash for the preimage preimageBase64.
+ * Function to check if hashBase64 is the hash for the preimage preimageBase64,
+ * computed with the hash function given by hashMechanism.
  * Both the preimage and hash are passed in base64 form.
  */
-func checkIfCorrectPreimage(preimageBase64 string, hashBase64 string) (bool, error) {
+func checkIfCorrectPreimage(preimageBase64 string, hashBase64 string, hashMechanism common.HashMechanism) (bool, error) {
 	funName := "checkIfCorrectPreimage"
 	preimage, err := base64.StdEncoding.DecodeString(preimageBase64)
 	if err != nil {
 		return false, logThenErrorf("base64 decode preimage error: %s", err)
 	}
 
-	shaHashBase64 := GenerateSHA256HashInBase64Form(string(preimage))
+	shaHashBase64, err := GenerateHashInBase64Form(hashMechanism, string(preimage))
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if shaHashBase64 == hashBase64 {
 		log.Infof("%s: preimage %s is passed correctly", funName, preimage)
 	} else {
@@ -721,7 +886,7 @@ func validateHashPreimage(claimInfo *common.AssetClaim, lockInfo interface{}) (b
 	log.Infof("HashLock: %+v\n", lockInfoVal)
 
 	// match the hash passed during claim with the hash stored during asset locking
-	return checkIfCorrectPreimage(string(claimInfoHTLC.HashPreimageBase64), lockInfoVal.HashBase64)
+	return checkIfCorrectPreimage(string(claimInfoHTLC.HashPreimageBase64), lockInfoVal.HashBase64, lockInfoVal.HashMechanism)
 }
 
 // fetches common.AssetClaim from the input parameter and checks if the lock mechanism is valid or not
@@ -796,7 +961,10 @@ func ClaimAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return "", logThenErrorf("cannot claim asset of type %s and ID %s as the expiry time is already elapsed", assetAgreement.Type, assetAgreement.Id)
 	}
@@ -835,6 +1003,12 @@ func ClaimAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 		return "", logThenErrorf("failed to delete the contractId %s as part of asset claim: %v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, ClaimAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+		Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient, HashPreimageBase64: getHashPreimageFromClaimInfo(claimInfo)})
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -892,7 +1066,10 @@ func ClaimSharedAsset(ctx contractapi.TransactionContextInterface, callerChainco
         }
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return "", logThenErrorf(err.Error())
+        }
         if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
                 return "", logThenErrorf("cannot claim asset of type %s and ID %s as the expiry time is already elapsed", assetAgreement.Type, assetAgreement.Id)
         }
@@ -931,6 +1108,12 @@ func ClaimSharedAsset(ctx contractapi.TransactionContextInterface, callerChainco
                 return "", logThenErrorf("failed to delete the contractId %s as part of asset claim: %v", contractId, err)
         }
 
+        err = emitAssetExchangeEvent(ctx, ClaimSharedAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, Id: assetAgreement.Id,
+                Lockers: assetLockVal.Lockers, Recipients: assetLockVal.Recipients, HashPreimageBase64: getHashPreimageFromClaimInfo(claimInfo)})
+        if err != nil {
+                return "", err
+        }
+
         return contractId, nil
 }
 
@@ -1023,7 +1206,10 @@ func UnlockAssetUsingContractId(ctx contractapi.TransactionContextInterface, con
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
 	if currentTimeSecs < assetLockVal.ExpiryTimeSecs {
 		return logThenErrorf("cannot unlock asset associated with the contractId %s as the expiry time is not yet elapsed", contractId)
 	}
@@ -1038,6 +1224,11 @@ func UnlockAssetUsingContractId(ctx contractapi.TransactionContextInterface, con
 		return logThenErrorf("failed to delete the contractId %s as part of asset unlock: %v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, UnlockAssetEventName, &AssetExchangeEvent{ContractId: contractId, Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1060,7 +1251,10 @@ func UnlockSharedAssetUsingContractId(ctx contractapi.TransactionContextInterfac
         }
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return logThenErrorf(err.Error())
+        }
         if currentTimeSecs < assetLockVal.ExpiryTimeSecs {
                 return logThenErrorf("cannot unlock asset associated with the contractId %s as the expiry time is not yet elapsed", contractId)
         }
@@ -1075,6 +1269,11 @@ func UnlockSharedAssetUsingContractId(ctx contractapi.TransactionContextInterfac
                 return logThenErrorf("failed to delete the contractId %s as part of asset unlock: %v", contractId, err)
         }
 
+        err = emitAssetExchangeEvent(ctx, UnlockSharedAssetEventName, &AssetExchangeEvent{ContractId: contractId, Lockers: assetLockVal.Lockers, Recipients: assetLockVal.Recipients})
+        if err != nil {
+                return err
+        }
+
         return nil
 }
 
@@ -1101,7 +1300,10 @@ func ClaimAssetUsingContractId(ctx contractapi.TransactionContextInterface, cont
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return logThenErrorf("cannot claim asset associated with contractId %s as the expiry time is already elapsed", contractId)
 	}
@@ -1137,6 +1339,12 @@ func ClaimAssetUsingContractId(ctx contractapi.TransactionContextInterface, cont
 		return logThenErrorf("failed to delete the contractId %s as part of asset claim: %+v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, ClaimAssetEventName, &AssetExchangeEvent{ContractId: contractId, Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient,
+		HashPreimageBase64: getHashPreimageFromClaimInfo(claimInfo)})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1164,7 +1372,10 @@ func ClaimSharedAssetUsingContractId(ctx contractapi.TransactionContextInterface
         }
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return assetLockVal, logThenErrorf(err.Error())
+        }
         if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
                 return assetLockVal, logThenErrorf("cannot claim asset associated with contractId %s as the expiry time is already elapsed", contractId)
         }
@@ -1200,6 +1411,12 @@ func ClaimSharedAssetUsingContractId(ctx contractapi.TransactionContextInterface
                 return assetLockVal, logThenErrorf("failed to delete the contractId %s as part of asset claim: %+v", contractId, err)
         }
 
+        err = emitAssetExchangeEvent(ctx, ClaimSharedAssetEventName, &AssetExchangeEvent{ContractId: contractId, Lockers: assetLockVal.Lockers, Recipients: assetLockVal.Recipients,
+                HashPreimageBase64: getHashPreimageFromClaimInfo(claimInfo)})
+        if err != nil {
+                return assetLockVal, err
+        }
+
         return assetLockVal, nil
 }
 
@@ -1212,7 +1429,10 @@ func IsAssetLockedQueryUsingContractId(ctx contractapi.TransactionContextInterfa
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return false, logThenErrorf("expiry time for asset associated with contractId %s is already elapsed", contractId)
 	}
@@ -1229,7 +1449,10 @@ func IsSharedAssetLockedQueryUsingContractId(ctx contractapi.TransactionContextI
         }
 
         // Check if expiry time is elapsed
-        currentTimeSecs := uint64(time.Now().Unix())
+        currentTimeSecs, err := GetTxTimeSecs(ctx)
+        if err != nil {
+                return false, logThenErrorf(err.Error())
+        }
         if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
                 return false, logThenErrorf("expiry time for asset associated with contractId %s is already elapsed", contractId)
         }
@@ -1289,6 +1512,12 @@ func LockFungibleAsset(ctx contractapi.TransactionContextInterface, callerChainc
 		return "", logThenErrorf("failed to write to the world state: %+v", err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, LockFungibleAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetAgreement.Type, NumUnits: assetAgreement.NumUnits,
+		Locker: assetAgreement.Locker, Recipient: assetAgreement.Recipient, HashBase64: getHashFromLockInfo(lockInfo), ExpiryTimeSecs: expiryTimeSecs})
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -1323,7 +1552,10 @@ func IsFungibleAssetLocked(ctx contractapi.TransactionContextInterface, contract
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return false, logThenErrorf("expiry time for fungible asset associated with contractId %s is already elapsed", contractId)
 	}
@@ -1355,7 +1587,10 @@ func ClaimFungibleAsset(ctx contractapi.TransactionContextInterface, contractId,
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return logThenErrorf("cannot claim fungible asset associated with contractId %s as the expiry time is already elapsed", contractId)
 	}
@@ -1386,6 +1621,12 @@ func ClaimFungibleAsset(ctx contractapi.TransactionContextInterface, contractId,
 		return logThenErrorf("failed to delete the contractId %s as part of fungible asset claim: %+v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, ClaimFungibleAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetLockVal.Type, NumUnits: assetLockVal.NumUnits,
+		Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient, HashPreimageBase64: getHashPreimageFromClaimInfo(claimInfo)})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1408,7 +1649,10 @@ func UnlockFungibleAsset(ctx contractapi.TransactionContextInterface, contractId
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
 	if currentTimeSecs < assetLockVal.ExpiryTimeSecs {
 		return logThenErrorf("cannot unlock fungible asset associated with the contractId %s as the expiry time is not yet elapsed", contractId)
 	}
@@ -1418,6 +1662,12 @@ func UnlockFungibleAsset(ctx contractapi.TransactionContextInterface, contractId
 		return logThenErrorf("failed to delete the contractId %s as part of fungible asset unlock: %v", contractId, err)
 	}
 
+	err = emitAssetExchangeEvent(ctx, UnlockFungibleAssetEventName, &AssetExchangeEvent{ContractId: contractId, Type: assetLockVal.Type, NumUnits: assetLockVal.NumUnits,
+		Locker: assetLockVal.Locker, Recipient: assetLockVal.Recipient})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
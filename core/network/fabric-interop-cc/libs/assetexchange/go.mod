@@ -8,4 +8,5 @@ require (
 	github.com/hyperledger/fabric-contract-api-go v1.1.1
 	github.com/hyperledger/fabric-protos-go v0.0.0-20210720123151-f0dc3e2a0871
 	github.com/sirupsen/logrus v1.8.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
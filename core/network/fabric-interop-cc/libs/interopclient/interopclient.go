@@ -0,0 +1,340 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// interopclient provides typed access to the interop chaincode for application chaincodes, so that they do not
+// need to build the arguments of chaincode-to-chaincode invocations by hand
+package interopclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	wutils "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/utils"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrCallerNotInteropChaincode is returned by CheckCallerIsInteropChaincode when the application chaincode was
+	// not invoked through the interop chaincode
+	ErrCallerNotInteropChaincode = errors.New("Illegal access; function can only be invoked from Interop Chaincode")
+	// ErrIllegalRelayAccess is returned by CheckRelayAccess when a relay invokes the application chaincode directly
+	ErrIllegalRelayAccess = errors.New("Illegal access by relay")
+)
+
+// InteropError is returned when the interop chaincode reports a failure, and carries the function that was
+// invoked along with the status and message of the response
+type InteropError struct {
+	Function string
+	Status   int32
+	Message  string
+}
+
+func (e *InteropError) Error() string {
+	return fmt.Sprintf("Interop chaincode function %s failed with status %d: %s", e.Function, e.Status, e.Message)
+}
+
+// InteropClient invokes the functions of the interop chaincode on behalf of an application chaincode.
+//
+// Views, asset agreements, locks and claims are passed as proto structs and serialized to the base64 encoded
+// arguments the interop chaincode expects.
+type InteropClient struct {
+	interopChaincodeID string
+}
+
+// NewInteropClient creates a client for the interop chaincode with the given ID, deployed on the same channel as
+// the application chaincode
+func NewInteropClient(interopChaincodeID string) *InteropClient {
+	return &InteropClient{interopChaincodeID: interopChaincodeID}
+}
+
+// InteropChaincodeID returns the ID of the interop chaincode invoked by the client
+func (c *InteropClient) InteropChaincodeID() string {
+	return c.interopChaincodeID
+}
+
+// CheckCallerIsInteropChaincode fails with ErrCallerNotInteropChaincode unless the transaction was submitted to the
+// interop chaincode, e.g., by WriteExternalState, which then invoked the application chaincode
+func (c *InteropClient) CheckCallerIsInteropChaincode(stub shim.ChaincodeStubInterface) error {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(stub)
+	if err != nil {
+		return err
+	}
+	if callerChaincodeID != c.interopChaincodeID {
+		return ErrCallerNotInteropChaincode
+	}
+	return nil
+}
+
+// CheckRelayAccess fails with ErrIllegalRelayAccess if the client is a relay that did not go through the interop
+// chaincode, which enforces the access control policies of remote networks. Other clients are always permitted.
+func (c *InteropClient) CheckRelayAccess(stub shim.ChaincodeStubInterface) error {
+	isClientRelay, err := wutils.IsClientRelay(stub)
+	if err != nil {
+		return err
+	}
+	if !isClientRelay {
+		return nil
+	}
+	err = c.CheckCallerIsInteropChaincode(stub)
+	if err == ErrCallerNotInteropChaincode {
+		return ErrIllegalRelayAccess
+	}
+	return err
+}
+
+// VerifyView verifies the proof in a view obtained from a remote network against the address it was requested from
+func (c *InteropClient) VerifyView(stub shim.ChaincodeStubInterface, address string, view *common.View) error {
+	viewBase64, err := marshalBase64(view)
+	if err != nil {
+		return err
+	}
+	_, err = c.invoke(stub, "VerifyView", viewBase64, address)
+	return err
+}
+
+// ParseAndValidateView verifies the proof in a view obtained from a remote network and returns the data in the view
+func (c *InteropClient) ParseAndValidateView(stub shim.ChaincodeStubInterface, address string, view *common.View) ([]byte, error) {
+	viewBase64, err := marshalBase64(view)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := c.invoke(stub, "ParseAndValidateView", address, viewBase64)
+	if err != nil {
+		return nil, err
+	}
+	// Byte arrays returned by the interop chaincode are serialized as base64 encoded JSON strings
+	var viewData []byte
+	err = json.Unmarshal(payload, &viewData)
+	if err != nil {
+		return nil, unexpectedResponse("ParseAndValidateView", payload)
+	}
+	return viewData, nil
+}
+
+// LockAsset locks a non-fungible asset and returns the contract ID of the lock
+func (c *InteropClient) LockAsset(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement, lockInfo *common.AssetLock) (string, error) {
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return "", err
+	}
+	lockInfoBase64, err := marshalBase64(lockInfo)
+	if err != nil {
+		return "", err
+	}
+	contractID, err := c.invoke(stub, "LockAsset", assetAgreementBase64, lockInfoBase64)
+	return string(contractID), err
+}
+
+// LockFungibleAsset locks a number of units of a fungible asset and returns the contract ID of the lock
+func (c *InteropClient) LockFungibleAsset(stub shim.ChaincodeStubInterface, assetAgreement *common.FungibleAssetExchangeAgreement, lockInfo *common.AssetLock) (string, error) {
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return "", err
+	}
+	lockInfoBase64, err := marshalBase64(lockInfo)
+	if err != nil {
+		return "", err
+	}
+	contractID, err := c.invoke(stub, "LockFungibleAsset", assetAgreementBase64, lockInfoBase64)
+	return string(contractID), err
+}
+
+// IsAssetLocked returns whether a non-fungible asset is locked as described by the agreement
+func (c *InteropClient) IsAssetLocked(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement) (bool, error) {
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return false, err
+	}
+	return c.invokeBool(stub, "IsAssetLocked", assetAgreementBase64)
+}
+
+// IsAssetLockedQueryUsingContractId returns whether the non-fungible asset lock with the contract ID is active
+func (c *InteropClient) IsAssetLockedQueryUsingContractId(stub shim.ChaincodeStubInterface, contractID string) (bool, error) {
+	return c.invokeBool(stub, "IsAssetLockedQueryUsingContractId", contractID)
+}
+
+// IsFungibleAssetLocked returns whether the fungible asset lock with the contract ID is active
+func (c *InteropClient) IsFungibleAssetLocked(stub shim.ChaincodeStubInterface, contractID string) (bool, error) {
+	return c.invokeBool(stub, "IsFungibleAssetLocked", contractID)
+}
+
+// ClaimAsset claims a locked non-fungible asset
+func (c *InteropClient) ClaimAsset(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement, claimInfo *common.AssetClaim) error {
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return err
+	}
+	claimInfoBase64, err := marshalBase64(claimInfo)
+	if err != nil {
+		return err
+	}
+	_, err = c.invoke(stub, "ClaimAsset", assetAgreementBase64, claimInfoBase64)
+	return err
+}
+
+// ClaimAssetUsingContractId claims the non-fungible asset locked with the contract ID
+func (c *InteropClient) ClaimAssetUsingContractId(stub shim.ChaincodeStubInterface, contractID string, claimInfo *common.AssetClaim) error {
+	claimInfoBase64, err := marshalBase64(claimInfo)
+	if err != nil {
+		return err
+	}
+	_, err = c.invoke(stub, "ClaimAssetUsingContractId", contractID, claimInfoBase64)
+	return err
+}
+
+// ClaimFungibleAsset claims the fungible asset units locked with the contract ID
+func (c *InteropClient) ClaimFungibleAsset(stub shim.ChaincodeStubInterface, contractID string, claimInfo *common.AssetClaim) error {
+	claimInfoBase64, err := marshalBase64(claimInfo)
+	if err != nil {
+		return err
+	}
+	_, err = c.invoke(stub, "ClaimFungibleAsset", contractID, claimInfoBase64)
+	return err
+}
+
+// UnlockAsset releases a locked non-fungible asset back to the locker after the lock has expired
+func (c *InteropClient) UnlockAsset(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement) error {
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return err
+	}
+	_, err = c.invoke(stub, "UnlockAsset", assetAgreementBase64)
+	return err
+}
+
+// UnlockAssetUsingContractId releases the non-fungible asset locked with the contract ID after the lock has expired
+func (c *InteropClient) UnlockAssetUsingContractId(stub shim.ChaincodeStubInterface, contractID string) error {
+	_, err := c.invoke(stub, "UnlockAssetUsingContractId", contractID)
+	return err
+}
+
+// UnlockFungibleAsset releases the fungible asset units locked with the contract ID after the lock has expired
+func (c *InteropClient) UnlockFungibleAsset(stub shim.ChaincodeStubInterface, contractID string) error {
+	_, err := c.invoke(stub, "UnlockFungibleAsset", contractID)
+	return err
+}
+
+// GetHTLCHash returns the base64 encoded hash of the lock on a non-fungible asset of the application chaincode
+func (c *InteropClient) GetHTLCHash(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement) (string, error) {
+	return c.invokeWithAgreement(stub, "GetHTLCHash", assetAgreement)
+}
+
+// GetHTLCHashByContractId returns the base64 encoded hash of the lock with the contract ID
+func (c *InteropClient) GetHTLCHashByContractId(stub shim.ChaincodeStubInterface, contractID string) (string, error) {
+	hashBase64, err := c.invoke(stub, "GetHTLCHashByContractId", contractID)
+	return string(hashBase64), err
+}
+
+// GetHTLCHashPreImage returns the base64 encoded preimage revealed when a non-fungible asset of the application
+// chaincode was claimed
+func (c *InteropClient) GetHTLCHashPreImage(stub shim.ChaincodeStubInterface, assetAgreement *common.AssetExchangeAgreement) (string, error) {
+	return c.invokeWithAgreement(stub, "GetHTLCHashPreImage", assetAgreement)
+}
+
+// GetHTLCHashPreImageByContractId returns the base64 encoded preimage revealed when the lock with the contract ID
+// was claimed
+func (c *InteropClient) GetHTLCHashPreImageByContractId(stub shim.ChaincodeStubInterface, contractID string) (string, error) {
+	preimageBase64, err := c.invoke(stub, "GetHTLCHashPreImageByContractId", contractID)
+	return string(preimageBase64), err
+}
+
+// PublishEvent publishes an event of the application chaincode to the remote networks subscribed to it. The
+// application chaincode must be registered with the interop chaincode.
+func (c *InteropClient) PublishEvent(stub shim.ChaincodeStubInterface, eventName string, payload []byte) error {
+	_, err := c.invoke(stub, "PublishEvent", eventName, string(payload))
+	return err
+}
+
+// GetPrivateDataPayload returns the response an application chaincode function serving a view of a private data
+// collection should return, i.e., the serialized PrivateDataPayload holding the value of the key in the collection
+// along with the hash of the value recorded on the ledger, so that remote networks can check one against the other
+func GetPrivateDataPayload(stub shim.ChaincodeStubInterface, collection string, key string) ([]byte, error) {
+	value, err := stub.GetPrivateData(collection, key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("Key %s does not exist in collection %s", key, collection)
+	}
+	hash, err := stub.GetPrivateDataHash(collection, key)
+	if err != nil {
+		return nil, err
+	}
+	privateDataPayloadBytes, err := proto.Marshal(&common.PrivateDataPayload{
+		Collection: collection,
+		Key:        key,
+		Value:      value,
+		Hash:       hash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Marshal error: %s", err)
+	}
+	return privateDataPayloadBytes, nil
+}
+
+// invokeWithAgreement invokes a function that identifies a non-fungible asset lock by the ID of the application
+// chaincode that locked it and the asset agreement
+func (c *InteropClient) invokeWithAgreement(stub shim.ChaincodeStubInterface, function string, assetAgreement *common.AssetExchangeAgreement) (string, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(stub)
+	if err != nil {
+		return "", err
+	}
+	assetAgreementBase64, err := marshalBase64(assetAgreement)
+	if err != nil {
+		return "", err
+	}
+	payload, err := c.invoke(stub, function, callerChaincodeID, assetAgreementBase64)
+	return string(payload), err
+}
+
+func (c *InteropClient) invokeBool(stub shim.ChaincodeStubInterface, function string, args ...string) (bool, error) {
+	payload, err := c.invoke(stub, function, args...)
+	if err != nil {
+		return false, err
+	}
+	result, err := strconv.ParseBool(string(payload))
+	if err != nil {
+		return false, unexpectedResponse(function, payload)
+	}
+	return result, nil
+}
+
+// invoke calls a function of the interop chaincode and returns the payload of the response, or an *InteropError
+// if the interop chaincode reports a failure
+func (c *InteropClient) invoke(stub shim.ChaincodeStubInterface, function string, args ...string) ([]byte, error) {
+	if c.interopChaincodeID == "" {
+		return nil, errors.New("Interop chaincode ID not set")
+	}
+	byteArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+	resp := stub.InvokeChaincode(c.interopChaincodeID, byteArgs, "")
+	if resp.GetStatus() != shim.OK {
+		err := &InteropError{Function: function, Status: resp.GetStatus(), Message: resp.GetMessage()}
+		log.Error(err.Error())
+		return nil, err
+	}
+	return resp.GetPayload(), nil
+}
+
+func marshalBase64(message proto.Message) (string, error) {
+	messageBytes, err := proto.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("Marshal error: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(messageBytes), nil
+}
+
+func unexpectedResponse(function string, payload []byte) error {
+	return fmt.Errorf("Unexpected response from interop chaincode function %s: %s", function, string(payload))
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package interopclient_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	ic "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/interopclient"
+	wtest "github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+const interopCCId = "interopcc"
+
+func TestLockAsset(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	client := ic.NewInteropClient(interopCCId)
+	assetAgreement := &common.AssetExchangeAgreement{Type: "bond", Id: "a01", Recipient: "Bob"}
+	lockInfo := &common.AssetLock{LockMechanism: common.LockMechanism_HTLC, LockInfo: []byte("lock")}
+
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: []byte("contract1")})
+	contractID, err := client.LockAsset(chaincodeStub, assetAgreement, lockInfo)
+	require.NoError(t, err)
+	require.Equal(t, "contract1", contractID)
+	chaincodeName, args, channel := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, interopCCId, chaincodeName)
+	require.Equal(t, "", channel)
+	require.Equal(t, "LockAsset", string(args[0]))
+	assetAgreementBytes, err := base64.StdEncoding.DecodeString(string(args[1]))
+	require.NoError(t, err)
+	decodedAgreement := &common.AssetExchangeAgreement{}
+	require.NoError(t, proto.Unmarshal(assetAgreementBytes, decodedAgreement))
+	require.True(t, proto.Equal(assetAgreement, decodedAgreement))
+
+	// Failures of the interop chaincode are returned as an InteropError
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 500, Message: "asset already locked"})
+	_, err = client.LockAsset(chaincodeStub, assetAgreement, lockInfo)
+	var interopErr *ic.InteropError
+	require.True(t, errors.As(err, &interopErr))
+	require.Equal(t, "LockAsset", interopErr.Function)
+	require.Equal(t, int32(500), interopErr.Status)
+	require.Equal(t, "asset already locked", interopErr.Message)
+	require.EqualError(t, err, "Interop chaincode function LockAsset failed with status 500: asset already locked")
+
+	_, err = ic.NewInteropClient("").LockAsset(chaincodeStub, assetAgreement, lockInfo)
+	require.EqualError(t, err, "Interop chaincode ID not set")
+}
+
+func TestIsAssetLockedQueryUsingContractId(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	client := ic.NewInteropClient(interopCCId)
+
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: []byte("true")})
+	isLocked, err := client.IsAssetLockedQueryUsingContractId(chaincodeStub, "contract1")
+	require.NoError(t, err)
+	require.True(t, isLocked)
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, [][]byte{[]byte("IsAssetLockedQueryUsingContractId"), []byte("contract1")}, args)
+
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: []byte("maybe")})
+	_, err = client.IsAssetLockedQueryUsingContractId(chaincodeStub, "contract1")
+	require.EqualError(t, err, "Unexpected response from interop chaincode function IsAssetLockedQueryUsingContractId: maybe")
+}
+
+func TestParseAndValidateView(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	client := ic.NewInteropClient(interopCCId)
+	view := &common.View{Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: "Notarization"}, Data: []byte("data")}
+
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: []byte(`"` + base64.StdEncoding.EncodeToString([]byte("value")) + `"`)})
+	viewData, err := client.ParseAndValidateView(chaincodeStub, "relay/network1/mychannel:simplestate:Read:a", view)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), viewData)
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, "ParseAndValidateView", string(args[0]))
+	require.Equal(t, "relay/network1/mychannel:simplestate:Read:a", string(args[1]))
+}
+
+func TestGetHTLCHash(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	wtest.SetMockStubCCId(chaincodeStub, "simpleasset")
+	client := ic.NewInteropClient(interopCCId)
+
+	// The ID of the application chaincode that locked the asset is passed to the interop chaincode
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: []byte("aGFzaA==")})
+	hashBase64, err := client.GetHTLCHash(chaincodeStub, &common.AssetExchangeAgreement{Type: "bond", Id: "a01"})
+	require.NoError(t, err)
+	require.Equal(t, "aGFzaA==", hashBase64)
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, "GetHTLCHash", string(args[0]))
+	require.Equal(t, "simpleasset", string(args[1]))
+}
+
+func TestCheckCallerIsInteropChaincode(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	client := ic.NewInteropClient(interopCCId)
+
+	wtest.SetMockStubCCId(chaincodeStub, interopCCId)
+	require.NoError(t, client.CheckCallerIsInteropChaincode(chaincodeStub))
+
+	wtest.SetMockStubCCId(chaincodeStub, "simpleasset")
+	require.Equal(t, ic.ErrCallerNotInteropChaincode, client.CheckCallerIsInteropChaincode(chaincodeStub))
+}
+
+func TestPublishEvent(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	client := ic.NewInteropClient(interopCCId)
+
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{Status: 200})
+	require.NoError(t, client.PublishEvent(chaincodeStub, "AssetCreated", []byte("a01")))
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, [][]byte{[]byte("PublishEvent"), []byte("AssetCreated"), []byte("a01")}, args)
+}
+
+func TestGetPrivateDataPayload(t *testing.T) {
+	_, chaincodeStub := wtest.PrepMockStub()
+	chaincodeStub.GetPrivateDataReturns([]byte("42"), nil)
+	chaincodeStub.GetPrivateDataHashReturns([]byte("hash"), nil)
+
+	privateDataPayloadBytes, err := ic.GetPrivateDataPayload(chaincodeStub, "prices", "a01")
+	require.NoError(t, err)
+	privateDataPayload := &common.PrivateDataPayload{}
+	require.NoError(t, proto.Unmarshal(privateDataPayloadBytes, privateDataPayload))
+	require.True(t, proto.Equal(&common.PrivateDataPayload{Collection: "prices", Key: "a01", Value: []byte("42"), Hash: []byte("hash")}, privateDataPayload))
+	collection, key := chaincodeStub.GetPrivateDataHashArgsForCall(0)
+	require.Equal(t, "prices", collection)
+	require.Equal(t, "a01", key)
+
+	chaincodeStub.GetPrivateDataReturns(nil, nil)
+	_, err = ic.GetPrivateDataPayload(chaincodeStub, "prices", "a02")
+	require.EqualError(t, err, "Key a02 does not exist in collection prices")
+}
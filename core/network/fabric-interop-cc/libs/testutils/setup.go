@@ -11,8 +11,11 @@ package testutils
 
 import (
 	"os"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/testutils/mocks"
 	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
@@ -49,10 +52,16 @@ func SetMockStubCCId(chaincodeStub *mocks.ChaincodeStub, ccName string) {
 	chaincodeStub.GetSignedProposalReturns(generateSignedProposal(ccName), nil)
 }
 
+// SetMockStubTxTime sets the timestamp of the transaction seen by the stub, which is the current time by default
+func SetMockStubTxTime(chaincodeStub *mocks.ChaincodeStub, txTime time.Time) {
+	chaincodeStub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: txTime.Unix(), Nanos: int32(txTime.Nanosecond())}, nil)
+}
+
 func prepMocks(orgMSP, clientID string) (*mocks.TransactionContext, *mocks.ChaincodeStub) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
+	chaincodeStub.GetTxTimestampReturns(ptypes.TimestampNow(), nil)
 
 	clientIdentity := &mocks.ClientIdentity{}
 	clientIdentity.GetMSPIDReturns(orgMSP, nil)
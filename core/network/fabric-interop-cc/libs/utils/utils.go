@@ -109,6 +109,43 @@ func CheckAccessIfRelayClient(stub shim.ChaincodeStubInterface) (bool, error) {
 }
 
 
+///////////////////////////////////////////////////////
+//////       TRANSACTION TIME FUNCTIONS        ////////
+///////////////////////////////////////////////////////
+
+// txTimeSkewToleranceSecs is the largest difference allowed between the timestamp of a transaction and the
+// clock of the endorsing peer, or 0 if it is not checked
+var txTimeSkewToleranceSecs uint64
+
+// SetTxTimeSkewTolerance sets the largest difference (in seconds) allowed between the timestamp of a transaction
+// and the clock of the endorsing peer. The timestamp is set by the submitting client, so a tolerance prevents
+// clients from moving it to get around expiry times; peers whose clock differs from the timestamp by more than the
+// tolerance refuse to endorse the transaction. A tolerance of 0 (the default) disables the check.
+func SetTxTimeSkewTolerance(toleranceSecs uint64) {
+	txTimeSkewToleranceSecs = toleranceSecs
+}
+
+// GetTxTimeSecs returns the timestamp of the transaction in seconds since the epoch. Unlike the clock of the
+// endorsing peer it is the same on all peers, so expiry decisions based on it are deterministic.
+func GetTxTimeSecs(ctx contractapi.TransactionContextInterface) (uint64, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get transaction timestamp: %v", err)
+	}
+	if txTimestamp == nil || txTimestamp.GetSeconds() < 0 {
+		return 0, fmt.Errorf("transaction timestamp is not set")
+	}
+	txTimeSecs := uint64(txTimestamp.GetSeconds())
+	if txTimeSkewToleranceSecs > 0 {
+		peerTimeSecs := uint64(time.Now().Unix())
+		if txTimeSecs > peerTimeSecs+txTimeSkewToleranceSecs || peerTimeSecs > txTimeSecs+txTimeSkewToleranceSecs {
+			return 0, fmt.Errorf("transaction timestamp %d differs from the peer time %d by more than %d seconds", txTimeSecs, peerTimeSecs, txTimeSkewToleranceSecs)
+		}
+	}
+	return txTimeSecs, nil
+}
+
+
 ///////////////////////////////////////////////////////
 //////        ASSET TRANSFER FUNCTIONS         ////////
 ///////////////////////////////////////////////////////
@@ -220,7 +257,10 @@ func PledgeAsset(ctx contractapi.TransactionContextInterface, assetJSON []byte,
 	}
 
 	// Make sure the pledge has an expiry time in the future
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return "", err
+	}
 	if currentTimeSecs >= expiryTimeSecs {
 		return "", fmt.Errorf("expiry time cannot be less than current time")
 	}
@@ -260,7 +300,10 @@ func ClaimRemoteAsset(ctx contractapi.TransactionContextInterface, pledgeId, cla
 	}
 
 	// Make sure the pledge has not expired (we assume the expiry timestamp set by the remote network)
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if currentTimeSecs >= pledge.ExpiryTimeSecs {
 		return nil, fmt.Errorf("cannot claim asset with pledgeId %s as the expiry time has elapsed", pledgeId)
 	}
@@ -331,7 +374,10 @@ func ReclaimAsset(ctx contractapi.TransactionContextInterface, pledgeId, recipie
 	if err != nil {
 		return nil, nil, err
 	}
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	if currentTimeSecs < pledge.ExpiryTimeSecs {
 		return nil, nil, fmt.Errorf("cannot reclaim asset with pledgeId %s as the expiry time is not yet elapsed", pledgeId)
 	}
@@ -459,6 +505,10 @@ func GetAssetPledgeDetails(ctx contractapi.TransactionContextInterface, pledgeId
 func GetAssetClaimStatus(ctx contractapi.TransactionContextInterface, pledgeId, recipientCert, pledger, pledgerNetworkId string, pledgeExpiryTimeSecs uint64, blankAssetJSON []byte) ([]byte, string, string, error) {
 	// (Optional) Ensure that this function is being called by the relay via the Fabric Interop CC
 
+	currentTimeSecs, err := GetTxTimeSecs(ctx)
+	if err != nil {
+		return nil, "", "", err
+	}
 	claimStatus := &common.AssetClaimStatus{
 		AssetDetails: blankAssetJSON,
 		LocalNetworkID: "",
@@ -466,7 +516,7 @@ func GetAssetClaimStatus(ctx contractapi.TransactionContextInterface, pledgeId,
 		Recipient: "",
 		ClaimStatus: false,
 		ExpiryTimeSecs: pledgeExpiryTimeSecs,
-		ExpirationStatus: (uint64(time.Now().Unix()) >= pledgeExpiryTimeSecs),
+		ExpirationStatus: (currentTimeSecs >= pledgeExpiryTimeSecs),
 	}
 	claimStatusBytes64, err := marshalAssetClaimStatus(claimStatus)
 	if err != nil {
@@ -26,6 +26,9 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         certificate: "test".to_string(),
         requestor_signature: "test".to_string(),
         nonce: "test".to_string(),
+        timestamp: 0,
+        confidential: false,
+        invoke: false,
     });
     let response = network_client.request_state(request).await?;
     println!("RESPONSE={:?}", response);
@@ -308,6 +308,9 @@ async fn data_transfer_call(
         certificate: network_query.certificate,
         requestor_signature: network_query.requestor_signature,
         nonce: network_query.nonce,
+        timestamp: network_query.timestamp,
+        confidential: network_query.confidential,
+        invoke: network_query.invoke,
         request_id: request_id.to_string(),
     });
     println!("Query: {:?}", query_request);
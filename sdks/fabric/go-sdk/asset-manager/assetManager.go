@@ -8,6 +8,7 @@ package assetmanager
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/sha3"
 
 	"github.com/golang/protobuf/proto"
 )
@@ -64,11 +66,12 @@ func createFungibleAssetExchangeAgreementSerializedBase64(assetType string, numU
 }
 
 // Create an asset lock structure
-func createAssetLockInfoSerializedBase64(hashBase64 string, expiryTimeSecs uint64) (string, error) {
+func createAssetLockInfoSerializedBase64(hashBase64 string, hashMechanism common.HashMechanism, expiryTimeSecs uint64) (string, error) {
 	lockInfoHTLC := &common.AssetLockHTLC{
 		HashBase64:     []byte(hashBase64),
 		ExpiryTimeSecs: expiryTimeSecs,
 		TimeSpec:       common.AssetLockHTLC_EPOCH,
+		HashMechanism:  hashMechanism,
 	}
 	lockInfoHTLCBytes, err := proto.Marshal(lockInfoHTLC)
 	if err != nil {
@@ -119,8 +122,38 @@ func GenerateSHA256HashInBase64Form(hashPreimage string) string {
 	return shaHashBase64
 }
 
+// function to generate a hash in base64 format for a given preimage using the hash function of an HTLC lock
+func GenerateHashInBase64Form(hashMechanism common.HashMechanism, hashPreimage string) (string, error) {
+	var hash []byte
+	switch hashMechanism {
+	case common.HashMechanism_SHA256:
+		return GenerateSHA256HashInBase64Form(hashPreimage), nil
+	case common.HashMechanism_SHA512:
+		shaHash := sha512.Sum512([]byte(hashPreimage))
+		hash = shaHash[:]
+	case common.HashMechanism_SHA3_256:
+		shaHash := sha3.Sum256([]byte(hashPreimage))
+		hash = shaHash[:]
+	case common.HashMechanism_KECCAK256:
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write([]byte(hashPreimage))
+		hash = hasher.Sum(nil)
+	default:
+		return "", logThenErrorf("hash mechanism %d is not supported", hashMechanism)
+	}
+
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
+
 func CreateHTLC(contract GatewayContract, assetType string, assetId string, recipientECertBase64 string,
 	hashBase64 string, expiryTimeSecs uint64) (string, error) {
+	return CreateHTLCWithHashMechanism(contract, assetType, assetId, recipientECertBase64, hashBase64, common.HashMechanism_SHA256, expiryTimeSecs)
+}
+
+// CreateHTLCWithHashMechanism locks an asset with the hash hashBase64 of a preimage, computed with the hash function
+// given by hashMechanism. CreateHTLC is equivalent to using common.HashMechanism_SHA256.
+func CreateHTLCWithHashMechanism(contract GatewayContract, assetType string, assetId string, recipientECertBase64 string,
+	hashBase64 string, hashMechanism common.HashMechanism, expiryTimeSecs uint64) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -136,6 +169,9 @@ func CreateHTLC(contract GatewayContract, assetType string, assetId string, reci
 	if hashBase64 == "" {
 		return "", logThenErrorf("hashBase64 is not supplied")
 	}
+	if _, ok := common.HashMechanism_name[int32(hashMechanism)]; !ok {
+		return "", logThenErrorf("hash mechanism %d is not supported", hashMechanism)
+	}
 	currentTimeSecs := uint64(time.Now().Unix())
 	if expiryTimeSecs <= currentTimeSecs {
 		return "", logThenErrorf("supplied expirty time in the past")
@@ -145,7 +181,7 @@ func CreateHTLC(contract GatewayContract, assetType string, assetId string, reci
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
-	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, expiryTimeSecs)
+	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, hashMechanism, expiryTimeSecs)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -161,6 +197,13 @@ func CreateHTLC(contract GatewayContract, assetType string, assetId string, reci
 
 func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uint64, recipientECertBase64 string,
 	hashBase64 string, expiryTimeSecs uint64) (string, error) {
+	return CreateFungibleHTLCWithHashMechanism(contract, assetType, numUnits, recipientECertBase64, hashBase64, common.HashMechanism_SHA256, expiryTimeSecs)
+}
+
+// CreateFungibleHTLCWithHashMechanism locks units of a fungible asset with the hash hashBase64 of a preimage, computed
+// with the hash function given by hashMechanism. CreateFungibleHTLC is equivalent to using common.HashMechanism_SHA256.
+func CreateFungibleHTLCWithHashMechanism(contract GatewayContract, assetType string, numUnits uint64, recipientECertBase64 string,
+	hashBase64 string, hashMechanism common.HashMechanism, expiryTimeSecs uint64) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -176,6 +219,9 @@ func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uin
 	if hashBase64 == "" {
 		return "", logThenErrorf("hashBase64 is not supplied")
 	}
+	if _, ok := common.HashMechanism_name[int32(hashMechanism)]; !ok {
+		return "", logThenErrorf("hash mechanism %d is not supported", hashMechanism)
+	}
 	currentTimeSecs := uint64(time.Now().Unix())
 	if expiryTimeSecs <= currentTimeSecs {
 		return "", logThenErrorf("supplied expirty time in the past")
@@ -185,7 +231,7 @@ func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uin
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
-	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, expiryTimeSecs)
+	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, hashMechanism, expiryTimeSecs)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
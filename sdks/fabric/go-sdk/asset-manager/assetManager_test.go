@@ -7,10 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package assetmanager
 
 import (
+	"encoding/base64"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,6 +30,28 @@ func (gwMock gatewayContractMock) EvaluateTransaction(ccFunc string, args ...str
 	return evaluateTransactionMock()
 }
 
+// gatewayContractRecorder records the arguments of the last submitted transaction
+type gatewayContractRecorder struct {
+	gatewayContractMock
+	args []string
+}
+
+func (gwRecorder *gatewayContractRecorder) SubmitTransaction(ccFunc string, args ...string) ([]byte, error) {
+	gwRecorder.args = args
+	return submitTransactionMock()
+}
+
+// function to decode the hash lock submitted by an HTLC lock transaction
+func getSubmittedLockInfoHTLC(t *testing.T, lockInfoBase64 string) *common.AssetLockHTLC {
+	lockInfoBytes, err := base64.StdEncoding.DecodeString(lockInfoBase64)
+	require.NoError(t, err)
+	lockInfo := &common.AssetLock{}
+	require.NoError(t, proto.Unmarshal(lockInfoBytes, lockInfo))
+	lockInfoHTLC := &common.AssetLockHTLC{}
+	require.NoError(t, proto.Unmarshal(lockInfo.LockInfo, lockInfoHTLC))
+	return lockInfoHTLC
+}
+
 func TestCreateHTLC(t *testing.T) {
 
 	contract := gatewayContractMock{}
@@ -75,6 +100,13 @@ func TestCreateHTLC(t *testing.T) {
 	}
 	require.EqualError(t, err, expectedError)
 
+	expectedError = "hash mechanism 7 is not supported"
+	_, err = CreateHTLCWithHashMechanism(contract, assetType, assetId, recipientECertBase64, hashBase64, common.HashMechanism(7), expiryTimeSecs)
+	if err == nil {
+		t.Error("expected to fail with error " + expectedError + " but didn't")
+	}
+	require.EqualError(t, err, expectedError)
+
 	expectedError = "supplied expirty time in the past"
 	_, err = CreateHTLC(contract, assetType, assetId, recipientECertBase64, hashBase64, expiryTimeSecs)
 	if err == nil {
@@ -100,6 +132,63 @@ func TestCreateHTLC(t *testing.T) {
 	require.EqualError(t, err, expectedError)
 }
 
+func TestCreateHTLCWithHashMechanism(t *testing.T) {
+
+	contract := &gatewayContractRecorder{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("contract-id"), nil
+	}
+	expiryTimeSecs := uint64(time.Now().Unix()) + 10
+	hashBase64, err := GenerateHashInBase64Form(common.HashMechanism_KECCAK256, "hashPreimage")
+	require.NoError(t, err)
+
+	// Locks created without a hash mechanism use SHA256
+	_, err = CreateHTLC(contract, "asset-type", "asset-id", "recipientECertBase64", GenerateSHA256HashInBase64Form("hashPreimage"), expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, common.HashMechanism_SHA256, getSubmittedLockInfoHTLC(t, contract.args[1]).HashMechanism)
+	_, err = CreateFungibleHTLC(contract, "asset-type", 10, "recipientECertBase64", GenerateSHA256HashInBase64Form("hashPreimage"), expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, common.HashMechanism_SHA256, getSubmittedLockInfoHTLC(t, contract.args[1]).HashMechanism)
+
+	contractId, err := CreateHTLCWithHashMechanism(contract, "asset-type", "asset-id", "recipientECertBase64", hashBase64, common.HashMechanism_KECCAK256, expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", contractId)
+	lockInfoHTLC := getSubmittedLockInfoHTLC(t, contract.args[1])
+	require.Equal(t, common.HashMechanism_KECCAK256, lockInfoHTLC.HashMechanism)
+	require.Equal(t, hashBase64, string(lockInfoHTLC.HashBase64))
+
+	contractId, err = CreateFungibleHTLCWithHashMechanism(contract, "asset-type", 10, "recipientECertBase64", hashBase64, common.HashMechanism_KECCAK256, expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", contractId)
+	require.Equal(t, common.HashMechanism_KECCAK256, getSubmittedLockInfoHTLC(t, contract.args[1]).HashMechanism)
+
+	expectedError := "hash mechanism 7 is not supported"
+	_, err = CreateFungibleHTLCWithHashMechanism(contract, "asset-type", 10, "recipientECertBase64", hashBase64, common.HashMechanism(7), expiryTimeSecs)
+	require.EqualError(t, err, expectedError)
+}
+
+func TestGenerateHashInBase64Form(t *testing.T) {
+	hashBase64, err := GenerateHashInBase64Form(common.HashMechanism_SHA256, "hashPreimage")
+	require.NoError(t, err)
+	require.Equal(t, GenerateSHA256HashInBase64Form("hashPreimage"), hashBase64)
+
+	hashBase64, err = GenerateHashInBase64Form(common.HashMechanism_SHA512, "")
+	require.NoError(t, err)
+	require.Equal(t, "z4PhNX7vuL3xVChQ1m2AB9Yg5AULVxXcg/SpIdNs6c5H0NE8XYXysP+DGNKHfuwvY7kxvUdBeoGlODJ6+SfaPg==", hashBase64)
+
+	hashBase64, err = GenerateHashInBase64Form(common.HashMechanism_SHA3_256, "")
+	require.NoError(t, err)
+	require.Equal(t, "p//G+L8e12ZRwUdWoGHWYvWA/03kO0n6gtgKS4D4Q0o=", hashBase64)
+
+	hashBase64, err = GenerateHashInBase64Form(common.HashMechanism_KECCAK256, "")
+	require.NoError(t, err)
+	require.Equal(t, "xdJGAYb3IzySfn2y3McDwOUAtlPKgic7e/rYBF2FpHA=", hashBase64)
+
+	expectedError := "hash mechanism 7 is not supported"
+	_, err = GenerateHashInBase64Form(common.HashMechanism(7), "hashPreimage")
+	require.EqualError(t, err, expectedError)
+}
+
 func TestCreateFungibleHTLC(t *testing.T) {
 
 	contract := gatewayContractMock{}
@@ -7,8 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package helpers
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -46,3 +54,83 @@ func ParseAddress(address string) (*ParsedAddress, error) {
 
 	return addressParts, nil
 }
+
+const eciesNonceSize = 12
+
+// deriveECIESKey computes the symmetric key from the ECDH shared secret and the ephemeral public key
+func deriveECIESKey(curve elliptic.Curve, sharedX *big.Int, ephemeralPublicKey []byte) []byte {
+	sharedBytes := make([]byte, (curve.Params().BitSize+7)/8)
+	sharedX.FillBytes(sharedBytes)
+	key := sha256.New()
+	key.Write(sharedBytes)
+	key.Write(ephemeralPublicKey)
+	return key.Sum(nil)
+}
+
+/**
+ * Encrypts a message to an ECDSA public key using the same ECIES scheme as the interop chaincode.
+ * The output is the uncompressed ephemeral public key, followed by the AES-GCM nonce and ciphertext.
+ **/
+func EncryptECIES(publicKey *ecdsa.PublicKey, message []byte) ([]byte, error) {
+	ephemeralKey, err := ecdsa.GenerateKey(publicKey.Curve, rand.Reader)
+	if err != nil {
+		return nil, logThenErrorf("failed to generate ephemeral key: %s", err.Error())
+	}
+	ephemeralPublicKey := elliptic.Marshal(publicKey.Curve, ephemeralKey.X, ephemeralKey.Y)
+	sharedX, _ := publicKey.Curve.ScalarMult(publicKey.X, publicKey.Y, ephemeralKey.D.Bytes())
+	block, err := aes.NewCipher(deriveECIESKey(publicKey.Curve, sharedX, ephemeralPublicKey))
+	if err != nil {
+		return nil, logThenErrorf("failed to create cipher: %s", err.Error())
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, logThenErrorf("failed to create cipher: %s", err.Error())
+	}
+	nonce := make([]byte, eciesNonceSize)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, logThenErrorf("failed to generate nonce: %s", err.Error())
+	}
+	output := append(ephemeralPublicKey, nonce...)
+	return append(output, gcm.Seal(nil, nonce, message, nil)...), nil
+}
+
+/**
+ * Decrypts a message encrypted to the public key of an ECDSA private key by EncryptECIES or the interop chaincode.
+ **/
+func DecryptECIES(privateKey *ecdsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	curve := privateKey.Curve
+	publicKeyLength := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(ciphertext) < publicKeyLength+eciesNonceSize {
+		return nil, logThenErrorf("ciphertext too short: %d bytes", len(ciphertext))
+	}
+	ephemeralPublicKey := ciphertext[:publicKeyLength]
+	ephemeralX, ephemeralY := elliptic.Unmarshal(curve, ephemeralPublicKey)
+	if ephemeralX == nil {
+		return nil, logThenErrorf("invalid ephemeral public key in ciphertext")
+	}
+	sharedX, _ := curve.ScalarMult(ephemeralX, ephemeralY, privateKey.D.Bytes())
+	block, err := aes.NewCipher(deriveECIESKey(curve, sharedX, ephemeralPublicKey))
+	if err != nil {
+		return nil, logThenErrorf("failed to create cipher: %s", err.Error())
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, logThenErrorf("failed to create cipher: %s", err.Error())
+	}
+	nonce := ciphertext[publicKeyLength : publicKeyLength+eciesNonceSize]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[publicKeyLength+eciesNonceSize:], nil)
+	if err != nil {
+		return nil, logThenErrorf("failed to decrypt: %s", err.Error())
+	}
+	return plaintext, nil
+}
+
+/**
+ * Computes the HMAC-SHA256 commitment to a confidential payload, keyed by the random value sent along with it.
+ **/
+func ComputeConfidentialPayloadHash(payload []byte, random []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
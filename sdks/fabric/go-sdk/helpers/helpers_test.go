@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package helpers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
 	"testing"
 
@@ -33,3 +36,28 @@ func TestParseAddress(t *testing.T) {
 	require.EqualError(t, err, expectedErr)
 	fmt.Printf("Test failed as expected with error: %s\n", err)
 }
+
+func TestECIES(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// Test success with a message encrypted to the key
+	ciphertext, err := EncryptECIES(&privateKey.PublicKey, []byte("17.12"))
+	require.NoError(t, err)
+	plaintext, err := DecryptECIES(privateKey, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("17.12"), plaintext)
+	fmt.Printf("Test success as the message was decrypted\n")
+
+	// Test failure with a different key
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = DecryptECIES(otherKey, ciphertext)
+	require.EqualError(t, err, "failed to decrypt: cipher: message authentication failed")
+	fmt.Printf("Test failed as expected with error: %s\n", err)
+
+	// Test failure with a truncated ciphertext
+	_, err = DecryptECIES(privateKey, ciphertext[:10])
+	require.EqualError(t, err, "ciphertext too short: 10 bytes")
+	fmt.Printf("Test failed as expected with error: %s\n", err)
+}
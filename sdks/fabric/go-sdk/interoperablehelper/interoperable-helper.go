@@ -7,23 +7,42 @@ SPDX-License-Identifier: Apache-2.0
 package interoperablehelper
 
 import (
+	"crypto/hmac"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/besu"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/corda"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/networks"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/core/network/fabric-interop-cc/libs/addresspattern"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/sdks/fabric/go-sdk/helpers"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/sdks/fabric/go-sdk/relay"
 	"github.com/hyperledger-labs/weaver-dlt-interoperability/sdks/fabric/go-sdk/types"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	log "github.com/sirupsen/logrus"
 	protoV2 "google.golang.org/protobuf/proto"
 )
 
+const (
+	// fabricCommitmentProofType is the proof type of Fabric views carrying a FabricCommitView
+	fabricCommitmentProofType = "Commitment"
+	// invocationSignedMessageSuffix distinguishes the signed message of an invocation from that of a query
+	invocationSignedMessageSuffix = ";invoke"
+)
+
+// fabricViewData is implemented by the FabricView and FabricCommitView protos, which both carry the chaincode response
+type fabricViewData interface {
+	protoV2.Message
+	GetResponse() *peer.Response
+}
+
 type GatewayContract interface {
 	EvaluateTransaction(name string, args ...string) ([]byte, error)
 	SubmitTransaction(name string, args ...string) ([]byte, error)
@@ -33,6 +52,12 @@ type Signer interface {
 	Sign(msg []byte) ([]byte, error)
 }
 
+// Decrypter must be implemented by the signer to request confidential views, which are encrypted
+// to the public key in the requestor's certificate
+type Decrypter interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
 // helper functions to log and return errors
 func logThenErrorf(format string, args ...interface{}) error {
 	errorMsg := fmt.Sprintf(format, args...)
@@ -147,63 +172,18 @@ func getPolicyCriteriaForAddress(contract GatewayContract, address string) ([]st
 		return emptyCriteria, logThenErrorf("failed to unmarshal verification policy with error: %s", err.Error())
 	}
 
-	// Get policy criteria matching the requested information in the address
-	matchingIdentifier := Identifier{
-		Pattern: "",
-		Policy: IdentifierAccessPolicy{
-			Type:     "",
-			Criteria: []string{},
-		},
+	// Get policy criteria of the most specific pattern matching the requested information in the address, as
+	// resolved by the interop chaincode
+	patterns := make([]string, len(verificationPolicy.Identifiers))
+	for i, identifier := range verificationPolicy.Identifiers {
+		patterns[i] = identifier.Pattern
 	}
-	for i := 0; i < len(verificationPolicy.Identifiers); i++ {
-		item := verificationPolicy.Identifiers[i]
-		if item.Pattern == parsedAddress.ViewSegment {
-			matchingIdentifier = item
-			break
-		}
-		if validPatternString(item.Pattern) && isPatternAndAddressMatch(item.Pattern, parsedAddress.ViewSegment) &&
-			(matchingIdentifier.Pattern == "") || (len(item.Pattern) > len(matchingIdentifier.Pattern)) {
-			matchingIdentifier = item
-			break
-		}
-	}
-
-	return matchingIdentifier.Policy.Criteria, nil
-}
-
-func validPatternString(pattern string) bool {
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// check if 0 or 1 stars
-	if numStars <= 1 {
-		// if 0 stars, return true, if 1 star, make sure its at the end
-		return (numStars == 0) || (strings.Index(pattern, "*")+1 == len(pattern))
+	bestMatch := addresspattern.MostSpecific(patterns, parsedAddress.ViewSegment)
+	if bestMatch == -1 {
+		return emptyCriteria, nil
 	}
 
-	return false
-}
-
-func isPatternAndAddressMatch(pattern string, address string) bool {
-	// make sure the pattern is valid
-	if !validPatternString(pattern) {
-		return false
-	}
-
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// if 0 stars, and exact match, return true
-	if numStars == 0 && pattern == address {
-		return true
-	}
-
-	// if 1 star and pattern is a substring of address, return true
-	if numStars == 1 && strings.Contains(address, pattern) {
-		return true
-	}
-
-	return false
+	return verificationPolicy.Identifiers[bestMatch].Policy.Criteria, nil
 }
 
 /**
@@ -213,12 +193,11 @@ func isPatternAndAddressMatch(pattern string, address string) bool {
 func GetResponseDataFromView(view *common.View) ([]byte, error) {
 	var interopPayload common.InteropPayload
 	if view.Meta.Protocol == common.Meta_FABRIC {
-		var fabricViewData fabric.FabricView
-		err := protoV2.Unmarshal(view.Data, &fabricViewData)
+		fabricViewData, err := unmarshalFabricView(view)
 		if err != nil {
 			return nil, logThenErrorf("fabricView unmarshal error: %s", err.Error())
 		}
-		err = protoV2.Unmarshal(fabricViewData.Response.Payload, &interopPayload)
+		err = protoV2.Unmarshal(fabricViewData.GetResponse().GetPayload(), &interopPayload)
 		if err != nil {
 			return nil, logThenErrorf("unable to unmarshal interopPayload: %s", err.Error())
 		}
@@ -232,9 +211,36 @@ func GetResponseDataFromView(view *common.View) ([]byte, error) {
 		if err != nil {
 			return nil, fmt.Errorf("unable to unmarshal interopPayload: %s", err.Error())
 		}
+	} else if view.Meta.Protocol == common.Meta_BESU || view.Meta.Protocol == common.Meta_ETHEREUM {
+		var besuViewData besu.ViewData
+		err := protoV2.Unmarshal(view.Data, &besuViewData)
+		if err != nil {
+			return nil, logThenErrorf("besuView unmarshal error: %s", err.Error())
+		}
+		err = protoV2.Unmarshal(besuViewData.Payload, &interopPayload)
+		if err != nil {
+			return nil, logThenErrorf("unable to unmarshal interopPayload: %s", err.Error())
+		}
 	} else {
 		return nil, logThenErrorf("cannot extract data from view; unsupported DLT type: %+v", view.Meta.Protocol)
 	}
+	if interopPayload.Confidential {
+		// the view must have been decrypted by decryptRemoteView
+		var confidentialPayload common.ConfidentialPayload
+		err := protoV2.Unmarshal(interopPayload.Payload, &confidentialPayload)
+		if err != nil {
+			return nil, logThenErrorf("unable to unmarshal confidential payload: %s", err.Error())
+		}
+		if len(confidentialPayload.DecryptedPayload) == 0 {
+			return nil, logThenErrorf("confidential payload in view has not been decrypted")
+		}
+		var contents common.ConfidentialPayloadContents
+		err = protoV2.Unmarshal(confidentialPayload.DecryptedPayload, &contents)
+		if err != nil {
+			return nil, logThenErrorf("unable to unmarshal decrypted payload: %s", err.Error())
+		}
+		return contents.Payload, nil
+	}
 	return interopPayload.Payload, nil
 }
 
@@ -301,8 +307,18 @@ func createFlowAddress(flow types.Flow, networkId string, remoteURL string) stri
 	return addressString
 }
 
-func signMessage(computedAddress string, uuidStr string, signer Signer) (string, error) {
+/**
+ * Signs the address and nonce of a query, followed by the timestamp if it is set (non-zero), and for
+ * invocations a suffix that distinguishes them from queries.
+ **/
+func signMessage(computedAddress string, uuidStr string, timestamp uint64, invoke bool, signer Signer) (string, error) {
 	message := computedAddress + uuidStr
+	if timestamp != 0 {
+		message += strconv.FormatUint(timestamp, 10)
+		if invoke {
+			message += invocationSignedMessageSuffix
+		}
+	}
 	signature, err := signer.Sign([]byte(message))
 	if err != nil {
 		return "", fmt.Errorf("signing failed: %s", err)
@@ -342,8 +358,16 @@ func getRemoteView(interopContract GatewayContract, networkId, org, localRelayEn
 	}
 
 	//relay = new Relay(localRelayEndpoint);
-	uuidValue := uuid.New()
-	uuidStr := base64.StdEncoding.EncodeToString([]byte(uuidValue.String()))
+	uuidStr := interopJSON.Nonce
+	if uuidStr == "" {
+		uuidValue := uuid.New()
+		uuidStr = base64.StdEncoding.EncodeToString([]byte(uuidValue.String()))
+	}
+	// The timestamp is signed unless the remote network is known not to check it, and invocations always require it
+	timestamp := uint64(0)
+	if !interopJSON.OmitTimestamp || interopJSON.Invoke {
+		timestamp = uint64(time.Now().Unix())
+	}
 
 	// Step 3
 	// TODO fix types here so can return proper view
@@ -351,21 +375,50 @@ func getRemoteView(interopContract GatewayContract, networkId, org, localRelayEn
 	log.Infof("localRelayEndPoint: %s, computedAddress: %s, policyCriteria: %s, networkId: %s, certUser: %s, uuidStr: %s, org: %s",
 		localRelayEndPoint, computedAddress, policyCriteria, networkId, certUser, uuidStr, org)
 
-	signatureBase64, err := signMessage(computedAddress, uuidStr, signer)
+	signatureBase64, err := signMessage(computedAddress, uuidStr, timestamp, interopJSON.Invoke, signer)
 	if err != nil {
 		return nil, "", logThenErrorf("failed signMessage with error: %s", err.Error())
 	}
 
+	var decrypter Decrypter
+	if interopJSON.Confidential {
+		var ok bool
+		decrypter, ok = signer.(Decrypter)
+		if !ok {
+			return nil, "", logThenErrorf("signer must implement Decrypter to request a confidential view")
+		}
+	}
+
 	relayObj := relay.NewRelay(localRelayEndPoint, 600)
-	relayResponse, err := relayObj.ProcessRequest(computedAddress, policyCriteria, networkId, certUser, signatureBase64, uuidStr, org)
+	networkQuery := &networks.NetworkQuery{
+		Policy:             policyCriteria,
+		Address:            computedAddress,
+		RequestingRelay:    "",
+		RequestingNetwork:  networkId,
+		Certificate:        certUser,
+		RequestorSignature: signatureBase64,
+		Nonce:              uuidStr,
+		Timestamp:          timestamp,
+		RequestingOrg:      org,
+		Confidential:       interopJSON.Confidential,
+		Invoke:             interopJSON.Invoke,
+	}
+	relayResponse, err := relayObj.ProcessNetworkQuery(networkQuery)
 	if err != nil {
 		return nil, "", logThenErrorf("InteropFlow relay response error: %s", err.Error())
 	}
+	view := relayResponse.GetView()
+	if interopJSON.Confidential {
+		view, err = decryptRemoteView(view, decrypter)
+		if err != nil {
+			return nil, "", logThenErrorf("failed to decrypt view with error: %s", err.Error())
+		}
+	}
 
 	// Step 4
 	// Verify view to ensure it is valid before starting expensive WriteExternalState flow.
 
-	viewBytes, err := protoV2.Marshal(relayResponse.GetView())
+	viewBytes, err := protoV2.Marshal(view)
 	if err != nil {
 		return nil, "", logThenErrorf("failed to marshal view with error: %s", err.Error())
 	}
@@ -373,5 +426,79 @@ func getRemoteView(interopContract GatewayContract, networkId, org, localRelayEn
 	if err != nil {
 		return nil, "", logThenErrorf("view verification failed with error: %s", err.Error())
 	}
-	return relayResponse.GetView(), computedAddress, nil
+	return view, computedAddress, nil
+}
+
+// unmarshalFabricView decodes the data of a Fabric view according to its proof type
+func unmarshalFabricView(view *common.View) (fabricViewData, error) {
+	var fabricView fabricViewData = &fabric.FabricView{}
+	if view.GetMeta().GetProofType() == fabricCommitmentProofType {
+		fabricView = &fabric.FabricCommitView{}
+	}
+	err := protoV2.Unmarshal(view.Data, fabricView)
+	if err != nil {
+		return nil, err
+	}
+	if fabricView.GetResponse() == nil {
+		return nil, fmt.Errorf("fabric view has no response")
+	}
+	return fabricView, nil
+}
+
+/**
+ * Decrypts the confidential payload in a Fabric view, with either a Notarization or a Commitment proof, checks it
+ * against the commitment in the view, and adds the decrypted contents to the view so that the interop chaincode can
+ * verify and extract them. The encrypted payload and commitment, which are covered by the proof, are left unchanged.
+ **/
+func decryptRemoteView(view *common.View, decrypter Decrypter) (*common.View, error) {
+	if view.GetMeta().GetProtocol() != common.Meta_FABRIC {
+		return nil, fmt.Errorf("confidential views are not supported for protocol %s", view.GetMeta().GetProtocol())
+	}
+	fabricView, err := unmarshalFabricView(view)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fabric view: %s", err.Error())
+	}
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal(fabricView.GetResponse().GetPayload(), &interopPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal interop payload: %s", err.Error())
+	}
+	if !interopPayload.Confidential {
+		return nil, fmt.Errorf("view payload is not confidential")
+	}
+	var confidentialPayload common.ConfidentialPayload
+	err = protoV2.Unmarshal(interopPayload.Payload, &confidentialPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal confidential payload: %s", err.Error())
+	}
+	decryptedPayload, err := decrypter.Decrypt(confidentialPayload.EncryptedPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %s", err.Error())
+	}
+	var contents common.ConfidentialPayloadContents
+	err = protoV2.Unmarshal(decryptedPayload, &contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decrypted payload: %s", err.Error())
+	}
+	if confidentialPayload.HashType != common.ConfidentialPayload_HMAC_SHA256 {
+		return nil, fmt.Errorf("unsupported confidential payload hash type %s", confidentialPayload.HashType)
+	}
+	if !hmac.Equal(helpers.ComputeConfidentialPayloadHash(contents.Payload, contents.Random), confidentialPayload.Hash) {
+		return nil, fmt.Errorf("decrypted payload does not match the hash in the view")
+	}
+
+	confidentialPayload.DecryptedPayload = decryptedPayload
+	interopPayload.Payload, err = protoV2.Marshal(&confidentialPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal confidential payload: %s", err.Error())
+	}
+	fabricView.GetResponse().Payload, err = protoV2.Marshal(&interopPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal interop payload: %s", err.Error())
+	}
+	viewData, err := protoV2.Marshal(fabricView)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fabric view: %s", err.Error())
+	}
+	return &common.View{Meta: view.Meta, Data: viewData}, nil
 }
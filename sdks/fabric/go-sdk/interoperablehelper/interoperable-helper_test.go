@@ -7,35 +7,166 @@ SPDX-License-Identifier: Apache-2.0
 package interoperablehelper
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/common"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/common/protos-go/fabric"
+	"github.com/hyperledger-labs/weaver-dlt-interoperability/sdks/fabric/go-sdk/helpers"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
 )
 
-func TestValidPatternString(t *testing.T) {
-
-	// Test success with the pattern passed in the correct format with just one "*" at the end
-	pattern := "abcd*"
-	retValue := validPatternString(pattern)
-	require.Equal(t, retValue, true)
-	fmt.Printf("Test success as the pattern passed correctly with just one star at the end\n")
-
-	// Test success with the pattern passed in the correct format with no starts
-	pattern = "abcd"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, true)
-	fmt.Printf("Test success as with the pattern passed in the correct format with no stars\n")
-
-	// Test failure with the pattern containing more then one "*" character
-	pattern = "ab*cd*"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, false)
-	fmt.Printf("Test failed as expected with pattern containing more than one star\n")
-
-	// Test failure with the pattern containing one "*" character NOT at the end
-	pattern = "ab*cd"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, false)
-	fmt.Printf("Test failed as expected with pattern containing one star but NOT at the end\n")
+type testPolicyContract struct {
+	verificationPolicy []byte
+}
+
+func (c *testPolicyContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return c.verificationPolicy, nil
+}
+
+func (c *testPolicyContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func TestGetPolicyCriteriaForAddress(t *testing.T) {
+	contract := &testPolicyContract{verificationPolicy: []byte(`{
+		"securityDomain": "network1",
+		"identifiers": [
+			{"pattern": "mychannel:*", "policy": {"type": "Signature", "criteria": ["Org1MSP"]}},
+			{"pattern": "mychannel:simplestate:Read:a", "policy": {"type": "Signature", "criteria": ["Org3MSP"]}},
+			{"pattern": "mychannel:simplestate:Read:*", "policy": {"type": "Signature", "criteria": ["Org2MSP"]}}
+		]
+	}`)}
+
+	// Test success with the most specific pattern picked whatever the order of the identifiers
+	criteria, err := getPolicyCriteriaForAddress(contract, "localhost:9080/network1/mychannel:simplestate:Read:a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org3MSP"}, criteria)
+	criteria, err = getPolicyCriteriaForAddress(contract, "localhost:9080/network1/mychannel:simplestate:Read:b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org2MSP"}, criteria)
+	criteria, err = getPolicyCriteriaForAddress(contract, "localhost:9080/network1/mychannel:otherstate:Read:b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org1MSP"}, criteria)
+	fmt.Printf("Test success as the most specific pattern was picked\n")
+
+	// Test no criteria when no pattern matches the address
+	criteria, err = getPolicyCriteriaForAddress(contract, "localhost:9080/network1/otherchannel:simplestate:Read:a")
+	require.NoError(t, err)
+	require.Empty(t, criteria)
+	fmt.Printf("Test success as no criteria were found for an unmatched address\n")
+}
+
+type testDecrypter struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+func (d *testDecrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	return helpers.DecryptECIES(d.privateKey, ciphertext)
+}
+
+// createConfidentialView builds a Fabric view with a payload encrypted the same way as the interop chaincode
+func createConfidentialView(t *testing.T, publicKey *ecdsa.PublicKey, payload []byte, hash []byte) *common.View {
+	contents := &common.ConfidentialPayloadContents{Payload: payload, Random: []byte("random")}
+	contentsBytes, err := protoV2.Marshal(contents)
+	require.NoError(t, err)
+	encryptedPayload, err := helpers.EncryptECIES(publicKey, contentsBytes)
+	require.NoError(t, err)
+	if hash == nil {
+		hash = helpers.ComputeConfidentialPayloadHash(contents.Payload, contents.Random)
+	}
+	confidentialPayloadBytes, err := protoV2.Marshal(&common.ConfidentialPayload{
+		EncryptedPayload: encryptedPayload,
+		HashType:         common.ConfidentialPayload_HMAC_SHA256,
+		Hash:             hash,
+	})
+	require.NoError(t, err)
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{
+		Payload:      confidentialPayloadBytes,
+		Address:      "localhost:9080/network1/mychannel:simplestate:Read:Arcturus",
+		Confidential: true,
+	})
+	require.NoError(t, err)
+	fabricViewBytes, err := protoV2.Marshal(&fabric.FabricView{Response: &peer.Response{Payload: interopPayloadBytes}})
+	require.NoError(t, err)
+	return &common.View{
+		Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: "Notarization"},
+		Data: fabricViewBytes,
+	}
+}
+
+// testSigner returns the message as its signature
+type testSigner struct{}
+
+func (signer testSigner) Sign(msg []byte) ([]byte, error) {
+	return msg, nil
+}
+
+func TestSignMessage(t *testing.T) {
+	address := "localhost:9080/network1/mychannel:simplestate:Read:a"
+
+	// Test that queries without a timestamp are signed over the address and nonce, as checked by all interop modules
+	signatureBase64, err := signMessage(address, "nonce", 0, false, testSigner{})
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(address+"nonce")), signatureBase64)
+
+	// Test that the timestamp is appended to the signed message when set
+	signatureBase64, err = signMessage(address, "nonce", 1600000000, false, testSigner{})
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(address+"nonce1600000000")), signatureBase64)
+
+	// Test that invocations are marked in the signed message
+	signatureBase64, err = signMessage(address, "nonce", 1600000000, true, testSigner{})
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(address+"nonce1600000000;invoke")), signatureBase64)
+}
+
+func TestDecryptRemoteView(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	decrypter := &testDecrypter{privateKey: privateKey}
+
+	// Test success with a view encrypted to the requestor's key
+	view := createConfidentialView(t, &privateKey.PublicKey, []byte("17.12"), nil)
+	_, err = GetResponseDataFromView(view)
+	require.EqualError(t, err, "confidential payload in view has not been decrypted")
+	decryptedView, err := decryptRemoteView(view, decrypter)
+	require.NoError(t, err)
+	data, err := GetResponseDataFromView(decryptedView)
+	require.NoError(t, err)
+	require.Equal(t, []byte("17.12"), data)
+	fmt.Printf("Test success as the view was decrypted\n")
+
+	// Test success with a view carrying a commitment proof
+	var fabricView fabric.FabricView
+	require.NoError(t, protoV2.Unmarshal(view.Data, &fabricView))
+	commitViewBytes, err := protoV2.Marshal(&fabric.FabricCommitView{Response: fabricView.Response})
+	require.NoError(t, err)
+	commitView := &common.View{Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: fabricCommitmentProofType}, Data: commitViewBytes}
+	decryptedView, err = decryptRemoteView(commitView, decrypter)
+	require.NoError(t, err)
+	data, err = GetResponseDataFromView(decryptedView)
+	require.NoError(t, err)
+	require.Equal(t, []byte("17.12"), data)
+	fmt.Printf("Test success as the commitment view was decrypted\n")
+
+	// Test failure with a hash that does not match the payload
+	view = createConfidentialView(t, &privateKey.PublicKey, []byte("17.12"), []byte("hash"))
+	_, err = decryptRemoteView(view, decrypter)
+	require.EqualError(t, err, "decrypted payload does not match the hash in the view")
+	fmt.Printf("Test failed as expected with error: %s\n", err)
+
+	// Test failure with a view encrypted to a different key
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	view = createConfidentialView(t, &otherKey.PublicKey, []byte("17.12"), nil)
+	_, err = decryptRemoteView(view, decrypter)
+	require.EqualError(t, err, "failed to decrypt payload: failed to decrypt: cipher: message authentication failed")
+	fmt.Printf("Test failed as expected with error: %s\n", err)
 }
@@ -42,8 +42,7 @@ func NewRelay(localEndPoint string, timeout uint64) *Relay {
  * sendRequest to send a request to a remote network using gRPC and the relay.
  * @returns {string} The ID of the request
  */
-func (r *Relay) sendRequest(address string, policy []string, requestingNetwork string, certificate string, signature string,
-	nonce string, org string) (string, error) {
+func (r *Relay) sendRequest(networkQuery *networks.NetworkQuery) (string, error) {
 
 	// set up a connection to the server
 	conn, err := grpc.Dial(r.endPoint, grpc.WithInsecure())
@@ -56,16 +55,6 @@ func (r *Relay) sendRequest(address string, policy []string, requestingNetwork s
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	networkQuery := &networks.NetworkQuery{
-		Policy:             policy,
-		Address:            address,
-		RequestingRelay:    "",
-		RequestingNetwork:  requestingNetwork,
-		Certificate:        certificate,
-		RequestorSignature: signature,
-		Nonce:              nonce,
-		RequestingOrg:      org,
-	}
 	resp, err := networkClient.RequestState(ctx, networkQuery)
 	if err != nil {
 		return "", logThenErrorf("error in grpc RequestState(): %v", err)
@@ -81,8 +70,27 @@ func (r *Relay) sendRequest(address string, policy []string, requestingNetwork s
  */
 func (r *Relay) ProcessRequest(address string, policy []string, requestingNetwork string, certificate string, signature string,
 	nonce string, org string) (*common.RequestState, error) {
+	networkQuery := &networks.NetworkQuery{
+		Policy:             policy,
+		Address:            address,
+		RequestingRelay:    "",
+		RequestingNetwork:  requestingNetwork,
+		Certificate:        certificate,
+		RequestorSignature: signature,
+		Nonce:              nonce,
+		RequestingOrg:      org,
+	}
+	return r.ProcessNetworkQuery(networkQuery)
+}
+
+/**
+ * ProcessNetworkQuery is the same as ProcessRequest, but takes a complete query, which allows setting the signed
+ * timestamp (the signature must then be over address + nonce + timestamp) and requesting a confidential response.
+ * @returns {string} The state returned by the remote request
+ */
+func (r *Relay) ProcessNetworkQuery(networkQuery *networks.NetworkQuery) (*common.RequestState, error) {
 
-	requestId, err := r.sendRequest(address, policy, requestingNetwork, certificate, signature, nonce, org)
+	requestId, err := r.sendRequest(networkQuery)
 	if err != nil {
 		return nil, logThenErrorf("sendRequest() error: %s", err.Error())
 	}
@@ -20,6 +20,14 @@ type Flow struct {
 	CordappId      string   `json:"cordappId"`
 }
 
+// OmitTimestamp leaves the signed timestamp out of the query, which is otherwise added so that the remote network
+// can reject replayed queries. It must be set for remote networks whose interop module does not check signed
+// timestamps (e.g. Corda or Besu), as these verify the signature over the address and nonce alone.
+//
+// Invoke requests that the remote network invoke the address as a transaction that may change its ledger state,
+// instead of querying it. Invocations are always timestamped, whatever the value of OmitTimestamp. The receipt of the invocation can later be queried from the
+// interop chaincode of the remote network with the hex encoded SHA-256 hash of the nonce of the request, which is
+// generated at random unless Nonce is set.
 type InteropJSON struct {
 	Address        string   `json:"address"`
 	ChaincodeFunc  string   `json:"chaincodeFunc"`
@@ -29,6 +37,10 @@ type InteropJSON struct {
 	NetworkId      string   `json:"networkId"`
 	Sign           bool     `json:"sign"`
 	CcArgs         []string `json:"ccArgs"`
+	Confidential   bool     `json:"confidential"`
+	OmitTimestamp  bool     `json:"omitTimestamp"`
+	Invoke         bool     `json:"invoke"`
+	Nonce          string   `json:"nonce"`
 }
 
 type RemoteJSON struct {
@@ -81,6 +81,12 @@ else
 	exit 1
 fi
 
+# The interop chaincode keeps the secret used for confidential responses in a private data collection
+CC_COLL_CONFIG=""
+if [ "$CC_CHAIN_CODE" = "interop" ] && [ -f "$CC_SRC_PATH/collections_config.json" ]; then
+	CC_COLL_CONFIG="--collections-config $CC_SRC_PATH/collections_config.json"
+fi
+
 # import utils
 . scripts/envVar.sh $NW_PATH $AA $NW_NAME
 
@@ -149,7 +155,7 @@ approveForMyOrg() {
 	#echo "ORDERER_PORT = $ORDERER_PORT"
   setGlobals $ORG $P_ADDR $NW_NAME
   set -x
-  peer lifecycle chaincode approveformyorg -o localhost:${ORD_P} --ordererTLSHostnameOverride orderer.$NW_NAME.com --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE --version ${VERSION} --init-required --package-id ${PACKAGE_ID} --sequence ${VERSION} >&log.txt
+  peer lifecycle chaincode approveformyorg -o localhost:${ORD_P} --ordererTLSHostnameOverride orderer.$NW_NAME.com --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE --version ${VERSION} --init-required --package-id ${PACKAGE_ID} --sequence ${VERSION} $CC_COLL_CONFIG >&log.txt
   set +x
   cat log.txt
   verifyResult $res "Chaincode definition approved on peer0.org${ORG} on channel '$CHANNEL_NAME' failed"
@@ -180,7 +186,7 @@ checkCommitReadiness() {
     sleep $DELAY
     echo "Attempting to check the commit readiness of the chaincode definition on peer0.org${ORG} secs"
     set -x
-    peer lifecycle chaincode checkcommitreadiness --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE --version ${VERSION} --sequence ${VERSION} --output json --init-required >&log.txt
+    peer lifecycle chaincode checkcommitreadiness --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE --version ${VERSION} --sequence ${VERSION} --output json --init-required $CC_COLL_CONFIG >&log.txt
 		#peer lifecycle chaincode checkcommitreadiness --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE --version ${VERSION} --sequence ${VERSION} --output json --init-required >&log.txt
     res=$?
     set +x
@@ -219,7 +225,7 @@ commitChaincodeDefinition() {
 	#echo "Peer pram: "$PEER_CONN_PARMS
 	#setGlobals $ORG $P_ADDR
   set -x
-  peer lifecycle chaincode commit -o localhost:${ORD_P} --ordererTLSHostnameOverride orderer.$NW_NAME.com --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE $PEER_CONN_PARMS --version ${VERSION} --sequence ${VERSION} --init-required >&log.txt
+  peer lifecycle chaincode commit -o localhost:${ORD_P} --ordererTLSHostnameOverride orderer.$NW_NAME.com --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA --channelID $CHANNEL_NAME --name $CC_CHAIN_CODE $PEER_CONN_PARMS --version ${VERSION} --sequence ${VERSION} --init-required $CC_COLL_CONFIG >&log.txt
   res=$?
   set +x
   cat log.txt